@@ -0,0 +1,262 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package ansi provides the ANSI T1.114 variant of TCAP, used mainly in North America.
+
+It shares IE and Tag with package tcap, but the messages are structured differently
+from ITU-T Q.773: the Package Types (Query, Response, Conversation, ...) with a single
+Transaction ID field, the Dialogue Portion and Component Portion with private-class
+tags, and the Invoke and ReturnResult that can be either Last or Not-Last.
+*/
+package ansi
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// TCAP represents a General Structure of ANSI TCAP Information Elements.
+type TCAP struct {
+	Transaction *Transaction
+	Dialogue    *Dialogue
+	Components  *Components
+}
+
+// NewUnidirectional creates a new TCAP of Package Type=Unidirectional.
+func NewUnidirectional(comps ...*Component) *TCAP {
+	return &TCAP{
+		Transaction: NewTransaction(Unidirectional, 0, 0),
+		Components:  NewComponents(comps...),
+	}
+}
+
+// NewQuery creates a new TCAP of Package Type=QueryWithPermission or QueryWithoutPermission.
+func NewQuery(withPermission bool, otid uint32, comps ...*Component) *TCAP {
+	ptype := QueryWithoutPermission
+	if withPermission {
+		ptype = QueryWithPermission
+	}
+	return &TCAP{
+		Transaction: NewTransaction(ptype, otid, 0),
+		Components:  NewComponents(comps...),
+	}
+}
+
+// NewConversation creates a new TCAP of Package Type=ConversationWithPermission or ConversationWithoutPermission.
+func NewConversation(withPermission bool, otid, rtid uint32, comps ...*Component) *TCAP {
+	ptype := ConversationWithoutPermission
+	if withPermission {
+		ptype = ConversationWithPermission
+	}
+	return &TCAP{
+		Transaction: NewTransaction(ptype, otid, rtid),
+		Components:  NewComponents(comps...),
+	}
+}
+
+// NewResponse creates a new TCAP of Package Type=Response.
+func NewResponse(rtid uint32, comps ...*Component) *TCAP {
+	return &TCAP{
+		Transaction: NewTransaction(Response, 0, rtid),
+		Components:  NewComponents(comps...),
+	}
+}
+
+// NewAbort creates a new TCAP of Package Type=Abort with P-Abort Cause.
+func NewAbort(rtid uint32, cause uint8) *TCAP {
+	t := &TCAP{
+		Transaction: NewTransaction(Abort, 0, rtid),
+	}
+	t.Transaction.PAbortCause = tcap.NewIE(pAbortCauseTag, []byte{cause})
+	return t
+}
+
+// NewUserAbort creates a new TCAP of Package Type=Abort with User Abort Information.
+//
+// info is put as the contents of the User Abort Information.
+func NewUserAbort(rtid uint32, info []byte) *TCAP {
+	t := &TCAP{
+		Transaction: NewTransaction(Abort, 0, rtid),
+	}
+	t.Transaction.UserAbortInformation = tcap.NewIE(userAbortInformationTag, info)
+	return t
+}
+
+// MarshalBinary returns the byte sequence generated from a TCAP instance.
+func (t *TCAP) MarshalBinary() ([]byte, error) {
+	return t.AppendBinary(make([]byte, 0, t.MarshalLen()))
+}
+
+// AppendBinary appends the byte sequence generated from a TCAP instance to b.
+func (t *TCAP) AppendBinary(b []byte) ([]byte, error) {
+	tx := t.Transaction
+	if tx == nil {
+		return nil, &tcap.InvalidCodeError{Code: 0}
+	}
+
+	b, start := ber.BeginTLV(b, uint8(tx.Type))
+	b, err := tx.appendFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if portion := t.Dialogue; portion != nil {
+		if b, err = portion.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	if portion := t.Components; portion != nil {
+		if b, err = portion.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	if b, err = tx.appendCause(b); err != nil {
+		return nil, err
+	}
+
+	return ber.EndTLV(b, start), nil
+}
+
+// Parse parses given byte sequence as an ANSI TCAP.
+func Parse(b []byte) (*TCAP, error) {
+	t := &TCAP{}
+	if err := t.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a TCAP.
+func (t *TCAP) UnmarshalBinary(b []byte) error {
+	tag, body, _, err := ber.ReadTLV(b)
+	if err != nil {
+		return err
+	}
+	t.Transaction = &Transaction{Type: tcap.Tag(tag)}
+
+	for len(body) != 0 {
+		tag, value, n, err := ber.ReadTLV(body)
+		if err != nil {
+			return err
+		}
+
+		switch tcap.Tag(tag) {
+		case transactionIDTag:
+			t.Transaction.TransactionID = parseIE(tag, value)
+		case pAbortCauseTag:
+			t.Transaction.PAbortCause = parseIE(tag, value)
+		case userAbortInformationTag:
+			t.Transaction.UserAbortInformation = parseIE(tag, value)
+		case dialoguePortionTag:
+			if t.Dialogue, err = ParseDialogue(body[:n]); err != nil {
+				return err
+			}
+		case componentSequenceTag:
+			if t.Components, err = ParseComponents(body[:n]); err != nil {
+				return err
+			}
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of TCAP.
+func (t *TCAP) MarshalLen() int {
+	l := 0
+	if tx := t.Transaction; tx != nil {
+		for _, field := range []*tcap.IE{tx.TransactionID, tx.PAbortCause, tx.UserAbortInformation} {
+			if field != nil {
+				l += ieLen(field)
+			}
+		}
+	}
+	if portion := t.Dialogue; portion != nil {
+		l += portion.MarshalLen()
+	}
+	if portion := t.Components; portion != nil {
+		l += portion.MarshalLen()
+	}
+	return 1 + ber.LengthLen(l) + l
+}
+
+// PackageType returns the Package Type, e.g. QueryWithPermission.
+func (t *TCAP) PackageType() int {
+	if tx := t.Transaction; tx != nil {
+		return tx.Type.Code()
+	}
+	return 0
+}
+
+// OTID returns the Originating Transaction ID, or 0 if the message does not have it.
+func (t *TCAP) OTID() uint32 {
+	if tx := t.Transaction; tx != nil {
+		return tx.OTID()
+	}
+	return 0
+}
+
+// RTID returns the Responding Transaction ID, or 0 if the message does not have it.
+func (t *TCAP) RTID() uint32 {
+	if tx := t.Transaction; tx != nil {
+		return tx.RTID()
+	}
+	return 0
+}
+
+// ComponentType returns the ComponentType in Component Portion in the list of string.
+func (t *TCAP) ComponentType() []string {
+	if c := t.Components; c != nil {
+		var types []string
+		for _, cm := range c.Component {
+			types = append(types, cm.ComponentTypeString())
+		}
+		return types
+	}
+	return nil
+}
+
+// OpCode returns the OpCode in Component Portion in the list of uint16.
+//
+// It has 0 for the Components that do not have Operation Code.
+func (t *TCAP) OpCode() []uint16 {
+	if c := t.Components; c != nil {
+		var ops []uint16
+		for _, cm := range c.Component {
+			ops = append(ops, cm.OpCode())
+		}
+		return ops
+	}
+	return nil
+}
+
+// LayerPayload returns the contents of Parameter in each Component.
+//
+// It has nil for the Components that do not have Parameter.
+func (t *TCAP) LayerPayload() [][]byte {
+	if c := t.Components; c != nil {
+		var ret [][]byte
+		for _, cm := range c.Component {
+			if cm.Parameter == nil {
+				ret = append(ret, nil)
+				continue
+			}
+			ret = append(ret, cm.Parameter.Value)
+		}
+		return ret
+	}
+	return nil
+}
+
+// String returns TCAP in human readable string.
+func (t *TCAP) String() string {
+	return fmt.Sprintf("{Transaction: %v, Dialogue: %v, Components: %v}",
+		t.Transaction,
+		t.Dialogue,
+		t.Components,
+	)
+}
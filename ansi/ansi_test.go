@@ -0,0 +1,202 @@
+package ansi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestANSI(t *testing.T) {
+	queryWithDialogue := NewQuery(false, 0xaabbccdd,
+		NewInvoke(false, 1, 0, 0x0901, true, nil),
+	)
+	queryWithDialogue.Dialogue = NewDialogue(T1114Version1996, NewIntegerApplicationContext(5), nil)
+
+	tests := []struct {
+		name          string
+		tcap          *TCAP
+		hex           string
+		packageType   int
+		otid, rtid    uint32
+		componentType []string
+		opCode        []uint16
+		payload       [][]byte
+	}{
+		{
+			name: "QueryWithPermission/InvokeLast",
+			tcap: NewQuery(true, 0x01020304,
+				NewInvoke(true, 1, -1, ReplyRequired|0x0901, false, []byte{0x80, 0x01, 0x05}),
+			),
+			hex:           "e216c70401020304e80ee90ccf0101d1028901f203800105",
+			packageType:   QueryWithPermission,
+			otid:          0x01020304,
+			componentType: []string{"invokeLast"},
+			opCode:        []uint16{0x0901},
+			payload:       [][]byte{{0x80, 0x01, 0x05}},
+		}, {
+			name:          "QueryWithoutPermission/Dialogue/InvokeNotLast",
+			tcap:          queryWithDialogue,
+			hex:           "e31ac704aabbccddf906da0101db0105e80aed08cf020100d0020901",
+			packageType:   QueryWithoutPermission,
+			otid:          0xaabbccdd,
+			componentType: []string{"invokeNotLast"},
+			opCode:        []uint16{0x0901},
+			payload:       [][]byte{nil},
+		}, {
+			name: "Response/ReturnResultLast",
+			tcap: NewResponse(0x01020304,
+				NewReturnResult(true, 1, []byte{0x80, 0x01, 0x07}),
+			),
+			hex:           "e412c70401020304e80aea08cf0101f203800107",
+			packageType:   Response,
+			rtid:          0x01020304,
+			componentType: []string{"returnResultLast"},
+			opCode:        []uint16{0},
+			payload:       [][]byte{{0x80, 0x01, 0x07}},
+		}, {
+			name: "ConversationWithoutPermission/ReturnError",
+			tcap: NewConversation(false, 1, 2,
+				NewReturnError(1, 5, true, nil),
+			),
+			hex:           "e614c7080000000100000002e808eb06cf0101d30105",
+			packageType:   ConversationWithoutPermission,
+			otid:          1,
+			rtid:          2,
+			componentType: []string{"returnError"},
+			opCode:        []uint16{0},
+			payload:       [][]byte{nil},
+		}, {
+			name: "Unidirectional/Reject",
+			tcap: NewUnidirectional(
+				NewReject(-1, GeneralProblem, IncorrectComponentPortion),
+			),
+			hex:           "e10ec700e80aec08cf00d5020102f200",
+			packageType:   Unidirectional,
+			componentType: []string{"reject"},
+			opCode:        []uint16{0},
+			payload:       [][]byte{{}},
+		}, {
+			name:        "Abort/P-Abort Cause",
+			tcap:        NewAbort(2, ResourceUnavailable),
+			hex:         "f609c70400000002d70106",
+			packageType: Abort,
+			rtid:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mustDecodeHex(t, tt.hex)
+
+			b, err := tt.tcap.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("MarshalBinary() = %x, want %x", b, want)
+			}
+			if l := tt.tcap.MarshalLen(); l != len(want) {
+				t.Errorf("MarshalLen() = %d, want %d", l, len(want))
+			}
+
+			got, err := Parse(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PackageType() != tt.packageType {
+				t.Errorf("PackageType() = %d, want %d", got.PackageType(), tt.packageType)
+			}
+			if got.OTID() != tt.otid || got.RTID() != tt.rtid {
+				t.Errorf("OTID(), RTID() = %#x, %#x, want %#x, %#x", got.OTID(), got.RTID(), tt.otid, tt.rtid)
+			}
+			if !reflect.DeepEqual(got.ComponentType(), tt.componentType) {
+				t.Errorf("ComponentType() = %v, want %v", got.ComponentType(), tt.componentType)
+			}
+			if !reflect.DeepEqual(got.OpCode(), tt.opCode) {
+				t.Errorf("OpCode() = %v, want %v", got.OpCode(), tt.opCode)
+			}
+			if !reflect.DeepEqual(got.LayerPayload(), tt.payload) {
+				t.Errorf("LayerPayload() = %x, want %x", got.LayerPayload(), tt.payload)
+			}
+
+			b, err = got.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("re-encoded = %x, want %x", b, want)
+			}
+		})
+	}
+}
+
+func TestComponentIDs(t *testing.T) {
+	inv := NewInvoke(true, 3, 7, ReplyRequired|0x0102, false, nil)
+	if inv.InvokeID() != 3 || inv.CorrelationID() != 7 {
+		t.Errorf("InvokeID(), CorrelationID() = %d, %d, want 3, 7", inv.InvokeID(), inv.CorrelationID())
+	}
+	if !inv.IsReplyRequired() || !inv.IsLast() {
+		t.Errorf("IsReplyRequired(), IsLast() = %v, %v, want true, true", inv.IsReplyRequired(), inv.IsLast())
+	}
+
+	rr := NewReturnResult(false, 3, nil)
+	if rr.InvokeID() != -1 || rr.CorrelationID() != 3 || rr.IsLast() {
+		t.Errorf("got InvokeID %d, CorrelationID %d, IsLast %v", rr.InvokeID(), rr.CorrelationID(), rr.IsLast())
+	}
+
+	rej := NewReject(-1, InvokeProblem, InvokeProblemUnrecognizedOperationCode)
+	if rej.CorrelationID() != -1 {
+		t.Errorf("CorrelationID() = %d, want -1", rej.CorrelationID())
+	}
+	if pt, pc := rej.Problem(); pt != InvokeProblem || pc != InvokeProblemUnrecognizedOperationCode {
+		t.Errorf("Problem() = %d, %d", pt, pc)
+	}
+}
+
+func TestLongLength(t *testing.T) {
+	param := bytes.Repeat([]byte{0x80, 0x01, 0xff}, 100)
+	msg := NewConversation(true, 0x11223344, 0x55667788,
+		NewInvoke(true, 1, 0, 0x0901, false, param),
+	)
+	msg.Dialogue = NewDialogue(T1114Version2000, NewObjectApplicationContext([]byte{0x2a, 0x03}), []byte{0x28, 0x00})
+
+	b, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := msg.MarshalLen(); l != len(b) {
+		t.Errorf("MarshalLen() = %d, want %d", l, len(b))
+	}
+
+	got, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.LayerPayload()[0], param) {
+		t.Errorf("LayerPayload() = %x, want %x", got.LayerPayload()[0], param)
+	}
+	if !got.Dialogue.IsObjectApplicationContext() || got.Dialogue.UserInformation == nil {
+		t.Errorf("unexpected Dialogue: %v", got.Dialogue)
+	}
+	if got.OTID() != 0x11223344 || got.RTID() != 0x55667788 {
+		t.Errorf("OTID(), RTID() = %#x, %#x", got.OTID(), got.RTID())
+	}
+
+	re, err := got.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(re, b) {
+		t.Errorf("re-encoded = %x, want %x", re, b)
+	}
+}
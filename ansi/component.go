@@ -0,0 +1,462 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Component Type definitions.
+const (
+	InvokeLast int = iota + 9
+	ReturnResultLast
+	ReturnError
+	Reject
+	InvokeNotLast
+	ReturnResultNotLast
+)
+
+// Problem Type definitions.
+const (
+	GeneralProblem uint8 = iota + 1
+	InvokeProblem
+	ReturnResultProblem
+	ReturnErrorProblem
+	TransactionPortionProblem
+)
+
+// General Problem Code definitions.
+const (
+	UnrecognizedComponentType uint8 = iota + 1
+	IncorrectComponentPortion
+	BadlyStructuredComponentPortion
+	IncorrectComponentCoding
+)
+
+// Invoke Problem Code definitions.
+const (
+	InvokeProblemDuplicateInvocation uint8 = iota + 1
+	InvokeProblemUnrecognizedOperationCode
+	InvokeProblemIncorrectParameter
+	InvokeProblemUnrecognizedCorrelationID
+)
+
+// ReturnResult Problem Code definitions.
+const (
+	ResultProblemUnrecognizedCorrelationID uint8 = iota + 1
+	ResultProblemUnexpectedReturnResult
+	ResultProblemIncorrectParameter
+)
+
+// ReturnError Problem Code definitions.
+const (
+	ErrorProblemUnrecognizedCorrelationID uint8 = iota + 1
+	ErrorProblemUnexpectedReturnError
+	ErrorProblemUnrecognizedError
+	ErrorProblemUnexpectedError
+	ErrorProblemIncorrectParameter
+)
+
+// Tags of the elements in Component Portion.
+var (
+	componentSequenceTag = tcap.NewPrivateConstructorTag(8)
+	componentIDsTag      = tcap.NewPrivatePrimitiveTag(15)
+	nationalOpCodeTag    = tcap.NewPrivatePrimitiveTag(16)
+	privateOpCodeTag     = tcap.NewPrivatePrimitiveTag(17)
+	parameterSetTag      = tcap.NewPrivateConstructorTag(18)
+	parameterSequenceTag = tcap.NewUniversalConstructorTag(16)
+	nationalErrorCodeTag = tcap.NewPrivatePrimitiveTag(19)
+	privateErrorCodeTag  = tcap.NewPrivatePrimitiveTag(20)
+	problemCodeTag       = tcap.NewPrivatePrimitiveTag(21)
+)
+
+// ReplyRequired is the bit in the Operation Family that indicates the reply is required.
+const ReplyRequired uint16 = 0x8000
+
+// Components represents a Component Portion of ANSI TCAP.
+type Components struct {
+	Tag       tcap.Tag
+	Component []*Component
+}
+
+// Component represents a Component of ANSI TCAP.
+type Component struct {
+	Type tcap.Tag
+
+	// ComponentIDs has the Invoke ID and/or Correlation ID. Invoke has the Invoke ID
+	// and optionally the Correlation ID, and the others have the Correlation ID.
+	ComponentIDs  *tcap.IE
+	OperationCode *tcap.IE
+	ErrorCode     *tcap.IE
+	ProblemCode   *tcap.IE
+
+	// Parameter is either Parameter Set (PRIVATE 18) or Parameter Sequence.
+	Parameter *tcap.IE
+}
+
+// NewComponents creates a new Components.
+func NewComponents(comps ...*Component) *Components {
+	return &Components{
+		Tag:       componentSequenceTag,
+		Component: comps,
+	}
+}
+
+// NewInvoke returns a new Invoke Component.
+//
+// corrID is omitted if negative. opCode is the Operation Family (with the reply
+// required bit at the top) and the Operation Specifier in this order.
+// param is put as the contents of the Parameter Set if not nil.
+func NewInvoke(isLast bool, invID, corrID int, opCode uint16, isNational bool, param []byte) *Component {
+	ctype := InvokeNotLast
+	if isLast {
+		ctype = InvokeLast
+	}
+
+	ids := []byte{uint8(invID)}
+	if corrID >= 0 {
+		ids = append(ids, uint8(corrID))
+	}
+
+	tag := privateOpCodeTag
+	if isNational {
+		tag = nationalOpCodeTag
+	}
+	op := make([]byte, 2)
+	binary.BigEndian.PutUint16(op, opCode)
+
+	c := &Component{
+		Type:          tcap.NewPrivateConstructorTag(ctype),
+		ComponentIDs:  tcap.NewIE(componentIDsTag, ids),
+		OperationCode: tcap.NewIE(tag, op),
+	}
+	if param != nil {
+		c.Parameter = tcap.NewIE(parameterSetTag, param)
+	}
+	return c
+}
+
+// NewReturnResult returns a new ReturnResult(Not)Last Component.
+//
+// param is put as the contents of the Parameter Set if not nil.
+func NewReturnResult(isLast bool, corrID int, param []byte) *Component {
+	ctype := ReturnResultNotLast
+	if isLast {
+		ctype = ReturnResultLast
+	}
+
+	c := &Component{
+		Type:         tcap.NewPrivateConstructorTag(ctype),
+		ComponentIDs: tcap.NewIE(componentIDsTag, []byte{uint8(corrID)}),
+	}
+	if param != nil {
+		c.Parameter = tcap.NewIE(parameterSetTag, param)
+	}
+	return c
+}
+
+// NewReturnError returns a new ReturnError Component.
+//
+// param is put as the contents of the Parameter Set if not nil.
+func NewReturnError(corrID int, errCode uint8, isNational bool, param []byte) *Component {
+	tag := privateErrorCodeTag
+	if isNational {
+		tag = nationalErrorCodeTag
+	}
+
+	c := &Component{
+		Type:         tcap.NewPrivateConstructorTag(ReturnError),
+		ComponentIDs: tcap.NewIE(componentIDsTag, []byte{uint8(corrID)}),
+		ErrorCode:    tcap.NewIE(tag, []byte{errCode}),
+	}
+	if param != nil {
+		c.Parameter = tcap.NewIE(parameterSetTag, param)
+	}
+	return c
+}
+
+// NewReject returns a new Reject Component.
+//
+// The Component ID is empty if corrID is negative. The empty Parameter Set is
+// always put, as it is mandatory in Reject.
+func NewReject(corrID int, problemType, problemCode uint8) *Component {
+	ids := []byte{}
+	if corrID >= 0 {
+		ids = []byte{uint8(corrID)}
+	}
+
+	return &Component{
+		Type:         tcap.NewPrivateConstructorTag(Reject),
+		ComponentIDs: tcap.NewIE(componentIDsTag, ids),
+		ProblemCode:  tcap.NewIE(problemCodeTag, []byte{problemType, problemCode}),
+		Parameter:    tcap.NewIE(parameterSetTag, []byte{}),
+	}
+}
+
+// MarshalBinary returns the byte sequence generated from a Components instance.
+func (c *Components) MarshalBinary() ([]byte, error) {
+	return c.AppendBinary(nil)
+}
+
+// AppendBinary appends the byte sequence generated from a Components instance to b.
+func (c *Components) AppendBinary(b []byte) ([]byte, error) {
+	b, start := ber.BeginTLV(b, uint8(c.Tag))
+
+	var err error
+	for _, comp := range c.Component {
+		if b, err = comp.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	return ber.EndTLV(b, start), nil
+}
+
+// MarshalBinary returns the byte sequence generated from a Component instance.
+func (c *Component) MarshalBinary() ([]byte, error) {
+	return c.AppendBinary(nil)
+}
+
+// AppendBinary appends the byte sequence generated from a Component instance to b.
+func (c *Component) AppendBinary(b []byte) ([]byte, error) {
+	b, start := ber.BeginTLV(b, uint8(c.Type))
+	for _, field := range c.fields() {
+		var err error
+		if b, err = field.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	return ber.EndTLV(b, start), nil
+}
+
+// fields returns the fields that the component type can have, in order.
+func (c *Component) fields() []*tcap.IE {
+	var candidates []*tcap.IE
+	switch c.Type.Code() {
+	case InvokeLast, InvokeNotLast:
+		candidates = []*tcap.IE{c.ComponentIDs, c.OperationCode, c.Parameter}
+	case ReturnResultLast, ReturnResultNotLast:
+		candidates = []*tcap.IE{c.ComponentIDs, c.Parameter}
+	case ReturnError:
+		candidates = []*tcap.IE{c.ComponentIDs, c.ErrorCode, c.Parameter}
+	case Reject:
+		candidates = []*tcap.IE{c.ComponentIDs, c.ProblemCode, c.Parameter}
+	}
+
+	var fields []*tcap.IE
+	for _, field := range candidates {
+		if field != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ParseComponents parses given byte sequence as a Components.
+func ParseComponents(b []byte) (*Components, error) {
+	c := &Components{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a Components.
+func (c *Components) UnmarshalBinary(b []byte) error {
+	tag, body, _, err := ber.ReadTLV(b)
+	if err != nil {
+		return err
+	}
+	c.Tag = tcap.Tag(tag)
+
+	for len(body) != 0 {
+		_, _, n, err := ber.ReadTLV(body)
+		if err != nil {
+			return err
+		}
+
+		comp, err := ParseComponent(body[:n])
+		if err != nil {
+			return err
+		}
+		c.Component = append(c.Component, comp)
+		body = body[n:]
+	}
+	return nil
+}
+
+// ParseComponent parses given byte sequence as a Component.
+func ParseComponent(b []byte) (*Component, error) {
+	c := &Component{}
+	if err := c.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a Component.
+func (c *Component) UnmarshalBinary(b []byte) error {
+	tag, body, _, err := ber.ReadTLV(b)
+	if err != nil {
+		return err
+	}
+	c.Type = tcap.Tag(tag)
+
+	for len(body) != 0 {
+		tag, value, n, err := ber.ReadTLV(body)
+		if err != nil {
+			return err
+		}
+
+		ie := parseIE(tag, value)
+		switch tcap.Tag(tag) {
+		case componentIDsTag:
+			c.ComponentIDs = ie
+		case nationalOpCodeTag, privateOpCodeTag:
+			c.OperationCode = ie
+		case nationalErrorCodeTag, privateErrorCodeTag:
+			c.ErrorCode = ie
+		case problemCodeTag:
+			c.ProblemCode = ie
+		case parameterSetTag, parameterSequenceTag:
+			c.Parameter = ie
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Components.
+func (c *Components) MarshalLen() int {
+	l := 0
+	for _, comp := range c.Component {
+		l += comp.MarshalLen()
+	}
+	return 1 + ber.LengthLen(l) + l
+}
+
+// MarshalLen returns the serial length of Component.
+func (c *Component) MarshalLen() int {
+	l := 0
+	for _, field := range c.fields() {
+		l += ieLen(field)
+	}
+	return 1 + ber.LengthLen(l) + l
+}
+
+// ComponentTypeString returns the Component Type in string.
+func (c *Component) ComponentTypeString() string {
+	switch c.Type.Code() {
+	case InvokeLast:
+		return "invokeLast"
+	case ReturnResultLast:
+		return "returnResultLast"
+	case ReturnError:
+		return "returnError"
+	case Reject:
+		return "reject"
+	case InvokeNotLast:
+		return "invokeNotLast"
+	case ReturnResultNotLast:
+		return "returnResultNotLast"
+	}
+	return ""
+}
+
+// IsLast reports whether the Component is the last one of the operation, i.e.
+// neither InvokeNotLast nor ReturnResultNotLast.
+func (c *Component) IsLast() bool {
+	switch c.Type.Code() {
+	case InvokeLast, ReturnResultLast, ReturnError, Reject:
+		return true
+	}
+	return false
+}
+
+// InvokeID returns the Invoke ID, or -1 if the Component does not have it.
+func (c *Component) InvokeID() int {
+	switch c.Type.Code() {
+	case InvokeLast, InvokeNotLast:
+		if ids := c.ComponentIDs; ids != nil && len(ids.Value) > 0 {
+			return int(ids.Value[0])
+		}
+	}
+	return -1
+}
+
+// CorrelationID returns the Correlation ID, or -1 if the Component does not have it.
+func (c *Component) CorrelationID() int {
+	ids := c.ComponentIDs
+	if ids == nil {
+		return -1
+	}
+
+	switch c.Type.Code() {
+	case InvokeLast, InvokeNotLast:
+		if len(ids.Value) > 1 {
+			return int(ids.Value[1])
+		}
+	default:
+		if len(ids.Value) > 0 {
+			return int(ids.Value[0])
+		}
+	}
+	return -1
+}
+
+// OpCode returns the Operation Code (Operation Family and Operation Specifier)
+// without the reply required bit, or 0 if the Component does not have it.
+func (c *Component) OpCode() uint16 {
+	op := c.OperationCode
+	if op == nil || len(op.Value) < 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(op.Value) &^ ReplyRequired
+}
+
+// IsReplyRequired reports whether the reply required bit in the Operation Family is set.
+func (c *Component) IsReplyRequired() bool {
+	op := c.OperationCode
+	if op == nil || len(op.Value) < 2 {
+		return false
+	}
+	return binary.BigEndian.Uint16(op.Value)&ReplyRequired != 0
+}
+
+// ErrCode returns the Error Code, or 0 if the Component does not have it.
+func (c *Component) ErrCode() uint8 {
+	if e := c.ErrorCode; e != nil && len(e.Value) > 0 {
+		return e.Value[0]
+	}
+	return 0
+}
+
+// Problem returns the Problem Type and Problem Specifier, or zeros if the Component does not have it.
+func (c *Component) Problem() (uint8, uint8) {
+	if p := c.ProblemCode; p != nil && len(p.Value) >= 2 {
+		return p.Value[0], p.Value[1]
+	}
+	return 0, 0
+}
+
+// String returns Components in human readable string.
+func (c *Components) String() string {
+	return fmt.Sprintf("{Tag: %#x, Component: %v}", c.Tag, c.Component)
+}
+
+// String returns Component in human readable string.
+func (c *Component) String() string {
+	return fmt.Sprintf("{Type: %#x, ComponentIDs: %v, OperationCode: %v, ErrorCode: %v, ProblemCode: %v, Parameter: %v}",
+		c.Type,
+		c.ComponentIDs,
+		c.OperationCode,
+		c.ErrorCode,
+		c.ProblemCode,
+		c.Parameter,
+	)
+}
@@ -0,0 +1,188 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Protocol Version definitions.
+const (
+	T1114Version1996 uint8 = 1 << iota
+	T1114Version2000
+)
+
+// Tags of the elements in Dialogue Portion.
+var (
+	dialoguePortionTag      = tcap.NewPrivateConstructorTag(25)
+	protocolVersionTag      = tcap.NewPrivatePrimitiveTag(26)
+	integerApplicationIDTag = tcap.NewPrivatePrimitiveTag(27)
+	objectApplicationIDTag  = tcap.NewPrivatePrimitiveTag(28)
+	userInformationTag      = tcap.NewPrivateConstructorTag(29)
+	integerSecurityIDTag    = tcap.NewContextSpecificPrimitiveTag(0)
+	objectSecurityIDTag     = tcap.NewContextSpecificPrimitiveTag(1)
+	confidentialityTag      = tcap.NewContextSpecificConstructorTag(2)
+)
+
+// Dialogue represents a Dialogue Portion of ANSI TCAP.
+type Dialogue struct {
+	Tag             tcap.Tag
+	ProtocolVersion *tcap.IE
+
+	// ApplicationContext is either integer (PRIVATE 27) or object identifier (PRIVATE 28).
+	ApplicationContext *tcap.IE
+	UserInformation    *tcap.IE
+
+	// SecurityContext is either integer ([0]) or object identifier ([1]).
+	SecurityContext *tcap.IE
+	Confidentiality *tcap.IE
+}
+
+// NewDialogue creates a new Dialogue.
+//
+// appCtx can be created with NewIntegerApplicationContext or NewObjectApplicationContext.
+// userInfo is put as the contents of the User Information if not nil.
+func NewDialogue(ver uint8, appCtx *tcap.IE, userInfo []byte) *Dialogue {
+	d := &Dialogue{
+		Tag:                dialoguePortionTag,
+		ProtocolVersion:    tcap.NewIE(protocolVersionTag, []byte{ver}),
+		ApplicationContext: appCtx,
+	}
+	if userInfo != nil {
+		d.UserInformation = tcap.NewIE(userInformationTag, userInfo)
+	}
+
+	return d
+}
+
+// NewIntegerApplicationContext creates a new Application Context of integer form.
+func NewIntegerApplicationContext(id int) *tcap.IE {
+	return tcap.NewIE(integerApplicationIDTag, ber.EncodeInteger(id))
+}
+
+// NewObjectApplicationContext creates a new Application Context of object identifier
+// form. oid should be the contents octets of the OBJECT IDENTIFIER.
+func NewObjectApplicationContext(oid []byte) *tcap.IE {
+	return tcap.NewIE(objectApplicationIDTag, oid)
+}
+
+// NewIntegerSecurityContext creates a new Security Context of integer form.
+func NewIntegerSecurityContext(id int) *tcap.IE {
+	return tcap.NewIE(integerSecurityIDTag, ber.EncodeInteger(id))
+}
+
+// NewObjectSecurityContext creates a new Security Context of object identifier form.
+func NewObjectSecurityContext(oid []byte) *tcap.IE {
+	return tcap.NewIE(objectSecurityIDTag, oid)
+}
+
+// MarshalBinary returns the byte sequence generated from a Dialogue instance.
+func (d *Dialogue) MarshalBinary() ([]byte, error) {
+	return d.AppendBinary(nil)
+}
+
+// AppendBinary appends the byte sequence generated from a Dialogue instance to b.
+func (d *Dialogue) AppendBinary(b []byte) ([]byte, error) {
+	b, start := ber.BeginTLV(b, uint8(d.Tag))
+	for _, field := range d.fields() {
+		var err error
+		if b, err = field.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	return ber.EndTLV(b, start), nil
+}
+
+func (d *Dialogue) fields() []*tcap.IE {
+	var fields []*tcap.IE
+	for _, field := range []*tcap.IE{
+		d.ProtocolVersion, d.ApplicationContext, d.UserInformation, d.SecurityContext, d.Confidentiality,
+	} {
+		if field != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ParseDialogue parses given byte sequence as a Dialogue.
+func ParseDialogue(b []byte) (*Dialogue, error) {
+	d := &Dialogue{}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a Dialogue.
+func (d *Dialogue) UnmarshalBinary(b []byte) error {
+	tag, body, _, err := ber.ReadTLV(b)
+	if err != nil {
+		return err
+	}
+	d.Tag = tcap.Tag(tag)
+
+	for len(body) != 0 {
+		tag, value, n, err := ber.ReadTLV(body)
+		if err != nil {
+			return err
+		}
+
+		ie := parseIE(tag, value)
+		switch tcap.Tag(tag) {
+		case protocolVersionTag:
+			d.ProtocolVersion = ie
+		case integerApplicationIDTag, objectApplicationIDTag:
+			d.ApplicationContext = ie
+		case userInformationTag:
+			d.UserInformation = ie
+		case integerSecurityIDTag, objectSecurityIDTag:
+			d.SecurityContext = ie
+		case confidentialityTag:
+			d.Confidentiality = ie
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Dialogue.
+func (d *Dialogue) MarshalLen() int {
+	l := 0
+	for _, field := range d.fields() {
+		l += ieLen(field)
+	}
+	return 1 + ber.LengthLen(l) + l
+}
+
+// IsObjectApplicationContext reports whether the Application Context is of object identifier form.
+func (d *Dialogue) IsObjectApplicationContext() bool {
+	return d.ApplicationContext != nil && d.ApplicationContext.Tag == objectApplicationIDTag
+}
+
+// IntegerApplicationContext returns the Application Context of integer form,
+// or -1 if it does not exist or is of object identifier form.
+func (d *Dialogue) IntegerApplicationContext() int {
+	ac := d.ApplicationContext
+	if ac == nil || ac.Tag != integerApplicationIDTag {
+		return -1
+	}
+	return ber.DecodeInteger(ac.Value)
+}
+
+// String returns Dialogue in human readable string.
+func (d *Dialogue) String() string {
+	return fmt.Sprintf("{Tag: %#x, ProtocolVersion: %v, ApplicationContext: %v, UserInformation: %v, SecurityContext: %v, Confidentiality: %v}",
+		d.Tag,
+		d.ProtocolVersion,
+		d.ApplicationContext,
+		d.UserInformation,
+		d.SecurityContext,
+		d.Confidentiality,
+	)
+}
@@ -0,0 +1,246 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ansi
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Package Type definitions.
+const (
+	Unidirectional int = iota + 1
+	QueryWithPermission
+	QueryWithoutPermission
+	Response
+	ConversationWithPermission
+	ConversationWithoutPermission
+	Abort = 22
+)
+
+// P-Abort Cause definitions.
+const (
+	UnrecognizedPackageType uint8 = iota + 1
+	IncorrectTransactionPortion
+	BadlyStructuredTransactionPortion
+	UnassignedRespondingTransactionID
+	PermissionToReleaseProblem
+	ResourceUnavailable
+	UnrecognizedDialoguePortionID
+	BadlyStructuredDialoguePortion
+	MissingDialoguePortion
+	InconsistentDialoguePortion
+)
+
+// Tags of the elements in Transaction Portion.
+var (
+	transactionIDTag        = tcap.NewPrivatePrimitiveTag(7)
+	pAbortCauseTag          = tcap.NewPrivatePrimitiveTag(23)
+	userAbortInformationTag = tcap.NewPrivateConstructorTag(24)
+)
+
+// Transaction represents a Transaction Portion of ANSI TCAP, i.e. the Package Type
+// and the elements that are not in Dialogue Portion nor Component Portion.
+type Transaction struct {
+	Type tcap.Tag
+
+	// TransactionID has no octets in Unidirectional, the Originating ID in Query,
+	// the Responding ID in Response and Abort, and both of them in Conversation.
+	TransactionID        *tcap.IE
+	PAbortCause          *tcap.IE
+	UserAbortInformation *tcap.IE
+}
+
+// NewTransaction returns a new Transaction Portion.
+//
+// The Transaction ID is built from the IDs given that the package type can have.
+func NewTransaction(ptype int, otid, rtid uint32) *Transaction {
+	t := &Transaction{
+		Type: tcap.NewPrivateConstructorTag(ptype),
+	}
+
+	var tids []byte
+	switch ptype {
+	case QueryWithPermission, QueryWithoutPermission:
+		tids = make([]byte, 4)
+		binary.BigEndian.PutUint32(tids, otid)
+	case Response, Abort:
+		tids = make([]byte, 4)
+		binary.BigEndian.PutUint32(tids, rtid)
+	case ConversationWithPermission, ConversationWithoutPermission:
+		tids = make([]byte, 8)
+		binary.BigEndian.PutUint32(tids, otid)
+		binary.BigEndian.PutUint32(tids[4:], rtid)
+	default:
+		tids = []byte{}
+	}
+	t.TransactionID = tcap.NewIE(transactionIDTag, tids)
+
+	return t
+}
+
+// MarshalBinary returns the byte sequence generated from a Transaction instance.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	return t.AppendBinary(nil)
+}
+
+// AppendBinary appends the byte sequence generated from a Transaction instance to b.
+func (t *Transaction) AppendBinary(b []byte) ([]byte, error) {
+	b, start := ber.BeginTLV(b, uint8(t.Type))
+	b, err := t.appendFields(b)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = t.appendCause(b); err != nil {
+		return nil, err
+	}
+	return ber.EndTLV(b, start), nil
+}
+
+// appendFields appends the Transaction ID.
+//
+// The P-Abort Cause and User Abort Information are put after the Dialogue Portion
+// by appendCause, as they come last in Abort.
+func (t *Transaction) appendFields(b []byte) ([]byte, error) {
+	if field := t.TransactionID; field != nil {
+		return field.AppendBinary(b)
+	}
+	return b, nil
+}
+
+func (t *Transaction) appendCause(b []byte) ([]byte, error) {
+	if field := t.PAbortCause; field != nil {
+		return field.AppendBinary(b)
+	}
+	if field := t.UserAbortInformation; field != nil {
+		return field.AppendBinary(b)
+	}
+	return b, nil
+}
+
+// MarshalLen returns the serial length of Transaction.
+func (t *Transaction) MarshalLen() int {
+	l := 0
+	for _, field := range []*tcap.IE{t.TransactionID, t.PAbortCause, t.UserAbortInformation} {
+		if field != nil {
+			l += ieLen(field)
+		}
+	}
+	return 1 + ber.LengthLen(l) + l
+}
+
+// OTID returns the Originating Transaction ID, or 0 if the package type does not have it.
+func (t *Transaction) OTID() uint32 {
+	tid := t.TransactionID
+	if tid == nil || len(tid.Value) < 4 {
+		return 0
+	}
+
+	switch t.Type.Code() {
+	case QueryWithPermission, QueryWithoutPermission, ConversationWithPermission, ConversationWithoutPermission:
+		return binary.BigEndian.Uint32(tid.Value)
+	}
+	return 0
+}
+
+// RTID returns the Responding Transaction ID, or 0 if the package type does not have it.
+func (t *Transaction) RTID() uint32 {
+	tid := t.TransactionID
+	if tid == nil {
+		return 0
+	}
+
+	switch t.Type.Code() {
+	case Response, Abort:
+		if len(tid.Value) >= 4 {
+			return binary.BigEndian.Uint32(tid.Value)
+		}
+	case ConversationWithPermission, ConversationWithoutPermission:
+		if len(tid.Value) >= 8 {
+			return binary.BigEndian.Uint32(tid.Value[4:])
+		}
+	}
+	return 0
+}
+
+// PackageTypeString returns the name of Package Type in string.
+func (t *Transaction) PackageTypeString() string {
+	switch t.Type.Code() {
+	case Unidirectional:
+		return "Unidirectional"
+	case QueryWithPermission:
+		return "QueryWithPermission"
+	case QueryWithoutPermission:
+		return "QueryWithoutPermission"
+	case Response:
+		return "Response"
+	case ConversationWithPermission:
+		return "ConversationWithPermission"
+	case ConversationWithoutPermission:
+		return "ConversationWithoutPermission"
+	case Abort:
+		return "Abort"
+	}
+	return ""
+}
+
+// AbortCause returns the P-Abort Cause in string.
+func (t *Transaction) AbortCause() string {
+	cause := t.PAbortCause
+	if cause == nil || len(cause.Value) == 0 {
+		return ""
+	}
+
+	switch cause.Value[0] {
+	case UnrecognizedPackageType:
+		return "UnrecognizedPackageType"
+	case IncorrectTransactionPortion:
+		return "IncorrectTransactionPortion"
+	case BadlyStructuredTransactionPortion:
+		return "BadlyStructuredTransactionPortion"
+	case UnassignedRespondingTransactionID:
+		return "UnassignedRespondingTransactionID"
+	case PermissionToReleaseProblem:
+		return "PermissionToReleaseProblem"
+	case ResourceUnavailable:
+		return "ResourceUnavailable"
+	case UnrecognizedDialoguePortionID:
+		return "UnrecognizedDialoguePortionID"
+	case BadlyStructuredDialoguePortion:
+		return "BadlyStructuredDialoguePortion"
+	case MissingDialoguePortion:
+		return "MissingDialoguePortion"
+	case InconsistentDialoguePortion:
+		return "InconsistentDialoguePortion"
+	}
+	return ""
+}
+
+// String returns Transaction in human readable string.
+func (t *Transaction) String() string {
+	return fmt.Sprintf("{Type: %#x, TransactionID: %v, PAbortCause: %v, UserAbortInformation: %v}",
+		t.Type,
+		t.TransactionID,
+		t.PAbortCause,
+		t.UserAbortInformation,
+	)
+}
+
+// ieLen returns the serial length of an IE computed from its Value.
+func ieLen(i *tcap.IE) int {
+	return 1 + ber.LengthLen(len(i.Value)) + len(i.Value)
+}
+
+// parseIE returns an IE with the given tag and value, without copying the value.
+func parseIE(tag uint8, value []byte) *tcap.IE {
+	return &tcap.IE{
+		Tag:    tcap.Tag(tag),
+		Length: uint8(len(value)),
+		Value:  value,
+	}
+}
@@ -2,7 +2,8 @@ package tcap
 
 import (
 	"bytes"
-	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
 )
 
 func handleMarshalLen(elementLength uint8, len int) int {
@@ -24,14 +25,11 @@ func readLength(b []byte) (uint8, int) {
 		return uint8(int(lengthByte)), 2
 	} else {
 		lengthByte = (lengthByte & 127)
-		fmt.Println("lengthByte", lengthByte)
 		if lengthByte != 0 {
 			for i := 0; i < int(lengthByte); i++ {
 				tmp, _ := r.ReadByte()
-				length = int(byte(length)<<8 | 255&tmp)
+				length = length<<8 | int(tmp)
 			}
-			fmt.Println("lengthByte-> length", length)
-			// length++
 			return uint8(length), 3
 		}
 
@@ -40,42 +38,39 @@ func readLength(b []byte) (uint8, int) {
 }
 
 /*
-WriteLength to read the length based on the ASN1 Implementation. the first byte of the length indicates if it is long or short
+lengthLen returns the number of octets needed to encode l as a BER definite length.
 */
-func writeLength(b []byte, length uint8) int {
-	var offset int = 2
-	if length <= 127 {
-		b[1] = byte(length)
-		return offset
-	}
-	buf := make([]byte, 4)
-	// length = length - 1
-	var count int
-	if (int64(length) & int64(-16777216)) > 0 {
-		buf[0] = byte(length >> 24 & 255)
-		buf[1] = byte(length >> 16 & 255)
-		buf[2] = byte(length >> 8 & 255)
-		buf[3] = byte(length & 255)
-		count = 4
-	} else if (int64(length) & 16711680) > 0 {
-		buf[0] = byte(length >> 16 & 255)
-		buf[1] = byte(length >> 8 & 255)
-		buf[2] = byte(length & 255)
-		count = 3
+func lengthLen(l int) int {
+	return ber.LengthLen(l)
+}
 
-	} else if (int64(length) & 65280) > 0 {
-		buf[0] = byte(length >> 8 & 255)
-		buf[1] = byte(length & 255)
-		count = 2
-	} else {
-		buf[0] = byte(length & 255)
-		count = 1
-	}
-	fmt.Println("writeLength:count", count, "offset", offset)
-	b[offset-1] = byte(128 | count)
-	for i := 0; i < count; i++ {
-		b[offset+i] = buf[i]
-	}
-	offset = offset + count
-	return offset
+/*
+appendLength appends l to b as a BER definite length, using the short form whenever possible.
+*/
+func appendLength(b []byte, l int) []byte {
+	return ber.AppendLength(b, l)
+}
+
+/*
+beginTLV appends the tag and a single placeholder length octet to b and returns the offset where the contents start.
+The contents are then appended to b as usual, and the length is fixed by endTLV.
+*/
+func beginTLV(b []byte, tag Tag) ([]byte, int) {
+	return ber.BeginTLV(b, uint8(tag))
+}
+
+/*
+endTLV sets the length of the element opened by beginTLV at start, moving the contents forward if the long form is needed.
+*/
+func endTLV(b []byte, start int) []byte {
+	return ber.EndTLV(b, start)
+}
+
+/*
+readTLV reads a single BER element at the beginning of b without copying it, and returns the tag, the contents
+and the number of octets consumed. Only the definite form of length is supported.
+*/
+func readTLV(b []byte) (Tag, []byte, int, error) {
+	tag, value, n, err := ber.ReadTLV(b)
+	return Tag(tag), value, n, err
 }
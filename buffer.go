@@ -0,0 +1,57 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"io"
+	"sync"
+)
+
+// defaultBufferSize is the initial capacity of the pooled buffers, which is
+// large enough for a typical TCAP message carried in a single SCCP UDT.
+const defaultBufferSize = 272
+
+// Buffer is a reusable byte buffer to encode TCAP messages into.
+//
+// Use GetBuffer and PutBuffer to take it from and return it to the package-level
+// pool, so that the encoding on the hot path does not allocate per message.
+type Buffer struct {
+	B []byte
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &Buffer{B: make([]byte, 0, defaultBufferSize)}
+	},
+}
+
+// GetBuffer returns an empty Buffer from the pool.
+func GetBuffer() *Buffer {
+	buf := bufferPool.Get().(*Buffer)
+	buf.B = buf.B[:0]
+	return buf
+}
+
+// PutBuffer returns buf to the pool.
+//
+// The contents of buf.B must not be used after calling this.
+func PutBuffer(buf *Buffer) {
+	if buf == nil {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// marshalTo encodes with appendFn into b without growing it beyond len(b).
+func marshalTo(b []byte, appendFn func([]byte) ([]byte, error)) error {
+	out, err := appendFn(b[:0:len(b)])
+	if err != nil {
+		return err
+	}
+	if len(out) > len(b) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package callflow correlates TCAP messages into dialogues.
+
+Correlator groups the messages given in order by the Transaction IDs and the
+addresses of the sender and receiver, and reconstructs the sequence of each
+dialogue (Begin, Continues, and End or Abort) with the latency and the outcome.
+It also finds the anomalies such as Continue without the dialogue, Begin without
+any response and Begin with the Transaction ID already in use.
+
+	c := callflow.NewCorrelator(10 * time.Second)
+	for _, m := range msgs {
+		c.AddCaptured(m)
+	}
+	c.Close()
+	c.Report(os.Stdout)
+
+The addresses are arbitrary strings that identify the nodes, typically the SCCP
+Calling/Called Party Addresses. As Transaction IDs are allocated by each node,
+the ones from different nodes are told apart by them.
+*/
+package callflow
+
+import (
+	"sort"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/pcap"
+)
+
+// Message is a TCAP message with the time and the addresses it is sent from and to.
+type Message struct {
+	Time time.Time
+	Src  string
+	Dst  string
+	TCAP *tcap.TCAP
+}
+
+// Outcome is the result of a Dialogue.
+type Outcome int
+
+// Outcome definitions.
+const (
+	// Open is the Dialogue that is not ended yet.
+	Open Outcome = iota
+	// Ended is the Dialogue ended by End.
+	Ended
+	// UserAborted is the Dialogue aborted by Abort with the dialogue portion
+	// or without any cause.
+	UserAborted
+	// ProviderAborted is the Dialogue aborted by Abort with P-Abort Cause.
+	ProviderAborted
+	// Unanswered is the Dialogue with Begin only, which is timed out or closed.
+	Unanswered
+	// Incomplete is the Dialogue that is answered but not ended, which is timed
+	// out or closed.
+	Incomplete
+	// Unidirectional is the one-shot Unidirectional message.
+	Unidirectional
+)
+
+// String returns the name of Outcome.
+func (o Outcome) String() string {
+	switch o {
+	case Open:
+		return "open"
+	case Ended:
+		return "ended"
+	case UserAborted:
+		return "user-aborted"
+	case ProviderAborted:
+		return "provider-aborted"
+	case Unanswered:
+		return "unanswered"
+	case Incomplete:
+		return "incomplete"
+	case Unidirectional:
+		return "unidirectional"
+	}
+	return "unknown"
+}
+
+// Dialogue is a sequence of the messages in a TCAP dialogue.
+type Dialogue struct {
+	// ID is the sequence number of the Dialogue in the Correlator, starting at 1.
+	ID int
+
+	// Originator is the address that sent Begin, and Responder is the one that
+	// received it, which is replaced with the one that sent the first response
+	// as Begin is often sent to the address other than the node answering it,
+	// e.g. E.214 Global Title.
+	Originator string
+	Responder  string
+	// OrigTID is the Transaction ID allocated by the Originator, and RespTID is
+	// the one by the Responder, which is empty until the first Continue.
+	OrigTID string
+	RespTID string
+	// ApplicationContext is the Application Context Name in Begin, which is
+	// empty if not present.
+	ApplicationContext string
+
+	Messages []*Message
+	Outcome  Outcome
+}
+
+// Start returns the time of the first message.
+func (d *Dialogue) Start() time.Time {
+	return d.Messages[0].Time
+}
+
+// Latency returns the time from the first message to the first response from
+// the Responder, or 0 if there is no response.
+func (d *Dialogue) Latency() time.Duration {
+	for _, m := range d.Messages[1:] {
+		if m.Src == d.Responder {
+			return m.Time.Sub(d.Start())
+		}
+	}
+	return 0
+}
+
+// Duration returns the time from the first message to the last.
+func (d *Dialogue) Duration() time.Duration {
+	return d.Messages[len(d.Messages)-1].Time.Sub(d.Start())
+}
+
+// last returns the time of the last message.
+func (d *Dialogue) last() time.Time {
+	return d.Messages[len(d.Messages)-1].Time
+}
+
+// answered reports whether the Responder has sent any message.
+func (d *Dialogue) answered() bool {
+	for _, m := range d.Messages[1:] {
+		if m.Src == d.Responder {
+			return true
+		}
+	}
+	return false
+}
+
+// AnomalyKind is the kind of an Anomaly.
+type AnomalyKind int
+
+// AnomalyKind definitions.
+const (
+	// Orphan is Continue, End or Abort of which the Destination Transaction ID
+	// does not match any Dialogue.
+	Orphan AnomalyKind = iota
+	// UnansweredBegin is Begin without any response, which is timed out or closed.
+	UnansweredBegin
+	// TIDReuse is Begin with the Originating Transaction ID used in the Dialogue
+	// not ended yet, which is closed by it without UnansweredBegin.
+	TIDReuse
+)
+
+// String returns the name of AnomalyKind.
+func (k AnomalyKind) String() string {
+	switch k {
+	case Orphan:
+		return "orphan"
+	case UnansweredBegin:
+		return "unanswered-begin"
+	case TIDReuse:
+		return "tid-reuse"
+	}
+	return "unknown"
+}
+
+// Anomaly is a message or a Dialogue that does not follow the normal flow.
+type Anomaly struct {
+	Kind    AnomalyKind
+	Message *Message
+	// Dialogue is the Dialogue related to the Anomaly, which is nil with Orphan.
+	// With TIDReuse, it is the one closed by the new Begin.
+	Dialogue *Dialogue
+}
+
+// tidKey identifies a Transaction ID allocated by a node.
+type tidKey struct {
+	addr string
+	tid  string
+}
+
+// Correlator groups the TCAP messages into Dialogues.
+//
+// The messages should be given in the order of time.
+type Correlator struct {
+	// Timeout is the time the Dialogues can be idle for, which are closed
+	// when a message later than that is given. Zero means no timeout.
+	Timeout time.Duration
+
+	dialogues []*Dialogue
+	anomalies []*Anomaly
+	// the open Dialogues by the Transaction IDs of both sides.
+	open map[tidKey]*Dialogue
+}
+
+// NewCorrelator creates a Correlator with the timeout of the idle Dialogues.
+func NewCorrelator(timeout time.Duration) *Correlator {
+	return &Correlator{
+		Timeout: timeout,
+		open:    map[tidKey]*Dialogue{},
+	}
+}
+
+// Dialogues returns all the Dialogues in the order of the first message.
+func (c *Correlator) Dialogues() []*Dialogue {
+	return c.dialogues
+}
+
+// Anomalies returns all the Anomalies in the order they are found.
+func (c *Correlator) Anomalies() []*Anomaly {
+	return c.anomalies
+}
+
+// AddCaptured adds a message read from a capture file, with the SCCP Calling
+// and Called Party Addresses as the addresses. The one that cannot be parsed
+// is ignored.
+func (c *Correlator) AddCaptured(m *pcap.Message) {
+	if m.TCAP == nil {
+		return
+	}
+	c.Add(&Message{
+		Time: m.Timestamp,
+		Src:  m.CallingParty.String(),
+		Dst:  m.CalledParty.String(),
+		TCAP: m.TCAP,
+	})
+}
+
+// Add adds a message, and returns the Dialogue it belongs to, or nil if it is
+// an orphan or does not have the Transaction Portion.
+func (c *Correlator) Add(m *Message) *Dialogue {
+	tx := m.TCAP.Transaction
+	if tx == nil {
+		return nil
+	}
+	c.expire(m.Time)
+
+	switch tx.Type.Code() {
+	case tcap.Unidirectional:
+		d := c.newDialogue(m, "")
+		d.Outcome = Unidirectional
+		return d
+	case tcap.Begin:
+		key := tidKey{m.Src, tx.OTID()}
+		if old, ok := c.open[key]; ok {
+			c.close(old)
+			c.anomalies = append(c.anomalies, &Anomaly{Kind: TIDReuse, Message: m, Dialogue: old})
+		}
+		d := c.newDialogue(m, tx.OTID())
+		c.open[key] = d
+		return d
+	}
+
+	d, ok := c.open[tidKey{m.Dst, tx.DTID()}]
+	if !ok {
+		c.anomalies = append(c.anomalies, &Anomaly{Kind: Orphan, Message: m})
+		return nil
+	}
+	if !d.answered() && m.Dst == d.Originator && tx.DTID() == d.OrigTID {
+		d.Responder = m.Src
+	}
+	d.Messages = append(d.Messages, m)
+
+	switch tx.Type.Code() {
+	case tcap.Continue:
+		if d.RespTID == "" && m.Src == d.Responder {
+			d.RespTID = tx.OTID()
+			c.open[tidKey{d.Responder, d.RespTID}] = d
+		}
+	case tcap.End:
+		d.Outcome = Ended
+		c.remove(d)
+	case tcap.Abort:
+		d.Outcome = UserAborted
+		if tx.PAbortCause != nil {
+			d.Outcome = ProviderAborted
+		}
+		c.remove(d)
+	}
+	return d
+}
+
+// Close closes all the open Dialogues as Unanswered or Incomplete.
+func (c *Correlator) Close() {
+	for _, d := range c.dialogues {
+		if d.Outcome == Open {
+			c.closeIdle(d)
+		}
+	}
+}
+
+func (c *Correlator) newDialogue(m *Message, otid string) *Dialogue {
+	d := &Dialogue{
+		ID:         len(c.dialogues) + 1,
+		Originator: m.Src,
+		Responder:  m.Dst,
+		OrigTID:    otid,
+		Messages:   []*Message{m},
+	}
+	if t := m.TCAP; t.Dialogue != nil && t.Dialogue.DialoguePDU != nil {
+		d.ApplicationContext = applicationContext(t)
+	}
+
+	c.dialogues = append(c.dialogues, d)
+	return d
+}
+
+// close closes an open Dialogue as Unanswered or Incomplete.
+func (c *Correlator) close(d *Dialogue) {
+	if d.answered() {
+		d.Outcome = Incomplete
+	} else {
+		d.Outcome = Unanswered
+	}
+	c.remove(d)
+}
+
+// closeIdle closes an open Dialogue that has no more messages, reporting
+// UnansweredBegin if it is Unanswered.
+func (c *Correlator) closeIdle(d *Dialogue) {
+	c.close(d)
+	if d.Outcome == Unanswered {
+		c.anomalies = append(c.anomalies, &Anomaly{Kind: UnansweredBegin, Message: d.Messages[0], Dialogue: d})
+	}
+}
+
+// remove removes the Transaction IDs of the Dialogue from the open ones.
+func (c *Correlator) remove(d *Dialogue) {
+	for _, key := range []tidKey{{d.Originator, d.OrigTID}, {d.Responder, d.RespTID}} {
+		if c.open[key] == d {
+			delete(c.open, key)
+		}
+	}
+}
+
+// expire closes the Dialogues idle for longer than Timeout at now.
+func (c *Correlator) expire(now time.Time) {
+	if c.Timeout == 0 {
+		return
+	}
+
+	// Each open Dialogue is found by the Transaction ID of the Originator,
+	// and they are closed in order as the order of the map is random.
+	var idle []*Dialogue
+	for key, d := range c.open {
+		if key == (tidKey{d.Originator, d.OrigTID}) && now.Sub(d.last()) > c.Timeout {
+			idle = append(idle, d)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].ID < idle[j].ID })
+	for _, d := range idle {
+		c.closeIdle(d)
+	}
+}
@@ -0,0 +1,177 @@
+package callflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/pcap"
+)
+
+const (
+	hlr = "gt=819012345678 ssn=6"
+	vlr = "gt=819087654321 ssn=7"
+	msc = "gt=819011111111 ssn=8"
+	// e214 is the E.214 Global Title that Begin is sent to, which is answered
+	// by hlr.
+	e214 = "gt=819012345678901 ssn=6"
+)
+
+var base = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func at(ms int) time.Time {
+	return base.Add(time.Duration(ms) * time.Millisecond)
+}
+
+func abort(dtid uint32, cause uint8) *tcap.TCAP {
+	return &tcap.TCAP{Transaction: tcap.NewAbort(dtid, cause, nil)}
+}
+
+func TestCorrelator(t *testing.T) {
+	c := NewCorrelator(time.Second)
+	msgs := []*Message{
+		// #1: Begin, Continue, Continue and End.
+		{at(0), vlr, hlr, tcap.NewBeginInvoke(0x0a, 0, 2, []byte{0x04, 0x01, 0x00})},
+		{at(12), hlr, vlr, tcap.NewContinueInvoke(0x0b, 0x0a, 1, 7, []byte{0x04, 0x01, 0x00})},
+		{at(20), vlr, hlr, tcap.NewContinueReturnResult(0x0a, 0x0b, 1, 7, nil)},
+		// #2: the same OTID from another node.
+		{at(25), msc, hlr, tcap.NewBeginInvoke(0x0a, 0, 2, []byte{0x04, 0x01, 0x00})},
+		{at(30), hlr, vlr, tcap.NewEndReturnResult(0x0a, 0, 2, true, nil)},
+		// orphan, as #1 is ended.
+		{at(40), vlr, hlr, tcap.NewContinueInvoke(0x0a, 0x0b, 2, 7, nil)},
+		{at(50), hlr, msc, abort(0x0a, 1)},
+		// #3: unanswered, closed by #4 reusing the TID.
+		{at(100), vlr, hlr, tcap.NewBeginInvoke(0x0c, 0, 2, nil)},
+		// #4: answered but not ended, and timed out by #5.
+		{at(200), vlr, hlr, tcap.NewBeginInvoke(0x0c, 0, 2, nil)},
+		{at(210), hlr, vlr, tcap.NewContinueInvoke(0x0d, 0x0c, 1, 7, nil)},
+		// #5: unanswered, closed by Close.
+		{at(1300), msc, hlr, tcap.NewBeginInvoke(0x0e, 0, 2, nil)},
+		// #6: Begin to the E.214 Global Title, answered by hlr.
+		{at(1400), vlr, e214, tcap.NewBeginInvoke(0x0f, 0, 2, nil)},
+		{at(1415), hlr, vlr, tcap.NewContinueInvoke(0x10, 0x0f, 1, 7, nil)},
+		{at(1420), vlr, hlr, tcap.NewContinueReturnResult(0x0f, 0x10, 1, 7, nil)},
+		{at(1430), hlr, vlr, tcap.NewEndReturnResult(0x0f, 0, 2, true, nil)},
+	}
+	for _, m := range msgs {
+		c.Add(m)
+	}
+	c.Close()
+
+	type want struct {
+		orig, resp, otid, dtid string
+		n                      int
+		outcome                Outcome
+		latency, duration      int
+	}
+	wants := []want{
+		{vlr, hlr, "0000000a", "0000000b", 4, Ended, 12, 30},
+		{msc, hlr, "0000000a", "", 2, ProviderAborted, 25, 25},
+		{vlr, hlr, "0000000c", "", 1, Unanswered, 0, 0},
+		{vlr, hlr, "0000000c", "0000000d", 2, Incomplete, 10, 10},
+		{msc, hlr, "0000000e", "", 1, Unanswered, 0, 0},
+		{vlr, hlr, "0000000f", "00000010", 4, Ended, 15, 30},
+	}
+
+	ds := c.Dialogues()
+	if len(ds) != len(wants) {
+		t.Fatalf("got %d dialogues, want %d", len(ds), len(wants))
+	}
+	for i, w := range wants {
+		d := ds[i]
+		if d.ID != i+1 {
+			t.Errorf("#%d: got ID %d", i+1, d.ID)
+		}
+		if d.Originator != w.orig || d.Responder != w.resp || d.OrigTID != w.otid || d.RespTID != w.dtid {
+			t.Errorf("#%d: got %s/%s -> %s/%s", d.ID, d.Originator, d.OrigTID, d.Responder, d.RespTID)
+		}
+		if len(d.Messages) != w.n {
+			t.Errorf("#%d: got %d messages, want %d", d.ID, len(d.Messages), w.n)
+		}
+		if d.Outcome != w.outcome {
+			t.Errorf("#%d: got %v, want %v", d.ID, d.Outcome, w.outcome)
+		}
+		if got := d.Latency(); got != time.Duration(w.latency)*time.Millisecond {
+			t.Errorf("#%d: got latency %v, want %dms", d.ID, got, w.latency)
+		}
+		if got := d.Duration(); got != time.Duration(w.duration)*time.Millisecond {
+			t.Errorf("#%d: got duration %v, want %dms", d.ID, got, w.duration)
+		}
+	}
+
+	wantAnomalies := []struct {
+		kind     AnomalyKind
+		msg      *Message
+		dialogue int
+	}{
+		{Orphan, msgs[5], 0},
+		{TIDReuse, msgs[8], 3},
+		{UnansweredBegin, msgs[10], 5},
+	}
+	as := c.Anomalies()
+	if len(as) != len(wantAnomalies) {
+		t.Fatalf("got %d anomalies, want %d", len(as), len(wantAnomalies))
+	}
+	for i, w := range wantAnomalies {
+		a := as[i]
+		if a.Kind != w.kind || a.Message != w.msg {
+			t.Errorf("%d: got %v %v", i, a.Kind, a.Message.TCAP)
+		}
+		if (w.dialogue == 0 && a.Dialogue != nil) || (w.dialogue != 0 && (a.Dialogue == nil || a.Dialogue.ID != w.dialogue)) {
+			t.Errorf("%d: got dialogue %v", i, a.Dialogue)
+		}
+	}
+}
+
+func TestCorrelatorUnidirectional(t *testing.T) {
+	c := NewCorrelator(0)
+	d := c.Add(&Message{Time: base, Src: vlr, Dst: hlr, TCAP: &tcap.TCAP{Transaction: tcap.NewUnidirectional(nil)}})
+	if d == nil || d.Outcome != Unidirectional {
+		t.Fatalf("got %v", d)
+	}
+	c.Close()
+	if d.Outcome != Unidirectional || len(c.Anomalies()) != 0 {
+		t.Errorf("got %v, %v", d.Outcome, c.Anomalies())
+	}
+}
+
+func TestAddCaptured(t *testing.T) {
+	hlrAddr := &pcap.Address{GlobalTitleIndicator: 4, NumberingPlan: 1, NatureOfAddress: 4, SSN: 6, Digits: "819012345678"}
+	vlrAddr := &pcap.Address{GlobalTitleIndicator: 4, NumberingPlan: 1, NatureOfAddress: 4, SSN: 7, Digits: "819087654321"}
+
+	c := NewCorrelator(0)
+	c.AddCaptured(&pcap.Message{Timestamp: at(0), CallingParty: vlrAddr, CalledParty: hlrAddr, TCAP: tcap.NewBeginInvoke(1, 0, 2, nil)})
+	c.AddCaptured(&pcap.Message{Timestamp: at(5), CallingParty: hlrAddr, CalledParty: vlrAddr, TCAP: tcap.NewEndReturnResult(1, 0, 2, true, nil)})
+	c.AddCaptured(&pcap.Message{Timestamp: at(6), Data: []byte{0x00}})
+
+	ds := c.Dialogues()
+	if len(ds) != 1 || ds[0].Outcome != Ended || ds[0].Originator != vlr || ds[0].Responder != hlr {
+		t.Fatalf("got %v", ds)
+	}
+}
+
+func TestReport(t *testing.T) {
+	c := NewCorrelator(0)
+	c.Add(&Message{at(0), vlr, hlr, tcap.NewBeginInvoke(0x0a, 0, 2, nil)})
+	c.Add(&Message{at(12), hlr, vlr, tcap.NewEndReturnResult(0x0a, 0, 2, true, []byte{0x04, 0x01, 0x00})})
+	c.Add(&Message{at(20), hlr, vlr, tcap.NewEndReturnResult(0x0a, 0, 2, true, []byte{0x04, 0x01, 0x00})})
+
+	buf := &bytes.Buffer{}
+	if err := c.Report(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"#1 " + vlr + " -> " + hlr + " otid=0000000a ended latency=12ms duration=12ms",
+		"    12:00:00.000000 -> Begin otid=0000000a invoke(2)",
+		"    12:00:00.012000 <- End dtid=0000000a returnResultLast(2)",
+		"",
+		"anomalies: 1",
+		"    12:00:00.020000 orphan End dtid=0000000a returnResultLast(2) " + hlr + " -> " + vlr,
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package callflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// timeFormat is the format of the time of each message in the report.
+const timeFormat = "15:04:05.000000"
+
+// Report writes the Dialogues and the Anomalies in a human readable form, e.g.:
+//
+//	#1 gt=819012345678 ssn=6 -> gt=819087654321 ssn=8 acn=0.4.0.0.1.0.2.3 otid=0000000a dtid=0000000b ended latency=12ms duration=30ms
+//	    12:00:00.000000 -> Begin otid=0000000a invoke(59)
+//	    12:00:00.012000 <- Continue otid=0000000b dtid=0000000a returnResultLast(59)
+//	    12:00:00.030000 -> End dtid=0000000b
+//
+//	anomalies: 1
+//	    12:00:01.000000 orphan Continue otid=0000000c dtid=00000099 gt=819012345678 ssn=6 -> gt=819087654321 ssn=8
+func (c *Correlator) Report(w io.Writer) error {
+	for _, d := range c.dialogues {
+		if _, err := io.WriteString(w, d.String()+"\n"); err != nil {
+			return err
+		}
+		for _, m := range d.Messages {
+			dir := "->"
+			if m.Src != d.Originator {
+				dir = "<-"
+			}
+			if _, err := fmt.Fprintf(w, "    %s %s %s\n", m.Time.Format(timeFormat), dir, describe(m.TCAP)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(c.anomalies) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "\nanomalies: %d\n", len(c.anomalies)); err != nil {
+		return err
+	}
+	for _, a := range c.anomalies {
+		if _, err := io.WriteString(w, "    "+a.String()+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the summary of the Dialogue in a line.
+func (d *Dialogue) String() string {
+	fields := []string{fmt.Sprintf("#%d", d.ID), d.Originator, "->", d.Responder}
+	if d.ApplicationContext != "" {
+		fields = append(fields, "acn="+d.ApplicationContext)
+	}
+	if d.OrigTID != "" {
+		fields = append(fields, "otid="+d.OrigTID)
+	}
+	if d.RespTID != "" {
+		fields = append(fields, "dtid="+d.RespTID)
+	}
+	fields = append(fields, d.Outcome.String())
+	if l := d.Latency(); l != 0 {
+		fields = append(fields, "latency="+l.Round(time.Microsecond).String())
+	}
+	if len(d.Messages) > 1 {
+		fields = append(fields, "duration="+d.Duration().Round(time.Microsecond).String())
+	}
+	return strings.Join(fields, " ")
+}
+
+// String returns the Anomaly in a line with the time and the message.
+func (a *Anomaly) String() string {
+	m := a.Message
+	s := fmt.Sprintf("%s %s %s %s -> %s", m.Time.Format(timeFormat), a.Kind, describe(m.TCAP), m.Src, m.Dst)
+	if a.Dialogue != nil {
+		s += fmt.Sprintf(" (#%d)", a.Dialogue.ID)
+	}
+	return s
+}
+
+// describe returns the message type with the Transaction IDs and the components.
+func describe(t *tcap.TCAP) string {
+	tx := t.Transaction
+	fields := []string{tx.MessageTypeString()}
+	if otid := tx.OTID(); otid != "" {
+		fields = append(fields, "otid="+otid)
+	}
+	if dtid := tx.DTID(); dtid != "" {
+		fields = append(fields, "dtid="+dtid)
+	}
+	if tx.Type.Code() == tcap.Abort && tx.PAbortCause != nil {
+		fields = append(fields, "cause="+tx.AbortCause())
+	}
+
+	if t.Components != nil {
+		for _, c := range t.Components.Component {
+			name := c.ComponentTypeString()
+			switch {
+			case c.OperationCode != nil:
+				name += fmt.Sprintf("(%d)", ber.DecodeInteger(c.OperationCode.Value))
+			case c.ErrorCode != nil:
+				name += fmt.Sprintf("(%d)", ber.DecodeInteger(c.ErrorCode.Value))
+			}
+			fields = append(fields, name)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// applicationContext returns the Application Context Name in the dialogue
+// portion of t in dotted notation, or empty if not present.
+func applicationContext(t *tcap.TCAP) string {
+	acn := t.Dialogue.DialoguePDU.ApplicationContextName
+	if acn == nil {
+		return ""
+	}
+	_, v, _, err := ber.ReadTLV(acn.Value)
+	if err != nil {
+		return ""
+	}
+	oid, err := ber.FormatOID(v)
+	if err != nil {
+		return ""
+	}
+	return oid
+}
@@ -0,0 +1,309 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// EventTypeBCSM definitions.
+const (
+	CollectedInfo         uint8 = 2
+	AnalyzedInformation   uint8 = 3
+	RouteSelectFailure    uint8 = 4
+	OCalledPartyBusy      uint8 = 5
+	ONoAnswer             uint8 = 6
+	OAnswer               uint8 = 7
+	OMidCall              uint8 = 8
+	ODisconnect           uint8 = 9
+	OAbandon              uint8 = 10
+	TermAttemptAuthorized uint8 = 12
+	TBusy                 uint8 = 13
+	TNoAnswer             uint8 = 14
+	TAnswer               uint8 = 15
+	TMidCall              uint8 = 16
+	TDisconnect           uint8 = 17
+	TAbandon              uint8 = 18
+	OTermSeized           uint8 = 19
+	CallAccepted          uint8 = 27
+	OChangeOfPosition     uint8 = 50
+	TChangeOfPosition     uint8 = 51
+	OServiceChange        uint8 = 52
+	TServiceChange        uint8 = 53
+)
+
+var eventTypeBCSMNames = map[uint8]string{
+	CollectedInfo:         "collectedInfo",
+	AnalyzedInformation:   "analyzedInformation",
+	RouteSelectFailure:    "routeSelectFailure",
+	OCalledPartyBusy:      "oCalledPartyBusy",
+	ONoAnswer:             "oNoAnswer",
+	OAnswer:               "oAnswer",
+	OMidCall:              "oMidCall",
+	ODisconnect:           "oDisconnect",
+	OAbandon:              "oAbandon",
+	TermAttemptAuthorized: "termAttemptAuthorized",
+	TBusy:                 "tBusy",
+	TNoAnswer:             "tNoAnswer",
+	TAnswer:               "tAnswer",
+	TMidCall:              "tMidCall",
+	TDisconnect:           "tDisconnect",
+	TAbandon:              "tAbandon",
+	OTermSeized:           "oTermSeized",
+	CallAccepted:          "callAccepted",
+	OChangeOfPosition:     "oChangeOfPosition",
+	TChangeOfPosition:     "tChangeOfPosition",
+	OServiceChange:        "oServiceChange",
+	TServiceChange:        "tServiceChange",
+}
+
+// EventTypeBCSMString returns the name of EventTypeBCSM, e.g. "oAnswer".
+func EventTypeBCSMString(t uint8) string {
+	if name, ok := eventTypeBCSMNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", t)
+}
+
+// MonitorMode definitions.
+const (
+	Interrupted uint8 = iota
+	NotifyAndContinue
+	Transparent
+)
+
+// LegType definitions.
+const (
+	Leg1 uint8 = 1
+	Leg2 uint8 = 2
+)
+
+// LegID is the sendingSideID or receivingSideID of a call leg.
+type LegID struct {
+	Receiving bool
+	LegType   uint8
+}
+
+func (l *LegID) encode(e *encoder) {
+	tag := 0
+	if l.Receiving {
+		tag = 1
+	}
+	e.Octets(ctx, tag, []byte{l.LegType})
+}
+
+// decodeLegID decodes the explicitly tagged CHOICE of LegID in e.
+func decodeLegID(e *ber.Element) (*LegID, error) {
+	id, _, err := ber.ReadElement(e.Value)
+	if err != nil {
+		return nil, err
+	}
+	if id.Class != ctx || id.Tag > 1 {
+		return nil, dec.Unexpected(id)
+	}
+	t, err := dec.Octet(id)
+	if err != nil {
+		return nil, err
+	}
+	return &LegID{Receiving: id.Tag == 1, LegType: t}, nil
+}
+
+// BCSMEvent is an event to be reported, in requestReportBCSMEvent.
+type BCSMEvent struct {
+	EventTypeBCSM uint8
+	MonitorMode   uint8
+	// LegID is nil if not present.
+	LegID *LegID
+	// DPSpecificCriteria is the contents of dpSpecificCriteria as they are encoded.
+	DPSpecificCriteria []byte
+	AutomaticRearm     bool
+	Extra              []byte
+}
+
+// RequestReportBCSMEventArg is the argument of requestReportBCSMEvent.
+type RequestReportBCSMEventArg struct {
+	BCSMEvents []*BCSMEvent
+	Extra      []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a RequestReportBCSMEventArg.
+func (r *RequestReportBCSMEventArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Constructed(ctx, 0, func(e *encoder) {
+			for _, ev := range r.BCSMEvents {
+				e.Constructed(univ, tagSequence, func(e *encoder) {
+					e.Octet(ctx, 0, ev.EventTypeBCSM)
+					e.Octet(ctx, 1, ev.MonitorMode)
+					if ev.LegID != nil {
+						e.Constructed(ctx, 2, ev.LegID.encode)
+					}
+					if ev.DPSpecificCriteria != nil {
+						e.RawConstructed(ctx, 30, ev.DPSpecificCriteria)
+					}
+					e.Null(ctx, 50, ev.AutomaticRearm)
+					e.Raw(ev.Extra)
+				})
+			}
+		})
+		e.Raw(r.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RequestReportBCSMEventArg.
+func (r *RequestReportBCSMEventArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(ctx, 0) {
+		return dec.Missing("bcsmEvents")
+	}
+
+	*r = RequestReportBCSMEventArg{}
+	evs, err := ber.ReadElements(es[0].Value)
+	if err != nil {
+		return err
+	}
+	for _, x := range evs {
+		ev, err := decodeBCSMEvent(x)
+		if err != nil {
+			return err
+		}
+		r.BCSMEvents = append(r.BCSMEvents, ev)
+	}
+	for _, e := range es[1:] {
+		r.Extra = append(r.Extra, e.Raw...)
+	}
+	return nil
+}
+
+func decodeBCSMEvent(x *ber.Element) (*BCSMEvent, error) {
+	if !x.Is(univ, tagSequence) {
+		return nil, dec.Unexpected(x)
+	}
+	es, err := ber.ReadElements(x.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &BCSMEvent{}
+	found := 0
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			if ev.EventTypeBCSM, err = dec.Octet(e); err != nil {
+				return nil, err
+			}
+			found++
+		case e.Is(ctx, 1):
+			if ev.MonitorMode, err = dec.Octet(e); err != nil {
+				return nil, err
+			}
+			found++
+		case e.Is(ctx, 2):
+			if ev.LegID, err = decodeLegID(e); err != nil {
+				return nil, err
+			}
+		case e.Is(ctx, 30):
+			ev.DPSpecificCriteria = codec.Clone(e.Value)
+		case e.Is(ctx, 50):
+			ev.AutomaticRearm = true
+		default:
+			ev.Extra = append(ev.Extra, e.Raw...)
+		}
+	}
+
+	if found != 2 {
+		return nil, dec.Missing("eventTypeBCSM or monitorMode")
+	}
+	return ev, nil
+}
+
+// MessageType definitions in MiscCallInfo.
+const (
+	Request uint8 = iota
+	Notification
+)
+
+// EventReportBCSMArg is the argument of eventReportBCSM.
+type EventReportBCSMArg struct {
+	EventTypeBCSM uint8
+	// EventSpecificInformation is the contents of eventSpecificInformationBCSM
+	// as they are encoded.
+	EventSpecificInformation []byte
+	// LegID is nil if not present.
+	LegID *LegID
+	// MessageType is nil if MiscCallInfo is not present, which means Request.
+	MessageType *uint8
+	Extra       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an EventReportBCSMArg.
+func (r *EventReportBCSMArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Octet(ctx, 0, r.EventTypeBCSM)
+		if r.EventSpecificInformation != nil {
+			e.RawConstructed(ctx, 2, r.EventSpecificInformation)
+		}
+		if r.LegID != nil {
+			e.Constructed(ctx, 3, r.LegID.encode)
+		}
+		if r.MessageType != nil {
+			e.Constructed(ctx, 4, func(e *encoder) {
+				e.Octet(ctx, 0, *r.MessageType)
+			})
+		}
+		e.Raw(r.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an EventReportBCSMArg.
+func (r *EventReportBCSMArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*r = EventReportBCSMArg{}
+	hasType := false
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			if r.EventTypeBCSM, err = dec.Octet(e); err != nil {
+				return err
+			}
+			hasType = true
+		case e.Is(ctx, 2):
+			r.EventSpecificInformation = codec.Clone(e.Value)
+		case e.Is(ctx, 3):
+			if r.LegID, err = decodeLegID(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 4):
+			info, err := ber.ReadElements(e.Value)
+			if err != nil {
+				return err
+			}
+			if len(info) == 0 || !info[0].Is(ctx, 0) {
+				return dec.Missing("messageType")
+			}
+			t, err := dec.Octet(info[0])
+			if err != nil {
+				return err
+			}
+			r.MessageType = &t
+		default:
+			r.Extra = append(r.Extra, e.Raw...)
+		}
+	}
+
+	if !hasType {
+		return dec.Missing("eventTypeBCSM")
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// InitialDPArg is the argument of initialDP.
+type InitialDPArg struct {
+	ServiceKey int
+	// CalledPartyNumber and CallingPartyNumber are nil if not present.
+	CalledPartyNumber      *param.CalledPartyNumber
+	CallingPartyNumber     *param.CallingPartyNumber
+	CallingPartysCategory  []byte
+	LocationNumber         []byte
+	OriginalCalledPartyID  []byte
+	HighLayerCompatibility []byte
+	// BearerCapability is the bearerCap, the contents of the Bearer
+	// Capability information element in Q.931.
+	BearerCapability []byte
+	// EventTypeBCSM is nil if not present.
+	EventTypeBCSM          *uint8
+	RedirectingPartyID     []byte
+	RedirectionInformation []byte
+	IMSI                   string
+	// LocationInformation is the contents of LocationInformation in MAP as
+	// they are encoded.
+	LocationInformation  []byte
+	CallReferenceNumber  []byte
+	MSCAddress           *param.AddressString
+	CalledPartyBCDNumber []byte
+	TimeAndTimezone      []byte
+	Extra                []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InitialDPArg.
+func (i *InitialDPArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Integer(ctx, 0, i.ServiceKey)
+		if i.CalledPartyNumber != nil {
+			e.Value(ctx, 2, i.CalledPartyNumber)
+		}
+		if i.CallingPartyNumber != nil {
+			e.Value(ctx, 3, i.CallingPartyNumber)
+		}
+		e.Octets(ctx, 5, i.CallingPartysCategory)
+		e.Octets(ctx, 10, i.LocationNumber)
+		e.Octets(ctx, 12, i.OriginalCalledPartyID)
+		e.Octets(ctx, 23, i.HighLayerCompatibility)
+		if i.BearerCapability != nil {
+			e.Constructed(ctx, 27, func(e *encoder) {
+				e.Octets(ctx, 0, i.BearerCapability)
+			})
+		}
+		if i.EventTypeBCSM != nil {
+			e.Octet(ctx, 28, *i.EventTypeBCSM)
+		}
+		e.Octets(ctx, 29, i.RedirectingPartyID)
+		e.Octets(ctx, 30, i.RedirectionInformation)
+		e.TBCD(ctx, 50, i.IMSI)
+		if i.LocationInformation != nil {
+			e.RawConstructed(ctx, 52, i.LocationInformation)
+		}
+		e.Octets(ctx, 54, i.CallReferenceNumber)
+		if i.MSCAddress != nil {
+			e.Value(ctx, 55, i.MSCAddress)
+		}
+		e.Octets(ctx, 56, i.CalledPartyBCDNumber)
+		e.Octets(ctx, 57, i.TimeAndTimezone)
+		e.Raw(i.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InitialDPArg.
+func (i *InitialDPArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*i = InitialDPArg{}
+	hasServiceKey := false
+	for _, e := range es {
+		if e.Class != ctx {
+			i.Extra = append(i.Extra, e.Raw...)
+			continue
+		}
+
+		switch e.Tag {
+		case 0:
+			i.ServiceKey = ber.DecodeInteger(e.Value)
+			hasServiceKey = true
+		case 2:
+			i.CalledPartyNumber = &param.CalledPartyNumber{}
+			if err := decode(e, i.CalledPartyNumber); err != nil {
+				return err
+			}
+		case 3:
+			i.CallingPartyNumber = &param.CallingPartyNumber{}
+			if err := decode(e, i.CallingPartyNumber); err != nil {
+				return err
+			}
+		case 5:
+			i.CallingPartysCategory = codec.Clone(e.Value)
+		case 10:
+			i.LocationNumber = codec.Clone(e.Value)
+		case 12:
+			i.OriginalCalledPartyID = codec.Clone(e.Value)
+		case 23:
+			i.HighLayerCompatibility = codec.Clone(e.Value)
+		case 27:
+			bc, _, err := ber.ReadElement(e.Value)
+			if err != nil {
+				return err
+			}
+			if !bc.Is(ctx, 0) {
+				// bearerInformation in phase 1, which is not supported.
+				i.Extra = append(i.Extra, e.Raw...)
+				continue
+			}
+			i.BearerCapability = codec.Clone(bc.Value)
+		case 28:
+			t, err := dec.Octet(e)
+			if err != nil {
+				return err
+			}
+			i.EventTypeBCSM = &t
+		case 29:
+			i.RedirectingPartyID = codec.Clone(e.Value)
+		case 30:
+			i.RedirectionInformation = codec.Clone(e.Value)
+		case 50:
+			i.IMSI = param.DecodeTBCD(e.Value)
+		case 52:
+			i.LocationInformation = codec.Clone(e.Value)
+		case 54:
+			i.CallReferenceNumber = codec.Clone(e.Value)
+		case 55:
+			i.MSCAddress = &param.AddressString{}
+			if err := decode(e, i.MSCAddress); err != nil {
+				return err
+			}
+		case 56:
+			i.CalledPartyBCDNumber = codec.Clone(e.Value)
+		case 57:
+			i.TimeAndTimezone = codec.Clone(e.Value)
+		default:
+			i.Extra = append(i.Extra, e.Raw...)
+		}
+	}
+
+	if !hasServiceKey {
+		return dec.Missing("serviceKey")
+	}
+	return nil
+}
+
+// ConnectArg is the argument of connect.
+type ConnectArg struct {
+	// DestinationRoutingAddress is the list of CalledPartyNumber, which has
+	// only one in the CAP.
+	DestinationRoutingAddress []*param.CalledPartyNumber
+	AlertingPattern           []byte
+	OriginalCalledPartyID     []byte
+	CallingPartysCategory     []byte
+	RedirectingPartyID        []byte
+	RedirectionInformation    []byte
+	SuppressionOfAnnouncement bool
+	OCSIApplicable            bool
+	Extra                     []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ConnectArg.
+func (c *ConnectArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Constructed(ctx, 0, func(e *encoder) {
+			for _, n := range c.DestinationRoutingAddress {
+				e.Value(univ, tagOctetString, n)
+			}
+		})
+		e.Octets(ctx, 1, c.AlertingPattern)
+		e.Octets(ctx, 6, c.OriginalCalledPartyID)
+		e.Octets(ctx, 28, c.CallingPartysCategory)
+		e.Octets(ctx, 29, c.RedirectingPartyID)
+		e.Octets(ctx, 30, c.RedirectionInformation)
+		e.Raw(c.Extra)
+		e.Null(ctx, 55, c.SuppressionOfAnnouncement)
+		e.Null(ctx, 56, c.OCSIApplicable)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ConnectArg.
+func (c *ConnectArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*c = ConnectArg{}
+	for _, e := range es {
+		if e.Class != ctx {
+			c.Extra = append(c.Extra, e.Raw...)
+			continue
+		}
+
+		switch e.Tag {
+		case 0:
+			ns, err := ber.ReadElements(e.Value)
+			if err != nil {
+				return err
+			}
+			for _, n := range ns {
+				if !n.Is(univ, tagOctetString) {
+					return dec.Unexpected(n)
+				}
+				number := &param.CalledPartyNumber{}
+				if err := decode(n, number); err != nil {
+					return err
+				}
+				c.DestinationRoutingAddress = append(c.DestinationRoutingAddress, number)
+			}
+		case 1:
+			c.AlertingPattern = codec.Clone(e.Value)
+		case 6:
+			c.OriginalCalledPartyID = codec.Clone(e.Value)
+		case 28:
+			c.CallingPartysCategory = codec.Clone(e.Value)
+		case 29:
+			c.RedirectingPartyID = codec.Clone(e.Value)
+		case 30:
+			c.RedirectionInformation = codec.Clone(e.Value)
+		case 55:
+			c.SuppressionOfAnnouncement = true
+		case 56:
+			c.OCSIApplicable = true
+		default:
+			c.Extra = append(c.Extra, e.Raw...)
+		}
+	}
+
+	if len(c.DestinationRoutingAddress) == 0 {
+		return dec.Missing("destinationRoutingAddress")
+	}
+	return nil
+}
+
+// ReleaseCallArg is the argument of releaseCall, the Cause in Q.850.
+type ReleaseCallArg struct {
+	Cause []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ReleaseCallArg.
+func (r *ReleaseCallArg) MarshalBinary() ([]byte, error) {
+	if r.Cause == nil {
+		return nil, dec.Missing("cause")
+	}
+	return ber.AppendElement(nil, univ, false, tagOctetString, r.Cause), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ReleaseCallArg.
+func (r *ReleaseCallArg) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+	if !e.Is(univ, tagOctetString) {
+		return dec.Unexpected(e)
+	}
+	r.Cause = codec.Clone(e.Value)
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package cap provides the typed arguments of the CAMEL Application Part (CAP)
+operations for the circuit-switched calls in 3GPP TS 29.078, phase 2 to 4, and
+the parameters of the errors.
+
+The arguments are encoded in and decoded from Parameter in Component by the
+Operation Code and the phase of the Application Context Name, in the same way
+as the MAP operations in gsmmap package.
+
+	p, err := cap.Decode(t.Components.Component[0], cap.ContextPhase(t))
+	if err != nil {
+		// ...
+	}
+	if idp, ok := p.(*cap.InitialDPArg); ok {
+		fmt.Println(idp.ServiceKey)
+	}
+
+CalledPartyNumber, CallingPartyNumber and the addresses are the types in param
+package. The other numbers in the ISUP format are kept as the encoded octets.
+The elements not modeled in the structs are kept in Extra as
+they are encoded, which is appended at the end of the SEQUENCE in encoding.
+*/
+package cap
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Operation Code definitions.
+const (
+	InitialDP              = 0
+	Connect                = 20
+	ReleaseCall            = 22
+	RequestReportBCSMEvent = 23
+	EventReportBCSM        = 24
+	Continue               = 31
+	ApplyCharging          = 35
+	ApplyChargingReport    = 36
+)
+
+// Parameter is an argument of an operation or a parameter of an error, which
+// is encoded as the whole element including the tag and the length.
+type Parameter = tcap.TypedParameter
+
+// arguments have the constructors of the arguments by the Operation Code. The
+// nil one means that the operation does not have the argument.
+var arguments = map[int]func() Parameter{
+	InitialDP:              func() Parameter { return &InitialDPArg{} },
+	Connect:                func() Parameter { return &ConnectArg{} },
+	ReleaseCall:            func() Parameter { return &ReleaseCallArg{} },
+	RequestReportBCSMEvent: func() Parameter { return &RequestReportBCSMEventArg{} },
+	EventReportBCSM:        func() Parameter { return &EventReportBCSMArg{} },
+	Continue:               nil,
+	ApplyCharging:          func() Parameter { return &ApplyChargingArg{} },
+	ApplyChargingReport:    func() Parameter { return &ApplyChargingReportArg{} },
+}
+
+// NewArgument returns the empty argument of the operation in the phase.
+//
+// It returns nil without error if the operation does not have the argument.
+func NewArgument(opCode, phase int) (Parameter, error) {
+	f, ok := arguments[opCode]
+	if !ok || phase < 2 || phase > 4 {
+		return nil, &UnsupportedOperationError{OpCode: opCode, Phase: phase}
+	}
+	if f == nil {
+		return nil, nil
+	}
+	return f(), nil
+}
+
+// DecodeArgument decodes b as the argument of the operation in the phase.
+func DecodeArgument(opCode, phase int, b []byte) (Parameter, error) {
+	p, err := NewArgument(opCode, phase)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("operation %d does not have argument", opCode)}
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Decode decodes the Parameter in c, as the argument if c is Invoke or as the
+// parameter of the error if c is ReturnError, in the phase given.
+//
+// It returns nil without error if c does not have the Parameter.
+func Decode(c *tcap.Component, phase int) (Parameter, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+	b, err := c.Parameter.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Type.Code() {
+	case tcap.Invoke:
+		code, err := localCode(c.OperationCode)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeArgument(code, phase, b)
+	case tcap.ReturnError:
+		code, err := localCode(c.ErrorCode)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeError(code, b)
+	}
+	return nil, &InvalidParameterError{Reason: fmt.Sprintf("unexpected component type: %s", c.ComponentTypeString())}
+}
+
+// localCode returns the value of the local Operation or Error Code.
+func localCode(ie *tcap.IE) (int, error) {
+	if ie == nil {
+		return 0, &InvalidParameterError{Reason: "no operation or error code in component"}
+	}
+	if ie.Tag != tcap.NewUniversalPrimitiveTag(2) {
+		return 0, &InvalidParameterError{Reason: "global code is not supported"}
+	}
+	return ber.DecodeInteger(ie.Value), nil
+}
+
+// SetParameter sets p encoded as the Parameter in c.
+func SetParameter(c *tcap.Component, p Parameter) error {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	ie, err := tcap.ParseIERecursive(b)
+	if err != nil {
+		return err
+	}
+	c.Parameter = ie
+	c.SetLength()
+	return nil
+}
+
+// The prefix of the CAP Application Context Names, which are followed by
+// {cap-gsmssf-to-gsmscf(50) version}, {cap3OE(21) ac(3) id} or {cap4OE(22) ac(3) id}.
+var acPrefix = []byte{0x04, 0x00, 0x00, 0x01}
+
+// ContextPhase returns the phase of the CAP Application Context Name in the
+// dialogue portion of t, or 0 if not present or not the one of CAP.
+func ContextPhase(t *tcap.TCAP) int {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil || d.DialoguePDU.ApplicationContextName == nil {
+		return 0
+	}
+	_, v, _, err := ber.ReadTLV(d.DialoguePDU.ApplicationContextName.Value)
+	if err != nil {
+		return 0
+	}
+	return phase(v)
+}
+
+func phase(oid []byte) int {
+	if len(oid) != 7 || !bytes.HasPrefix(oid, acPrefix) {
+		return 0
+	}
+	switch {
+	case oid[4] == 0x00 && oid[5] == tcap.CapGsmSSFToGsmSCFContext:
+		// version1 is phase 1, and version2 is phase 2.
+		return int(oid[6]) + 1
+	case oid[4] == 21 && oid[5] == 3:
+		return 3
+	case oid[4] == 22 && oid[5] == 3:
+		return 4
+	}
+	return 0
+}
+
+// InvalidParameterError indicates that a Parameter cannot be encoded or decoded.
+type InvalidParameterError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidParameterError) Error() string {
+	return "cap: invalid parameter: " + e.Reason
+}
+
+// UnsupportedOperationError indicates that the operation is not supported in
+// the phase.
+type UnsupportedOperationError struct {
+	OpCode int
+	Phase  int
+}
+
+// Error returns error message with violating content.
+func (e *UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("cap: unsupported operation: %d in phase %d", e.OpCode, e.Phase)
+}
@@ -0,0 +1,306 @@
+package cap_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/cap"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	called = &param.CalledPartyNumber{
+		NatureOfAddress:       param.ISUPNationalNumber,
+		InternalNetworkNumber: true,
+		NumberingPlan:         param.PlanISDN,
+		Digits:                "123456",
+	}
+	calling = &param.CallingPartyNumber{
+		NatureOfAddress: param.ISUPNationalNumber,
+		NumberingPlan:   param.PlanISDN,
+		Screening:       param.NetworkProvided,
+		Digits:          "123456",
+	}
+)
+
+func uint8p(v uint8) *uint8 {
+	return &v
+}
+
+func intp(v int) *int {
+	return &v
+}
+
+var cases = []struct {
+	description string
+	opCode      int
+	structured  cap.Parameter
+	serialized  string
+}{
+	{
+		"initialDP", cap.InitialDP,
+		&cap.InitialDPArg{
+			ServiceKey:            100,
+			CalledPartyNumber:     called,
+			CallingPartyNumber:    calling,
+			CallingPartysCategory: []byte{0x0a},
+			EventTypeBCSM:         uint8p(cap.CollectedInfo),
+			IMSI:                  "001010123456789",
+			MSCAddress:            param.NewISDNAddress("8190123456"),
+			TimeAndTimezone:       mustHex("02011040000000"),
+		},
+		"3035800164820503902143658305031321436585010a9c01029f320800010121436587f99f37069118092143659f390702011040000000",
+	}, {
+		"connect", cap.Connect,
+		&cap.ConnectArg{DestinationRoutingAddress: []*param.CalledPartyNumber{called}, CallingPartysCategory: []byte{0x0a}},
+		"300ca007040503902143659c010a",
+	}, {
+		"releaseCall", cap.ReleaseCall,
+		&cap.ReleaseCallArg{Cause: mustHex("8090")},
+		"04028090",
+	}, {
+		"requestReportBCSMEvent", cap.RequestReportBCSMEvent,
+		&cap.RequestReportBCSMEventArg{
+			BCSMEvents: []*cap.BCSMEvent{
+				{EventTypeBCSM: cap.OAnswer, MonitorMode: cap.NotifyAndContinue, LegID: &cap.LegID{LegType: cap.Leg2}},
+				{EventTypeBCSM: cap.ODisconnect, MonitorMode: cap.Interrupted},
+			},
+		},
+		"3017a015300b800107810101a2038001023006800109810100",
+	}, {
+		"eventReportBCSM", cap.EventReportBCSM,
+		&cap.EventReportBCSMArg{
+			EventTypeBCSM: cap.OAnswer,
+			LegID:         &cap.LegID{Receiving: true, LegType: cap.Leg2},
+			MessageType:   uint8p(cap.Notification),
+		},
+		"300d800107a303810102a403800101",
+	}, {
+		"applyCharging", cap.ApplyCharging,
+		&cap.ApplyChargingArg{
+			MaxCallPeriodDuration:     3000,
+			ReleaseIfDurationExceeded: true,
+			PartyToCharge:             &cap.LegID{LegType: cap.Leg1},
+		},
+		"30108009a00780020bb88101ffa203800101",
+	}, {
+		"applyChargingReport", cap.ApplyChargingReport,
+		&cap.ApplyChargingReportArg{
+			PartyToCharge:        cap.LegID{Receiving: true, LegType: cap.Leg1},
+			TimeIfNoTariffSwitch: intp(300),
+			CallActive:           true,
+		},
+		"0410a00ea003810101a1048002012c8201ff",
+	}, {
+		"applyChargingReport with tariff switch", cap.ApplyChargingReport,
+		&cap.ApplyChargingReportArg{
+			PartyToCharge:           cap.LegID{Receiving: true, LegType: cap.Leg2},
+			TimeIfTariffSwitch:      &cap.TimeIfTariffSwitch{TimeSinceTariffSwitch: 100, TariffSwitchInterval: intp(3600)},
+			CallReleasedAtTCPExpiry: true,
+		},
+		"0417a015a003810102a109a10780016481020e108201008300",
+	},
+}
+
+func TestArguments(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+
+			got, err := cap.DecodeArgument(c.opCode, 2, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		code        int
+		structured  cap.Parameter
+		serialized  string
+	}{
+		{
+			"cancelFailed", cap.CancelFailed,
+			&cap.CancelFailedParam{Problem: cap.TooLate, OperationID: 5},
+			"3006800101810105",
+		}, {
+			"taskRefused", cap.TaskRefused,
+			func() cap.Parameter { r := cap.Congestion; return &r }(),
+			"0a0102",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+
+			got, err := cap.DecodeError(c.code, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+
+	if _, err := cap.DecodeError(cap.MissingParameter, mustHex("0a0100")); err == nil {
+		t.Error("expected error with the error without parameter")
+	}
+	if got := cap.ErrorString(cap.UnknownLegID); got != "unknownLegID" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, c := range []struct {
+		ctx, ver uint8
+		want     int
+	}{
+		{tcap.CapGsmSSFToGsmSCFContext, 1, 2},
+		{tcap.CapGsmSSFToGsmSCFContext, 2, 3},
+	} {
+		begin := tcap.NewBeginInvokeWithDialogue(1, tcap.DialogueAsID, c.ctx, c.ver, 0, cap.EventReportBCSM,
+			mustHex("300d800107a303810102a403800101"))
+		b, err := begin.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := tcap.Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		phase := cap.ContextPhase(msg)
+		if phase != c.want {
+			t.Fatalf("got phase %d, want %d", phase, c.want)
+		}
+		p, err := cap.Decode(msg.Components.Component[0], phase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if arg, ok := p.(*cap.EventReportBCSMArg); !ok || arg.EventTypeBCSM != cap.OAnswer {
+			t.Errorf("got %#v", p)
+		}
+	}
+}
+
+func TestSetParameter(t *testing.T) {
+	c := tcap.NewInvoke(1, -1, cap.Connect, true, nil)
+	arg := &cap.ConnectArg{DestinationRoutingAddress: []*param.CalledPartyNumber{called}, SuppressionOfAnnouncement: true}
+	if err := cap.SetParameter(c, arg); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := tcap.ParseComponents(append([]byte{0x6c, byte(len(b))}, b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cap.Decode(parsed.Component[0], 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
+
+func TestInvalidParameter(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		opCode      int
+		serialized  string
+	}{
+		{"truncated", cap.InitialDP, "30358001"},
+		{"missing mandatory", cap.InitialDP, "300782058390214365"},
+		{"unexpected tag", cap.Connect, "a000"},
+		{"trailing bytes", cap.ReleaseCall, "0402809000"},
+		{"missing time information", cap.ApplyChargingReport, "0407a005a003810101"},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := cap.DecodeArgument(c.opCode, 3, mustHex(c.serialized)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := cap.DecodeArgument(cap.Continue, 2, mustHex("3000")); err == nil {
+		t.Error("expected error with the operation without argument")
+	}
+	if _, err := cap.NewArgument(cap.InitialDP, 1); err == nil {
+		t.Error("expected error with unsupported phase")
+	}
+}
+
+func TestOperations(t *testing.T) {
+	begin := tcap.NewBeginInvokeWithDialogue(1, tcap.DialogueAsID, tcap.CapGsmSSFToGsmSCFContext, 1, 0, cap.InitialDP,
+		mustHex("3035800164820503902143658305031321436585010a9c01029f320800010121436587f99f37069118092143659f390702011040000000"))
+	b, err := begin.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := msg.Components.Component[0]
+	op := tcap.DefaultRegistry.LookupComponent(msg, c)
+	if op == nil {
+		t.Fatal("initialDP not registered")
+	}
+	if op.Name != "initialDP" || op.Class != tcap.Class2 {
+		t.Errorf("got %+v", op)
+	}
+	p, err := op.Decode(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idp, ok := p.(*cap.InitialDPArg); !ok || idp.ServiceKey != 100 {
+		t.Errorf("got %#v", p)
+	}
+
+	for phase := 2; phase <= 4; phase++ {
+		op := tcap.LookupOperation(cap.ApplicationContext(phase), cap.Continue)
+		if op == nil || op.Name != "continue" || op.NewArgument != nil {
+			t.Errorf("phase %d: got %+v", phase, op)
+		}
+		e := tcap.LookupError(cap.ApplicationContext(phase), cap.CancelFailed)
+		if e == nil || e.Name != "cancelFailed" || e.NewParameter == nil {
+			t.Errorf("phase %d: got %+v", phase, e)
+		}
+	}
+}
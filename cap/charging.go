@@ -0,0 +1,227 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// ApplyChargingArg is the argument of applyCharging, with timeDurationCharging
+// in aChBillingChargingCharacteristics.
+type ApplyChargingArg struct {
+	// MaxCallPeriodDuration is in 100 milliseconds.
+	MaxCallPeriodDuration     int
+	ReleaseIfDurationExceeded bool
+	// TariffSwitchInterval is in seconds, which is nil if not present.
+	TariffSwitchInterval *int
+	// ChargingExtra is the other elements in timeDurationCharging as they are encoded.
+	ChargingExtra []byte
+	// PartyToCharge is nil if not present, which means leg1.
+	PartyToCharge *LegID
+	Extra         []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingArg.
+func (a *ApplyChargingArg) MarshalBinary() ([]byte, error) {
+	chars, err := codec.Tagged(ctx, 0, func(e *encoder) {
+		e.Integer(ctx, 0, a.MaxCallPeriodDuration)
+		if a.ReleaseIfDurationExceeded {
+			e.Boolean(ctx, 1, true)
+		}
+		if a.TariffSwitchInterval != nil {
+			e.Integer(ctx, 2, *a.TariffSwitchInterval)
+		}
+		e.Raw(a.ChargingExtra)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Octets(ctx, 0, chars)
+		if a.PartyToCharge != nil {
+			e.Constructed(ctx, 2, a.PartyToCharge.encode)
+		}
+		e.Raw(a.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingArg.
+func (a *ApplyChargingArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(ctx, 0) {
+		return dec.Missing("aChBillingChargingCharacteristics")
+	}
+
+	*a = ApplyChargingArg{}
+	chars, err := dec.ReadElement(es[0].Value)
+	if err != nil {
+		return err
+	}
+	if !chars.Is(ctx, 0) {
+		return dec.Unexpected(chars)
+	}
+	fields, err := ber.ReadElements(chars.Value)
+	if err != nil {
+		return err
+	}
+	hasMax := false
+	for _, e := range fields {
+		switch {
+		case e.Is(ctx, 0):
+			a.MaxCallPeriodDuration = ber.DecodeInteger(e.Value)
+			hasMax = true
+		case e.Is(ctx, 1):
+			a.ReleaseIfDurationExceeded = codec.Boolean(e)
+		case e.Is(ctx, 2):
+			a.TariffSwitchInterval = decodeInteger(e)
+		default:
+			a.ChargingExtra = append(a.ChargingExtra, e.Raw...)
+		}
+	}
+	if !hasMax {
+		return dec.Missing("maxCallPeriodDuration")
+	}
+
+	for _, e := range es[1:] {
+		switch {
+		case e.Is(ctx, 2):
+			if a.PartyToCharge, err = decodeLegID(e); err != nil {
+				return err
+			}
+		default:
+			a.Extra = append(a.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// ApplyChargingReportArg is the argument of applyChargingReport, with
+// timeDurationChargingResult in CallResult.
+type ApplyChargingReportArg struct {
+	PartyToCharge LegID
+	// Either TimeIfNoTariffSwitch or TimeIfTariffSwitch is present, in 100
+	// milliseconds.
+	TimeIfNoTariffSwitch    *int
+	TimeIfTariffSwitch      *TimeIfTariffSwitch
+	CallActive              bool
+	CallReleasedAtTCPExpiry bool
+	Extra                   []byte
+}
+
+// TimeIfTariffSwitch is the time information when the tariff has been switched.
+type TimeIfTariffSwitch struct {
+	TimeSinceTariffSwitch int
+	// TariffSwitchInterval is nil if not present.
+	TariffSwitchInterval *int
+}
+
+// MarshalBinary returns the byte sequence generated from an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) MarshalBinary() ([]byte, error) {
+	result, err := codec.Tagged(ctx, 0, func(e *encoder) {
+		e.Constructed(ctx, 0, a.PartyToCharge.encode)
+		e.Constructed(ctx, 1, func(e *encoder) {
+			switch t := a.TimeIfTariffSwitch; {
+			case t != nil:
+				e.Constructed(ctx, 1, func(e *encoder) {
+					e.Integer(ctx, 0, t.TimeSinceTariffSwitch)
+					if t.TariffSwitchInterval != nil {
+						e.Integer(ctx, 1, *t.TariffSwitchInterval)
+					}
+				})
+			case a.TimeIfNoTariffSwitch != nil:
+				e.Integer(ctx, 0, *a.TimeIfNoTariffSwitch)
+			default:
+				e.SetError(dec.Missing("timeInformation"))
+			}
+		})
+		e.Boolean(ctx, 2, a.CallActive)
+		e.Null(ctx, 3, a.CallReleasedAtTCPExpiry)
+		e.Raw(a.Extra)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ber.AppendElement(nil, univ, false, tagOctetString, result), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ApplyChargingReportArg.
+func (a *ApplyChargingReportArg) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+	if !e.Is(univ, tagOctetString) {
+		return dec.Unexpected(e)
+	}
+	result, err := dec.ReadElement(e.Value)
+	if err != nil {
+		return err
+	}
+	if !result.Is(ctx, 0) {
+		return dec.Unexpected(result)
+	}
+	es, err := ber.ReadElements(result.Value)
+	if err != nil {
+		return err
+	}
+
+	*a = ApplyChargingReportArg{CallActive: true}
+	hasParty, hasTime := false, false
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			id, err := decodeLegID(e)
+			if err != nil {
+				return err
+			}
+			a.PartyToCharge, hasParty = *id, true
+		case e.Is(ctx, 1):
+			t, _, err := ber.ReadElement(e.Value)
+			if err != nil {
+				return err
+			}
+			switch {
+			case t.Is(ctx, 0):
+				a.TimeIfNoTariffSwitch = decodeInteger(t)
+			case t.Is(ctx, 1):
+				xs, err := ber.ReadElements(t.Value)
+				if err != nil {
+					return err
+				}
+				a.TimeIfTariffSwitch = &TimeIfTariffSwitch{}
+				for _, x := range xs {
+					switch {
+					case x.Is(ctx, 0):
+						a.TimeIfTariffSwitch.TimeSinceTariffSwitch = ber.DecodeInteger(x.Value)
+					case x.Is(ctx, 1):
+						a.TimeIfTariffSwitch.TariffSwitchInterval = decodeInteger(x)
+					}
+				}
+			default:
+				return dec.Unexpected(t)
+			}
+			hasTime = true
+		case e.Is(ctx, 2):
+			a.CallActive = codec.Boolean(e)
+		case e.Is(ctx, 3):
+			a.CallReleasedAtTCPExpiry = true
+		default:
+			a.Extra = append(a.Extra, e.Raw...)
+		}
+	}
+
+	switch {
+	case !hasParty:
+		return dec.Missing("partyToCharge")
+	case !hasTime:
+		return dec.Missing("timeInformation")
+	}
+	return nil
+}
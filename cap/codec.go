@@ -0,0 +1,41 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// Tag classes and the universal tag numbers used in the Parameters.
+const (
+	univ = codec.Universal
+	ctx  = codec.ContextSpecific
+
+	tagOctetString = 4
+	tagEnumerated  = 10
+	tagSequence    = 16
+)
+
+// encoder is the encoder of the Parameters, which is shared with gsmmap package.
+type encoder = codec.Encoder
+
+// dec reads the Parameters, reporting the invalid ones in InvalidParameterError.
+var dec = &codec.Decoder{Invalid: func(reason string) error {
+	return &InvalidParameterError{Reason: reason}
+}}
+
+func decodeInteger(e *ber.Element) *int {
+	n := ber.DecodeInteger(e.Value)
+	return &n
+}
+
+// decode decodes the contents octets of e in v.
+func decode(e *ber.Element, v interface{ Decode([]byte) error }) error {
+	if err := v.Decode(e.Value); err != nil {
+		return dec.Unexpected(e)
+	}
+	return nil
+}
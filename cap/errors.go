@@ -0,0 +1,180 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// Error Code definitions.
+const (
+	Canceled                    = 0
+	CancelFailed                = 1
+	ETCFailed                   = 3
+	ImproperCallerResponse      = 4
+	MissingCustomerRecord       = 6
+	MissingParameter            = 7
+	ParameterOutOfRange         = 8
+	RequestedInfoError          = 10
+	SystemFailure               = 11
+	TaskRefused                 = 12
+	UnavailableResource         = 13
+	UnexpectedComponentSequence = 14
+	UnexpectedDataValue         = 15
+	UnexpectedParameter         = 16
+	UnknownLegID                = 17
+	UnknownPDPID                = 50
+	UnknownCSID                 = 51
+)
+
+var errorNames = map[int]string{
+	Canceled:                    "canceled",
+	CancelFailed:                "cancelFailed",
+	ETCFailed:                   "eTCFailed",
+	ImproperCallerResponse:      "improperCallerResponse",
+	MissingCustomerRecord:       "missingCustomerRecord",
+	MissingParameter:            "missingParameter",
+	ParameterOutOfRange:         "parameterOutOfRange",
+	RequestedInfoError:          "requestedInfoError",
+	SystemFailure:               "systemFailure",
+	TaskRefused:                 "taskRefused",
+	UnavailableResource:         "unavailableResource",
+	UnexpectedComponentSequence: "unexpectedComponentSequence",
+	UnexpectedDataValue:         "unexpectedDataValue",
+	UnexpectedParameter:         "unexpectedParameter",
+	UnknownLegID:                "unknownLegID",
+	UnknownPDPID:                "unknownPDPID",
+	UnknownCSID:                 "unknownCSID",
+}
+
+// ErrorString returns the name of the Error Code, e.g. "missingParameter".
+func ErrorString(code int) string {
+	if name, ok := errorNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", code)
+}
+
+// errorParameters have the constructors of the parameters by the Error Code.
+// The ones not in it do not have the parameter.
+var errorParameters = map[int]func() Parameter{
+	CancelFailed:       func() Parameter { return &CancelFailedParam{} },
+	RequestedInfoError: func() Parameter { return new(ErrorReason) },
+	SystemFailure:      func() Parameter { return new(ErrorReason) },
+	TaskRefused:        func() Parameter { return new(ErrorReason) },
+}
+
+// DecodeError decodes b as the parameter of the error.
+func DecodeError(code int, b []byte) (Parameter, error) {
+	f, ok := errorParameters[code]
+	if !ok {
+		if _, known := errorNames[code]; !known {
+			return nil, &InvalidParameterError{Reason: fmt.Sprintf("unknown error code: %d", code)}
+		}
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("error %s does not have parameter", ErrorString(code))}
+	}
+
+	p := f()
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ErrorReason is the ENUMERATED parameter of requestedInfoError
+// (RequestedInfoErrorParameter), systemFailure (UnavailableNetworkResource)
+// and taskRefused (TaskRefusedParameter).
+type ErrorReason uint8
+
+// RequestedInfoErrorParameter definitions.
+const (
+	UnknownRequestedInfo      ErrorReason = 1
+	RequestedInfoNotAvailable ErrorReason = 2
+)
+
+// UnavailableNetworkResource definitions.
+const (
+	UnavailableResources ErrorReason = iota
+	ComponentFailure
+	BasicCallProcessingException
+	ResourceStatusFailure
+	EndUserFailure
+)
+
+// TaskRefusedParameter definitions.
+const (
+	Generic ErrorReason = iota
+	Unobtainable
+	Congestion
+)
+
+// MarshalBinary returns the byte sequence generated from an ErrorReason.
+func (r ErrorReason) MarshalBinary() ([]byte, error) {
+	return ber.AppendElement(nil, univ, false, tagEnumerated, []byte{uint8(r)}), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an ErrorReason.
+func (r *ErrorReason) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+	if !e.Is(univ, tagEnumerated) {
+		return dec.Unexpected(e)
+	}
+	v, err := dec.Octet(e)
+	if err != nil {
+		return err
+	}
+	*r = ErrorReason(v)
+	return nil
+}
+
+// CancelFailed problem definitions.
+const (
+	UnknownOperation uint8 = iota
+	TooLate
+	OperationNotCancellable
+)
+
+// CancelFailedParam is the parameter of cancelFailed.
+type CancelFailedParam struct {
+	Problem     uint8
+	OperationID int
+	Extra       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a CancelFailedParam.
+func (c *CancelFailedParam) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(univ, tagSequence, func(e *encoder) {
+		e.Octet(ctx, 0, c.Problem)
+		e.Integer(ctx, 1, c.OperationID)
+		e.Raw(c.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CancelFailedParam.
+func (c *CancelFailedParam) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) < 2 || !es[0].Is(ctx, 0) || !es[1].Is(ctx, 1) {
+		return dec.Missing("problem or operationID")
+	}
+
+	*c = CancelFailedParam{}
+	if c.Problem, err = dec.Octet(es[0]); err != nil {
+		return err
+	}
+	c.OperationID = ber.DecodeInteger(es[1].Value)
+	for _, e := range es[2:] {
+		c.Extra = append(c.Extra, e.Raw...)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package cap
+
+import (
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+// timer is the default invocation timer of the operations, which is the upper
+// bound of the operation timers such as T_idp and T_con in 3GPP TS 29.078.
+const timer = 20 * time.Second
+
+// The errors commonly returned by the operations.
+var commonErrors = []int{
+	MissingParameter, ParameterOutOfRange, SystemFailure, TaskRefused,
+	UnexpectedComponentSequence, UnexpectedDataValue, UnexpectedParameter,
+}
+
+// operations are the operations registered in tcap.DefaultRegistry in the
+// Application Contexts of all the phases, as well as the errors.
+var operations = []tcap.Operation{
+	{
+		Name: "initialDP", Code: InitialDP, Class: tcap.Class2, Timer: timer,
+		Errors: append([]int{MissingCustomerRecord}, commonErrors...),
+	}, {
+		Name: "connect", Code: Connect, Class: tcap.Class2, Timer: timer,
+		Errors: append([]int{UnknownLegID}, commonErrors...),
+	}, {
+		Name: "releaseCall", Code: ReleaseCall, Class: tcap.Class4, Timer: timer,
+	}, {
+		Name: "requestReportBCSMEvent", Code: RequestReportBCSMEvent, Class: tcap.Class2, Timer: timer,
+		Errors: append([]int{UnknownLegID}, commonErrors...),
+	}, {
+		Name: "eventReportBCSM", Code: EventReportBCSM, Class: tcap.Class4, Timer: timer,
+	}, {
+		Name: "continue", Code: Continue, Class: tcap.Class4, Timer: timer,
+	}, {
+		Name: "applyCharging", Code: ApplyCharging, Class: tcap.Class2, Timer: timer,
+		Errors: append([]int{UnknownLegID}, commonErrors...),
+	}, {
+		Name: "applyChargingReport", Code: ApplyChargingReport, Class: tcap.Class2, Timer: timer,
+		Errors: commonErrors,
+	},
+}
+
+func init() {
+	for i := range operations {
+		op := &operations[i]
+		op.NewArgument = arguments[op.Code]
+		for phase := 2; phase <= 4; phase++ {
+			tcap.RegisterOperation(ApplicationContext(phase), op)
+		}
+	}
+
+	for code, name := range errorNames {
+		e := &tcap.Error{Name: name, Code: code, NewParameter: errorParameters[code]}
+		for phase := 2; phase <= 4; phase++ {
+			tcap.RegisterError(ApplicationContext(phase), e)
+		}
+	}
+}
+
+// ApplicationContext returns the CAP Application Context Name of the gsmSSF to
+// gsmSCF interface in the phase in the dotted notation, which is used in
+// tcap.Registry, or the empty string if the phase is not supported.
+func ApplicationContext(phase int) string {
+	switch phase {
+	case 2:
+		return "0.4.0.0.1.0.50.1"
+	case 3:
+		return "0.4.0.0.1.21.3.4"
+	case 4:
+		return "0.4.0.0.1.22.3.4"
+	}
+	return ""
+}
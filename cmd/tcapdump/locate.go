@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+// locationError is an error with the position of the malformed element.
+type locationError struct {
+	// Offset is the offset of the element from the beginning of the message.
+	Offset int
+	// Path is the tags of the enclosing elements and the element, outermost first.
+	Path []string
+	Err  error
+}
+
+func (e *locationError) Error() string {
+	return fmt.Sprintf("at offset %d (0x%x) in %s: %v", e.Offset, e.Offset, strings.Join(e.Path, "/"), e.Err)
+}
+
+func (e *locationError) Unwrap() error {
+	return e.Err
+}
+
+var errIndefiniteLength = errors.New("indefinite length is not supported")
+
+// locatePortion looks for the portion or component that made the parsers in
+// tcap package fail with err by parsing them one by one, and returns err with
+// the position of it. b must have passed walk.
+func locatePortion(b []byte, err error) error {
+	for offset := 0; offset < len(b); {
+		tag, _, hdr, l, _ := readHeader(b[offset:])
+		msg := b[offset : offset+hdr+l]
+		path := []string{tag}
+		if _, perr := tcap.ParseTransaction(msg); perr != nil {
+			return &locationError{offset, path, perr}
+		}
+
+		for o := hdr; o < len(msg); {
+			tag, _, chdr, cl, _ := readHeader(msg[o:])
+			child := msg[o : o+chdr+cl]
+			switch tag {
+			case "6b":
+				if _, perr := tcap.ParseDialogue(child); perr != nil {
+					return &locationError{offset + o, append(path, tag), perr}
+				}
+			case "6c":
+				_, perr := tcap.ParseComponents(child)
+				if perr == nil {
+					break
+				}
+				for co := chdr; co < len(child); {
+					ctag, _, comphdr, compl, _ := readHeader(child[co:])
+					comp := child[co : co+comphdr+compl]
+					if _, perr := tcap.ParseComponent(comp); perr != nil {
+						return &locationError{offset + o + co, append(path, tag, ctag), perr}
+					}
+					co += comphdr + compl
+				}
+				return &locationError{offset + o, append(path, tag), perr}
+			}
+			o += chdr + cl
+		}
+		offset += hdr + l
+	}
+	return err
+}
+
+// walk checks the TLVs in b and the ones in the constructed elements recursively,
+// and returns the error with the position of the first malformed one.
+func walk(b []byte, offset int, path []string) error {
+	for len(b) != 0 {
+		tag, constructed, hdr, l, err := readHeader(b)
+		elemPath := append(path[:len(path):len(path)], tag)
+		if err != nil {
+			return &locationError{offset, elemPath, err}
+		}
+		if len(b) < hdr+l {
+			return &locationError{offset, elemPath, fmt.Errorf(
+				"length %d exceeds the remaining %d byte(s): %w", l, len(b)-hdr, io.ErrUnexpectedEOF,
+			)}
+		}
+
+		if constructed {
+			if err := walk(b[hdr:hdr+l], offset+hdr, elemPath); err != nil {
+				return err
+			}
+		}
+		b, offset = b[hdr+l:], offset+hdr+l
+	}
+	return nil
+}
+
+// readHeader reads the identifier and length octets, and returns the tag in
+// hex, whether it is constructed, the length of the octets and the value.
+func readHeader(b []byte) (tag string, constructed bool, hdr, l int, err error) {
+	if len(b) < 2 {
+		return fmt.Sprintf("%x", b), false, 0, 0, io.ErrUnexpectedEOF
+	}
+
+	constructed = b[0]&0x20 != 0
+	n := 1
+	if b[0]&0x1f == 0x1f {
+		// The tag number in the subsequent octets.
+		for ; n < len(b) && b[n]&0x80 != 0; n++ {
+		}
+		if n++; n >= len(b) {
+			return fmt.Sprintf("%x", b), constructed, 0, 0, io.ErrUnexpectedEOF
+		}
+	}
+	tag = fmt.Sprintf("%x", b[:n])
+
+	l = int(b[n])
+	n++
+	switch {
+	case l == 0x80:
+		return tag, constructed, 0, 0, errIndefiniteLength
+	case l&0x80 != 0:
+		count := l & 0x7f
+		if count > 4 {
+			return tag, constructed, 0, 0, fmt.Errorf("length in %d octets is too long", count)
+		}
+		if len(b) < n+count {
+			return tag, constructed, 0, 0, io.ErrUnexpectedEOF
+		}
+		l = 0
+		for _, x := range b[n : n+count] {
+			l = l<<8 | int(x)
+		}
+		n += count
+	}
+	return tag, constructed, n, l, nil
+}
@@ -0,0 +1,300 @@
+// Command tcapdump decodes TCAP messages and prints them in a human readable form.
+//
+// The messages are read from the arguments, the files given with -f, or stdin,
+// in hex, base64 or raw binary. In hex and base64, each non-empty line is a
+// message and the lines starting with "#" are ignored.
+//
+//	tcapdump 62434804000000026b1e...
+//	tcapdump -o json -f capture.txt
+//	tcapdump -in bin -o summary < message.bin
+//
+// It exits with status 1 if any message cannot be decoded, printing where in
+// the byte sequence the decoding failed.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	// The operations are registered to print the names.
+	_ "github.com/danievanzyl/go-ya-tcap/cap"
+	_ "github.com/danievanzyl/go-ya-tcap/gsmmap"
+)
+
+func main() {
+	var (
+		in     = flag.String("in", "auto", "Input format: auto, hex, base64 or bin.")
+		out    = flag.String("o", "tree", "Output format: tree, json or summary.")
+		files  = flag.Bool("f", false, "Read the arguments as file names instead of messages (\"-\" for stdin).")
+		useBER = flag.Bool("ber", false, "Decode with ParseBER, which accepts multiple messages in one input.")
+		strict = flag.Bool("strict", false, "Validate the structure of the messages against Q.773.")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [message|file ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("tcapdump: ")
+	tcap.DisableLogging()
+
+	d := &dumper{w: os.Stdout, out: *out, ber: *useBER}
+	if *strict {
+		d.opts = append(d.opts, tcap.WithValidation())
+	}
+
+	inputs, err := readInputs(flag.Args(), *files, *in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	failed := false
+	for _, input := range inputs {
+		if err := d.dump(input.b); err != nil {
+			log.Printf("%s: %v", input.name, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// input is a message to decode with the name to identify it in the errors.
+type input struct {
+	name string
+	b    []byte
+}
+
+func readInputs(args []string, files bool, format string) ([]input, error) {
+	switch {
+	case len(args) == 0:
+		return readFile("stdin", os.Stdin, format)
+	case !files:
+		var inputs []input
+		for i, arg := range args {
+			name := fmt.Sprintf("argument %d", i+1)
+			b, err := decodeText(arg, format)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			inputs = append(inputs, input{name, b})
+		}
+		return inputs, nil
+	}
+
+	var inputs []input
+	for _, path := range args {
+		var r io.Reader = os.Stdin
+		name := "stdin"
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r, name = f, path
+		}
+
+		in, err := readFile(name, r, format)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in...)
+	}
+	return inputs, nil
+}
+
+// readFile reads the messages from r, which is one message in binary or one
+// message per line in hex or base64.
+func readFile(name string, r io.Reader, format string) ([]input, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "auto" && !isText(b) {
+		format = "bin"
+	}
+	if format == "bin" {
+		return []input{{name, b}}, nil
+	}
+
+	var inputs []input
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lname := fmt.Sprintf("%s:%d", name, n)
+		m, err := decodeText(line, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", lname, err)
+		}
+		inputs = append(inputs, input{lname, m})
+	}
+	return inputs, s.Err()
+}
+
+// decodeText decodes a message in hex or base64. With "auto", hex is tried first
+// as the hex digits are also valid in base64.
+func decodeText(s, format string) ([]byte, error) {
+	switch format {
+	case "hex":
+		return decodeHex(s)
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+	case "auto":
+		if b, err := decodeHex(s); err == nil {
+			return b, nil
+		}
+		if b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), "")); err == nil {
+			return b, nil
+		}
+		return nil, errors.New("neither hex nor base64")
+	case "bin":
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// decodeHex decodes hex digits, which can have the "0x" prefix and be separated
+// by spaces or colons as copied from Wireshark.
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	s = strings.NewReplacer(" ", "", "\t", "", ":", "").Replace(s)
+	return hex.DecodeString(s)
+}
+
+// isText reports whether b looks like hex or base64 rather than raw binary.
+func isText(b []byte) bool {
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case strings.IndexByte("+/=:# \t\r\n", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// dumper decodes the messages and writes them in the output format.
+type dumper struct {
+	w    io.Writer
+	out  string
+	ber  bool
+	opts []tcap.ParseOption
+
+	// acns is the Application Context Names by the Transaction IDs, to name
+	// the operations and the errors in the messages without dialogue portion.
+	acns map[string]string
+}
+
+func (d *dumper) dump(b []byte) error {
+	// Check the BER structure first to tell where it is broken, which the
+	// parsers in tcap package do not.
+	if err := walk(b, 0, nil); err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return errors.New("empty input")
+	}
+	if _, _, hdr, l, _ := readHeader(b); !d.ber && hdr+l < len(b) {
+		return fmt.Errorf("at offset %d (0x%x): %d byte(s) after the message (use -ber to decode multiple messages)",
+			hdr+l, hdr+l, len(b)-hdr-l,
+		)
+	}
+
+	tcaps, err := d.parse(b)
+	if err != nil {
+		return err
+	}
+	for _, t := range tcaps {
+		if err := d.write(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *dumper) parse(b []byte) ([]*tcap.TCAP, error) {
+	if d.ber {
+		tcaps, err := tcap.ParseBER(b, d.opts...)
+		if err != nil {
+			return nil, locatePortion(b, err)
+		}
+		return tcaps, nil
+	}
+
+	t, err := tcap.Parse(b, d.opts...)
+	if err != nil {
+		return nil, locatePortion(b, err)
+	}
+	return []*tcap.TCAP{t}, nil
+}
+
+func (d *dumper) write(t *tcap.TCAP) error {
+	switch d.out {
+	case "tree":
+		_, err := fmt.Fprintf(d.w, "%+v\n", t)
+		return err
+	case "json":
+		j, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(d.w, "%s\n", j)
+		return err
+	case "summary":
+		_, err := fmt.Fprintln(d.w, summary(t, d.applicationContext(t)))
+		return err
+	}
+	return fmt.Errorf("unknown output format %q", d.out)
+}
+
+// applicationContext returns the Application Context Name of the dialogue of
+// t, which is the one in the dialogue portion of t or the earlier message with
+// the same Transaction ID.
+func (d *dumper) applicationContext(t *tcap.TCAP) string {
+	acn := messageACN(t)
+	tx := t.Transaction
+	if tx == nil {
+		return acn
+	}
+
+	otid, dtid := tx.OTID(), tx.DTID()
+	if acn != "" {
+		if d.acns == nil {
+			d.acns = map[string]string{}
+		}
+		for _, id := range []string{otid, dtid} {
+			if id != "" {
+				d.acns[id] = acn
+			}
+		}
+		return acn
+	}
+	if acn, ok := d.acns[dtid]; ok && dtid != "" {
+		return acn
+	}
+	if otid != "" {
+		return d.acns[otid]
+	}
+	return ""
+}
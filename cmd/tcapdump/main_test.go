@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// Begin with AARQ of networkUnstructuredSsContext-v2 and Invoke of processUnstructuredSS-Request.
+const begin = "6281f248040000000a6b3f283d060700118605010101a032603080020780a109060704000001001302be1f281d060704000001010101a012a01080069121436587f981069121436587f96c81a8a181a502010002013b30819c04010f04818c5474d8bd06e5df7590f92d07d5e769f71944479741c7373bec3e83aad32911342fcbed65b90b94a683d27310b96c2fb3dff0321904afcbcbec3ce8ed069ddfecb0fb0c0abbc9a0341d549f97e7a0e9700865819ab36a90059a0ea95016283875b24043e194053a4e9b3750d84d0625a955d09c1e7693c372f2dc0542bee16550fe5d0795ddea771e4447bbf1800891111111111111f1"
+
+func TestReadFile(t *testing.T) {
+	b, _ := hex.DecodeString("64084904000000016c00")
+
+	for _, c := range []struct {
+		name, format string
+		in           []byte
+	}{
+		{"hex", "auto", []byte("# comment\n\n64084904000000016c00\n64 08 49 04 00 00 00 01 6c 00\n")},
+		{"hex with colons", "hex", []byte("0x64:08:49:04:00:00:00:01:6c:00\n64084904000000016c00")},
+		{"base64", "auto", []byte("ZAhJBAAAAAFsAA==\nZAhJBAAAAAFsAA==\n")},
+		{"base64 without auto", "base64", []byte("ZAhJBAAAAAFsAA==\nZAhJBAAAAAFsAA==")},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			inputs, err := readFile("test", bytes.NewReader(c.in), c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inputs) != 2 {
+				t.Fatalf("got %d inputs, want 2", len(inputs))
+			}
+			for _, in := range inputs {
+				if !bytes.Equal(in.b, b) {
+					t.Errorf("%s: got %x, want %x", in.name, in.b, b)
+				}
+			}
+		})
+	}
+
+	inputs, err := readFile("test", bytes.NewReader(b), "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 1 || !bytes.Equal(inputs[0].b, b) {
+		t.Errorf("got %v, want binary %x", inputs, b)
+	}
+
+	if _, err := readFile("test", strings.NewReader("6408\nzz\n"), "hex"); err == nil || !strings.HasPrefix(err.Error(), "test:2: ") {
+		t.Errorf("got %v, want error at test:2", err)
+	}
+}
+
+func TestDump(t *testing.T) {
+	b, _ := hex.DecodeString(begin)
+
+	for _, c := range []struct {
+		out, want string
+	}{
+		{"summary", "Begin otid=0000000a acn=0.4.0.0.1.0.19.2(networkUnstructuredSsContext-v2) invoke(id=0,op=59(processUnstructuredSS-Request))\n"},
+		{"json", `{"transaction":{"type":"Begin","otid":"0000000a"},`},
+		{"tree", "Transaction Capabilities Application Part\n    begin\n        Source Transaction ID\n            otid: 0000000a\n"},
+	} {
+		t.Run(c.out, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			d := &dumper{w: w, out: c.out}
+			if err := d.dump(b); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(w.String(), c.want) {
+				t.Errorf("got %s, want %s...", w, c.want)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, c := range []struct {
+		hex, want string
+	}{
+		{
+			"6516480400000001490400000002" + "6c08a306020101020101",
+			"Continue otid=00000001 dtid=00000002 returnError(id=1,err=1)",
+		}, {
+			"64384904000000026b262824060700118605010101a0196117a109060704000001001403a203020100a305a103020100" + "6c08a306020101020101",
+			"End dtid=00000002 acn=0.4.0.0.1.0.20.3(shortMsgGatewayContext-v3) returnError(id=1,err=1(unknownSubscriber))",
+		}, {
+			"640f490400000002" + "6c07a40505008201" + "01",
+			"End dtid=00000002 reject(id=NULL,problem=2:1)",
+		}, {
+			"670949040000000b4a0104",
+			"Abort dtid=0000000b cause=ResourceLimitation",
+		},
+	} {
+		b, _ := hex.DecodeString(c.hex)
+		w := &bytes.Buffer{}
+		d := &dumper{w: w, out: "summary"}
+		if err := d.dump(b); err != nil {
+			t.Errorf("%s: %v", c.hex, err)
+			continue
+		}
+		if got := strings.TrimSuffix(w.String(), "\n"); got != c.want {
+			t.Errorf("got %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestSummaryDialogue(t *testing.T) {
+	w := &bytes.Buffer{}
+	d := &dumper{w: w, out: "summary"}
+	for _, s := range []string{
+		"62354804000000026b1e281c060700118605010001a011600f80020780a1090607040000010014036c0da10b02010102012d3003800105",
+		// The errors are named in the Application Context of the Begin.
+		"64104904000000026c08a306020101020101",
+		// The Transaction ID not seen before.
+		"64104904000000036c08a306020101020101",
+	} {
+		b, _ := hex.DecodeString(s)
+		if err := d.dump(b); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+
+	want := "Begin otid=00000002 acn=0.4.0.0.1.0.20.3(shortMsgGatewayContext-v3) invoke(id=1,op=45(sendRoutingInfoForSM))\n" +
+		"End dtid=00000002 returnError(id=1,err=1(unknownSubscriber))\n" +
+		"End dtid=00000003 returnError(id=1,err=1)\n"
+	if got := w.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestDumpError(t *testing.T) {
+	for _, c := range []struct {
+		name, hex, want string
+	}{
+		{
+			"truncated message",
+			"6404490101",
+			"at offset 0 (0x0) in 64: length 4 exceeds the remaining 3 byte(s): unexpected EOF",
+		}, {
+			"truncated transaction ID",
+			"620449040000",
+			"at offset 2 (0x2) in 62/49: length 4 exceeds the remaining 2 byte(s): unexpected EOF",
+		}, {
+			"indefinite length",
+			"62804804000000010000",
+			"at offset 0 (0x0) in 62: indefinite length is not supported",
+		}, {
+			"malformed component",
+			"620a4804000000016c02a100",
+			"at offset 10 (0xa) in 62/6c/a1: unexpected EOF",
+		}, {
+			"empty component portion",
+			"64084904000000016c00",
+			"at offset 8 (0x8) in 64/6c: unexpected EOF",
+		}, {
+			"trailing bytes",
+			"64064904000000016c00",
+			"at offset 8 (0x8): 2 byte(s) after the message (use -ber to decode multiple messages)",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(c.hex)
+			d := &dumper{w: ioutil.Discard, out: "summary"}
+			err := d.dump(b)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != c.want {
+				t.Errorf("got %q, want %q", err, c.want)
+			}
+		})
+	}
+
+	b, _ := hex.DecodeString("6404490101")
+	err := (&dumper{w: ioutil.Discard, out: "tree"}).dump(b)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// summary returns a one-line summary of t, with the names of the operations and
+// the errors in the Application Context Name acn, e.g.:
+//
+//	Begin otid=00000002 acn=0.4.0.0.1.0.19.2(networkUnstructuredSsContext-v2) invoke(id=0,op=59(processUnstructuredSS-Request))
+func summary(t *tcap.TCAP, acn string) string {
+	var fields []string
+	if tx := t.Transaction; tx != nil {
+		fields = append(fields, tx.MessageTypeString())
+		if otid := tx.OTID(); otid != "" {
+			fields = append(fields, "otid="+otid)
+		}
+		if dtid := tx.DTID(); dtid != "" {
+			fields = append(fields, "dtid="+dtid)
+		}
+		if tx.Type.Code() == tcap.Abort && tx.PAbortCause != nil {
+			fields = append(fields, "cause="+tx.AbortCause())
+		}
+	}
+
+	if d := t.Dialogue; d != nil && d.DialoguePDU != nil {
+		if acn := d.DialoguePDU.ApplicationContextName; acn != nil {
+			fields = append(fields, "acn="+applicationContext(t, acn.Value))
+		}
+	}
+
+	if c := t.Components; c != nil {
+		for _, comp := range c.Component {
+			fields = append(fields, component(comp, acn))
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// applicationContext returns the ACN in dotted notation, with the name if known.
+func applicationContext(t *tcap.TCAP, b []byte) string {
+	_, v, _, err := ber.ReadTLV(b)
+	if err != nil {
+		return fmt.Sprintf("%x", b)
+	}
+	oid, err := ber.FormatOID(v)
+	if err != nil {
+		return fmt.Sprintf("%x", v)
+	}
+
+	// The ones under {itu-t identified-organization etsi mobileDomain
+	// gsm-Network ac-Id} have the names.
+	if len(v) == 7 && bytes.HasPrefix(v, []byte{0x04, 0x00, 0x00, 0x01, 0x00}) && t.AppContextName() != "" {
+		return oid + "(" + t.AppContextNameWithVersion() + ")"
+	}
+	return oid
+}
+
+// messageACN returns the Application Context Name in the dialogue portion of t
+// in dotted notation, or the empty string if not present.
+func messageACN(t *tcap.TCAP) string {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil || d.DialoguePDU.ApplicationContextName == nil {
+		return ""
+	}
+	_, v, _, err := ber.ReadTLV(d.DialoguePDU.ApplicationContextName.Value)
+	if err != nil {
+		return ""
+	}
+	oid, err := ber.FormatOID(v)
+	if err != nil {
+		return ""
+	}
+	return oid
+}
+
+// component returns the type of c with Invoke ID and Operation or Error Code,
+// with the name of the operation or the error if registered in acn.
+func component(c *tcap.Component, acn string) string {
+	var attrs []string
+	if id := c.InvokeID; id != nil {
+		if id.Tag == tcap.NewUniversalPrimitiveTag(5) {
+			attrs = append(attrs, "id=NULL")
+		} else {
+			attrs = append(attrs, fmt.Sprintf("id=%d", ber.DecodeInteger(id.Value)))
+		}
+	}
+	if op := c.OperationCode; op != nil {
+		s := "op=" + code(op)
+		if op.Tag == tcap.NewUniversalPrimitiveTag(2) {
+			if o := tcap.LookupOperation(acn, ber.DecodeInteger(op.Value)); o != nil {
+				s += "(" + o.Name + ")"
+			}
+		}
+		attrs = append(attrs, s)
+	}
+	if e := c.ErrorCode; e != nil {
+		s := "err=" + code(e)
+		if name := c.ErrorName(acn); name != "" {
+			s += "(" + name + ")"
+		}
+		attrs = append(attrs, s)
+	}
+	if p := c.ProblemCode; p != nil {
+		attrs = append(attrs, fmt.Sprintf("problem=%d:%d", p.Tag.Code(), ber.DecodeInteger(p.Value)))
+	}
+
+	name := c.ComponentTypeString()
+	if name == "" {
+		name = fmt.Sprintf("unknown(0x%02x)", uint8(c.Type))
+	}
+	return name + "(" + strings.Join(attrs, ",") + ")"
+}
+
+// code returns the local value in decimal or the global value in dotted notation.
+func code(ie *tcap.IE) string {
+	if ie.Tag == tcap.NewUniversalPrimitiveTag(6) {
+		if oid, err := ber.FormatOID(ie.Value); err == nil {
+			return oid
+		}
+		return fmt.Sprintf("%x", ie.Value)
+	}
+	return fmt.Sprint(ber.DecodeInteger(ie.Value))
+}
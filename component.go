@@ -84,6 +84,13 @@ type Component struct {
 	ErrorCode     *IE
 	ProblemCode   *IE
 	Parameter     *IE
+	// Unknown is the elements not known in the Component, e.g. the ones after
+	// Parameter, which are kept as they are and put where they were parsed.
+	Unknown   []*IE
+	unknownAt unknownAt
+
+	raw  []byte
+	orig *original
 }
 
 // NewComponents creates a new Components.
@@ -117,7 +124,6 @@ func NewInvoke(invID, lkID, opCode int, isLocal bool, param []byte) *Component {
 		}
 	}
 
-	fmt.Println("NewInvoke:param", param)
 	if param != nil {
 		if err := c.setParameterFromBytesWithTag(param); err != nil {
 			logf("failed to build Parameter: %v", err)
@@ -129,6 +135,9 @@ func NewInvoke(invID, lkID, opCode int, isLocal bool, param []byte) *Component {
 }
 
 // NewReturnResult returns a new single ReturnResultLast or ReturnResultNotLast Component.
+//
+// The result sequence is omitted if param is empty, as the Parameter is mandatory
+// in it, in which case the Component has only the Invoke ID and opCode is ignored.
 func NewReturnResult(invID, opCode int, isLocal, isLast bool, param []byte) *Component {
 	tag := ReturnResultNotLast
 	if isLast {
@@ -137,21 +146,23 @@ func NewReturnResult(invID, opCode int, isLocal, isLast bool, param []byte) *Com
 
 	c := &Component{
 		Type: NewContextSpecificConstructorTag(tag),
-		ResultRetres: &IE{
-			Tag: NewUniversalConstructorTag(0x10),
-		},
 		InvokeID: &IE{
 			Tag:    NewUniversalPrimitiveTag(2),
 			Length: 1,
 			Value:  []byte{uint8(invID)},
 		},
-		OperationCode: NewOperationCode(opCode, isLocal),
+	}
+	if len(param) == 0 {
+		c.SetLength()
+		return c
 	}
 
-	if param != nil {
-		if err := c.setParameterFromBytesWithTag(param); err != nil {
-			logf("failed to build Parameter: %v", err)
-		}
+	c.ResultRetres = &IE{
+		Tag: NewUniversalConstructorTag(0x10),
+	}
+	c.OperationCode = NewOperationCode(opCode, isLocal)
+	if err := c.setParameterFromBytesWithTag(param); err != nil {
+		logf("failed to build Parameter: %v", err)
 	}
 
 	c.SetLength()
@@ -184,7 +195,7 @@ func NewReturnError(invID, errCode int, isLocal bool, param []byte) *Component {
 // NewReject returns a new single Reject Component.
 func NewReject(invID, problemType int, problemCode uint8, param []byte) *Component {
 	c := &Component{
-		Type: NewContextSpecificConstructorTag(Invoke),
+		Type: NewContextSpecificConstructorTag(Reject),
 		InvokeID: &IE{
 			Tag:    NewUniversalPrimitiveTag(2),
 			Length: 1,
@@ -227,115 +238,82 @@ func NewErrorCode(code int, isLocal bool) *IE {
 
 // MarshalBinary returns the byte sequence generated from a Components instance.
 func (c *Components) MarshalBinary() ([]byte, error) {
-	b := make([]byte, c.MarshalLen())
-	if err := c.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return c.AppendBinary(make([]byte, 0, c.MarshalLen()))
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (c *Components) MarshalTo(b []byte) error {
-	b[0] = uint8(c.Tag)
-	b[1] = c.Length
+	return marshalTo(b, c.AppendBinary)
+}
+
+// AppendBinary appends the byte sequence generated from a Components instance to b.
+//
+// The length is computed from the contents, not taken from the Length field.
+func (c *Components) AppendBinary(b []byte) ([]byte, error) {
+	b, start := beginTLV(b, c.Tag)
 
-	fmt.Printf("component: %x %d\n", b[0], b[1])
-	cursor := 2
+	var err error
 	for _, comp := range c.Component {
-		compLen := comp.MarshalLen()
-		if err := comp.MarshalTo(b[cursor : cursor+compLen]); err != nil {
-			return err
+		if b, err = comp.AppendBinary(b); err != nil {
+			return nil, err
 		}
-		cursor += compLen
 	}
-	return nil
+	return endTLV(b, start), nil
 }
 
 // MarshalBinary returns the byte sequence generated from a Components instance.
 func (c *Component) MarshalBinary() ([]byte, error) {
-	b := make([]byte, c.MarshalLen())
-	if err := c.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return c.AppendBinary(make([]byte, 0, c.MarshalLen()))
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (c *Component) MarshalTo(b []byte) error {
-	b[0] = uint8(c.Type)
-	b[1] = c.Length
+	return marshalTo(b, c.AppendBinary)
+}
 
-	offset := 2
-	if field := c.InvokeID; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
+// AppendBinary appends the byte sequence generated from a Component instance to b.
+//
+// The length is computed from the contents, not taken from the Length field.
+// In ReturnResult, the OperationCode and Parameter are put in the sequence
+// given as ResultRetres, and the Value of ResultRetres itself is ignored.
+func (c *Component) AppendBinary(b []byte) ([]byte, error) {
+	component := len(b)
+	b, start := beginTLV(b, c.Type)
+
+	w := newUnknownWriter(c.Unknown, c.unknownAt)
+	b = w.appendAfter(b, nil)
+	b, err := w.appendFields(b, c.InvokeID)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("component:type", c.Type.Code())
 	switch c.Type.Code() {
 	case Invoke:
-		if field := c.LinkedID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := c.OperationCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
+		b, err = w.appendFields(b, c.LinkedID, c.OperationCode, c.Parameter)
 	case ReturnResultLast, ReturnResultNotLast:
 		if field := c.ResultRetres; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := c.OperationCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
+			var seq int
+			b, seq = beginTLV(b, field.Tag)
+			if b, err = w.appendFields(b, c.OperationCode, c.Parameter); err != nil {
+				return nil, err
 			}
+			b = endTLV(b, seq)
+			b = w.appendAfter(b, field)
+			break
 		}
+		b, err = w.appendFields(b, c.OperationCode, c.Parameter)
 	case ReturnError:
-		if field := c.ErrorCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := c.Parameter; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
+		b, err = w.appendFields(b, c.ErrorCode, c.Parameter)
 	case Reject:
-		if field := c.ProblemCode; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-		}
+		b, err = w.appendFields(b, c.ProblemCode)
 	}
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	b = w.appendRest(b)
+
+	b = endTLV(b, start)
+	return c.orig.replace(b, component), nil
 }
 
 // ParseComponents parses given byte sequence as an Components.
@@ -353,37 +331,29 @@ func (c *Components) UnmarshalBinary(b []byte) error {
 		return io.ErrUnexpectedEOF
 	}
 
-	c.Tag = Tag(b[0])
-	u, _ := readLength(b)
-	c.Length = u
-
-	offset := 2
+	tag, v, _, err := readTLV(b)
+	if err != nil {
+		return err
+	}
+	c.Tag = tag
+	c.Length = uint8(len(v))
+	c.Component = nil
 
-	if c.Length > 127 {
-		offset = 3
+	// Component Portion has at least one Component.
+	if len(v) == 0 {
+		return io.ErrUnexpectedEOF
 	}
-	for {
-		if len(b) < 2 {
-			break
+	for len(v) != 0 {
+		_, _, n, err := readTLV(v)
+		if err != nil {
+			return err
 		}
-
-		comp, err := ParseComponent(b[offset:])
+		comp, err := ParseComponent(v[:n])
 		if err != nil {
 			return err
 		}
 		c.Component = append(c.Component, comp)
-
-		if c.Length > 127 {
-			if len(b[offset:]) == int(comp.Length)+3 {
-				break
-			}
-			b = b[offset+comp.MarshalLen()-3:]
-		} else {
-			if len(b[offset:]) == int(comp.Length)+2 {
-				break
-			}
-			b = b[offset+comp.MarshalLen()-2:]
-		}
+		v = v[n:]
 	}
 	return nil
 }
@@ -398,87 +368,134 @@ func ParseComponent(b []byte) (*Component, error) {
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an Component.
+//
+// The elements that the type of Component does not have, e.g. the ones after
+// Parameter, are kept in Unknown.
 func (c *Component) UnmarshalBinary(b []byte) error {
 	if len(b) < 2 {
 		return io.ErrUnexpectedEOF
 	}
-	c.Type = Tag(b[0])
-	u, _ := readLength(b)
-	c.Length = u
-
-	var err error
-	offset := 2
-	if c.Length > 127 {
-		offset = 3
-	}
-	c.InvokeID, err = ParseIE(b[offset:])
+	tag, v, n, err := readTLV(b)
 	if err != nil {
 		return err
 	}
-	offset += c.InvokeID.MarshalLen()
+	c.Type = tag
+	c.Length = uint8(len(v))
+	c.Unknown, c.unknownAt = nil, nil
+	c.raw, c.orig = b[:n], nil
+
+	r := &componentReader{c: c, b: v}
+	if c.InvokeID, err = r.next(); err != nil {
+		return err
+	}
 
 	switch c.Type.Code() {
 	case Invoke:
-		/* TODO: Implement LinkedID Parser.
-		   c.LinkedID, err = ParseIE(b[offset:])
-		   if err != nil {
-		       return err
-		   }
-		   offset += c.LinkedID.MarshalLen()
-		*/
-		c.OperationCode, err = ParseIE(b[offset:])
-		if err != nil {
-			return err
+		if r.peek(NewContextSpecificPrimitiveTag(0)) {
+			if c.LinkedID, err = r.next(); err != nil {
+				return err
+			}
 		}
-		offset += c.OperationCode.MarshalLen()
-
-		if offset >= len(b) {
-			return nil
+		if c.OperationCode, err = r.next(); err != nil {
+			return err
 		}
-		c.Parameter, err = ParseIERecursive(b[offset:])
-		if err != nil {
+		if c.Parameter, err = r.parameter(); err != nil {
 			return err
 		}
 	case ReturnResultLast, ReturnResultNotLast:
-		c.ResultRetres, err = ParseIE(b[offset:])
-		if err != nil {
-			return err
+		// The result sequence is optional, e.g. the acknowledgement of the
+		// operations without result has only the Invoke ID.
+		if !r.peek(NewUniversalConstructorTag(0x10)) {
+			break
 		}
-		offset = 0
-		b = c.ResultRetres.Value[offset:]
-
-		c.OperationCode, err = ParseIE(b[offset:])
-		if err != nil {
+		if c.ResultRetres, err = r.next(); err != nil {
 			return err
 		}
-		offset += c.OperationCode.MarshalLen()
 
-		if offset >= len(b) {
-			return nil
+		seq := &componentReader{c: c, b: c.ResultRetres.Value, prev: c.ResultRetres}
+		if len(seq.b) != 0 {
+			if c.OperationCode, err = seq.next(); err != nil {
+				return err
+			}
 		}
-		c.Parameter, err = ParseIERecursive(b[offset:])
-		if err != nil {
+		if c.Parameter, err = seq.parameter(); err != nil {
+			return err
+		}
+		if err := seq.rest(); err != nil {
 			return err
 		}
 	case ReturnError:
-		c.ErrorCode, err = ParseIE(b[offset:])
-		if err != nil {
+		if c.ErrorCode, err = r.next(); err != nil {
 			return err
 		}
-		offset += c.ErrorCode.MarshalLen()
-
-		if offset >= len(b) {
-			return nil
+		if c.Parameter, err = r.parameter(); err != nil {
+			return err
 		}
-		c.Parameter, err = ParseIERecursive(b[offset:])
+	case Reject:
+		if c.ProblemCode, err = r.next(); err != nil {
+			return err
+		}
+	}
+	return r.rest()
+}
+
+// componentReader reads the elements in the contents of a Component in order.
+type componentReader struct {
+	c    *Component
+	b    []byte
+	prev *IE
+}
+
+// next reads the next element as a mandatory field.
+func (r *componentReader) next() (*IE, error) {
+	_, _, n, err := readTLV(r.b)
+	if err != nil {
+		return nil, err
+	}
+	ie, err := ParseIE(r.b[:n])
+	if err != nil {
+		return nil, err
+	}
+	r.b, r.prev = r.b[n:], ie
+	return ie, nil
+}
+
+// peek reports whether the next element has the tag.
+func (r *componentReader) peek(tag Tag) bool {
+	return len(r.b) != 0 && Tag(r.b[0]) == tag
+}
+
+// parameter reads the next element as the optional Parameter, parsing the IEs
+// in it recursively.
+func (r *componentReader) parameter() (*IE, error) {
+	if len(r.b) == 0 {
+		return nil, nil
+	}
+	_, _, n, err := readTLV(r.b)
+	if err != nil {
+		return nil, err
+	}
+	ie, err := ParseIERecursive(r.b[:n])
+	if err != nil {
+		return nil, err
+	}
+	r.b, r.prev = r.b[n:], ie
+	return ie, nil
+}
+
+// rest reads the remaining elements as Unknown.
+func (r *componentReader) rest() error {
+	for len(r.b) != 0 {
+		_, _, n, err := readTLV(r.b)
 		if err != nil {
 			return err
 		}
-	case Reject:
-		c.ProblemCode, err = ParseIE(b[offset:])
+		ie, err := ParseIE(r.b[:n])
 		if err != nil {
 			return err
 		}
+		r.c.Unknown = r.c.unknownAt.add(r.c.Unknown, ie, r.prev)
+		r.b = r.b[n:]
 	}
 	return nil
 }
@@ -518,10 +535,11 @@ func (c *Component) setParameterFromBytesWithTag(b []byte) error {
 		return io.ErrUnexpectedEOF
 	}
 
-	tag := Tag(b[0])
-	b = b[2:]
+	tag, b, _, err := readTLV(b)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("setParameterFromBytesWithTag", tag)
 	ies, err := ParseMultiIEs(b)
 	if err != nil {
 		logf("failed to parse given bytes, building it anyway: %v", err)
@@ -543,63 +561,20 @@ func (c *Component) setParameterFromBytesWithTag(b []byte) error {
 }
 
 // SetValsFrom sets the values from IE parsed by ParseBER.
+//
+// Each Component is read from the contents of its IE in the same way as Parse
+// does, keeping LinkedID and the elements not known in Unknown.
 func (c *Components) SetValsFrom(berParsed *IE) error {
 	c.Tag = berParsed.Tag
 	c.Length = berParsed.Length
 	for _, ie := range berParsed.IE {
-		comp := &Component{
-			Type:   ie.Tag,
-			Length: ie.Length,
-		}
-
-		switch ie.Tag {
-		case 0xa1: // Invoke
-			for i, iex := range ie.IE {
-				switch iex.Tag {
-				case 0x02:
-					if i == 0 {
-						comp.InvokeID = iex
-					} else {
-						comp.OperationCode = iex
-					}
-				case 0x30:
-					comp.Parameter = iex
-				}
-			}
-		case 0xa2, 0xa7: // ReturnResult(Not)Last
-			for i, iex := range ie.IE {
-				switch iex.Tag {
-				case 0x02:
-					if i == 0 {
-						comp.InvokeID = iex
-					}
-				case 0x30:
-					comp.ResultRetres = iex
-					for _, riex := range iex.IE {
-						switch riex.Tag {
-						case 0x02:
-							comp.OperationCode = riex
-						case 0x30:
-							comp.Parameter = riex
-						}
-					}
-				}
-			}
-		case 0xa3: // ReturnError
-			for i, iex := range ie.IE {
-				switch iex.Tag {
-				case 0x02:
-					if i == 0 {
-						comp.InvokeID = iex
-					} else {
-						comp.ErrorCode = iex
-					}
-				case 0x30:
-					comp.Parameter = iex
-				}
-			}
-		}
+		b, start := beginTLV(nil, ie.Tag)
+		b = endTLV(append(b, ie.Value...), start)
 
+		comp := &Component{}
+		if err := comp.UnmarshalBinary(b); err != nil {
+			return err
+		}
 		c.Component = append(c.Component, comp)
 	}
 
@@ -608,17 +583,26 @@ func (c *Components) SetValsFrom(berParsed *IE) error {
 
 // MarshalLen returns the serial length of Components.
 func (c *Components) MarshalLen() int {
-	l := 2
+	l := 0
 	for _, comp := range c.Component {
 		l += comp.MarshalLen()
 	}
-	fmt.Println("components:", "len", l)
-	return l
+	return 1 + lengthLen(l) + l
 }
 
 // MarshalLen returns the serial length of Component.
 func (c *Component) MarshalLen() int {
-	l := c.InvokeID.MarshalLen()
+	if len(c.Unknown) != 0 || c.orig != nil {
+		b, err := c.AppendBinary(nil)
+		if err == nil {
+			return len(b)
+		}
+	}
+
+	l := 0
+	if field := c.InvokeID; field != nil {
+		l += field.MarshalLen()
+	}
 	switch c.Type.Code() {
 	case Invoke:
 		if field := c.LinkedID; field != nil {
@@ -631,15 +615,17 @@ func (c *Component) MarshalLen() int {
 			l += field.MarshalLen()
 		}
 	case ReturnResultLast, ReturnResultNotLast:
-		if field := c.ResultRetres; field != nil {
-			l += field.MarshalLen()
-		}
+		seq := 0
 		if field := c.OperationCode; field != nil {
-			l += field.MarshalLen()
+			seq += field.MarshalLen()
 		}
 		if field := c.Parameter; field != nil {
-			l += field.MarshalLen()
+			seq += field.MarshalLen()
+		}
+		if c.ResultRetres != nil {
+			seq += 1 + lengthLen(seq)
 		}
+		l += seq
 	case ReturnError:
 		if field := c.ErrorCode; field != nil {
 			l += field.MarshalLen()
@@ -652,11 +638,7 @@ func (c *Component) MarshalLen() int {
 			l += field.MarshalLen()
 		}
 	}
-	if c.Length > 127 {
-		return l + 3
-	} else {
-		return l + 2
-	}
+	return 1 + lengthLen(l) + l
 }
 
 // SetLength sets the length in Length field.
@@ -720,22 +702,64 @@ func (c *Component) ComponentTypeString() string {
 	return ""
 }
 
-// InvID returns the InvID in string.
+// InvID returns the InvID in string, or 0 if the Component does not have it,
+// e.g. the NULL Invoke ID of Reject.
 func (c *Component) InvID() uint8 {
-	if c.InvokeID != nil {
-		return c.InvokeID.Value[0]
+	if id := c.InvokeID; id != nil && len(id.Value) != 0 {
+		return id.Value[0]
 	}
 	return 0
 }
 
-// OpCode returns the OpCode in string.
+// OpCode returns the OpCode in string, or the Error Code of ReturnError. It
+// returns 0 if the Component does not have it, e.g. ReturnResult without the
+// result sequence; use OperationCodeValue to tell it from the Operation Code 0.
 func (c *Component) OpCode() uint8 {
-	if c.Type.Code() == ReturnError {
-		return c.ErrorCode.Value[0]
-	} else if c.Type.Code() != Reject {
-		return c.OperationCode.Value[0]
+	code, ok := c.OperationCodeValue()
+	if !ok {
+		return 0
 	}
-	return 0
+	return uint8(code)
+}
+
+// OperationCodeValue returns the OpCode, or the Error Code of ReturnError. It
+// returns false if the Component does not have it, e.g. ReturnResult without
+// the result sequence, as 0 is a valid Operation Code.
+func (c *Component) OperationCodeValue() (int, bool) {
+	code := c.OperationCode
+	switch c.Type.Code() {
+	case ReturnError:
+		code = c.ErrorCode
+	case Reject:
+		return 0, false
+	}
+	if code == nil || len(code.Value) == 0 {
+		return 0, false
+	}
+	return int(code.Value[0]), true
+}
+
+// ErrorName returns the name of the local Error Code of ReturnError registered
+// in DefaultRegistry in the Application Context Name acn, or in any Application
+// Context. It returns the empty string if not found.
+//
+// The caller gives acn, as the dialogue portion with it is usually only in the
+// first messages of the dialogue and ReturnError is often sent in End without
+// it, e.g.:
+//
+//	name := c.ErrorName(gsmmap.ApplicationContext(tcap.ShortMsgGatewayContext, 3))
+func (c *Component) ErrorName(acn string) string {
+	if c.Type.Code() != ReturnError {
+		return ""
+	}
+	code, ok := localCode(c.ErrorCode)
+	if !ok {
+		return ""
+	}
+	if e := LookupError(acn, code); e != nil {
+		return e.Name
+	}
+	return ""
 }
 
 // String returns Components in human readable string.
@@ -0,0 +1,80 @@
+package tcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestReturnResultWithoutResult(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		tcap    *TCAP
+		hex     string
+		opCode  int
+		ok      bool
+		payload []byte
+	}{
+		{
+			"without result sequence",
+			NewEndReturnResult(2, 1, 71, true, nil),
+			"640d4904000000026c05a203020101",
+			0, false, nil,
+		}, {
+			"with empty Parameter",
+			NewEndReturnResult(2, 1, 71, true, []byte{}),
+			"640d4904000000026c05a203020101",
+			0, false, nil,
+		}, {
+			"with Operation Code 0",
+			NewEndReturnResult(2, 1, 0, true, []byte{0x04, 0x01, 0x0f}),
+			"64154904000000026c0da20b020101300602010004010f",
+			0, true, []byte{0x0f},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			want, err := hex.DecodeString(c.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.tcap.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %x, want %x", got, want)
+			}
+
+			parsed, err := Parse(want, WithValidation())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parsed.OpCode(); len(got) != 1 || got[0] != uint8(c.opCode) {
+				t.Errorf("OpCode: got %v, want [%d]", got, c.opCode)
+			}
+			code, ok := parsed.Components.Component[0].OperationCodeValue()
+			if code != c.opCode || ok != c.ok {
+				t.Errorf("OperationCodeValue: got %d, %v, want %d, %v", code, ok, c.opCode, c.ok)
+			}
+			if got := parsed.LayerPayload(); len(got) != 1 || !bytes.Equal(got[0], c.payload) {
+				t.Errorf("LayerPayload: got %x, want [%x]", got, c.payload)
+			}
+			if got, err = parsed.MarshalBinary(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("re-encoded: got %x, want %x", got, want)
+			}
+		})
+	}
+
+	// The result sequence with only the Operation Code is parsed, but violates
+	// Q.773 as the Parameter is mandatory in it.
+	b := mustHexString("64124904000000026c0aa2080201013003020147")
+	if _, err := Parse(b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(b, WithValidation()); err == nil {
+		t.Error("got no error with the result sequence without Parameter")
+	}
+}
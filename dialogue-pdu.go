@@ -5,19 +5,26 @@
 package tcap
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
 	"github.com/pkg/errors"
 )
 
 // Code definitions.
 const (
-	AARQ = iota
-	AARE
-	ABRT
-	ABRT2 = 4
-	// AUDT = 0
+	AARQ = 0
+	AARE = 1
+	ABRT = 4
+
+	// AUDT is the Unidirectional Dialogue in the Dialogue with
+	// UnidialogueAsID, which shares the tag and the fields with AARQ.
+	AUDT = 0
+
+	// Deprecated: ABRT2 is the same as ABRT, which is [APPLICATION 4].
+	ABRT2 = ABRT
 )
 
 // Application Context definitions.
@@ -87,8 +94,19 @@ const (
 const (
 	Null uint8 = iota
 	NoReasonGiven
-	ApplicationContextNameNotSupplied
-	NoCommonDialoguePortion = 2 // same as above...
+	ApplicationContextNameNotSupported
+
+	// Deprecated: use ApplicationContextNameNotSupported.
+	ApplicationContextNameNotSupplied = ApplicationContextNameNotSupported
+)
+
+// Reason defnitions for Dialogue Service Provider Diagnostic in
+// ResultSourceDiagnostic, which are in the different set from the ones of
+// Dialogue Service User with the same values.
+const (
+	ProviderNull uint8 = iota
+	ProviderNoReasonGiven
+	NoCommonDialoguePortion
 )
 
 // Abort Source defnitions.
@@ -107,6 +125,12 @@ type DialoguePDU struct {
 	ResultSourceDiagnostic *IE
 	AbortSource            *IE
 	UserInformation        *IE
+	// Unknown is the elements with the tags not known, which are kept as they
+	// are and put where they were parsed, or after the other fields. The
+	// security context and confidentiality are kept here, as Q.773 does not
+	// define them in the dialogue PDUs.
+	Unknown   []*IE
+	unknownAt unknownAt
 }
 
 // NewDialoguePDU creates a new DialoguePDU.
@@ -120,23 +144,30 @@ func NewDialoguePDU(dtype, pver int, ctx, ctxver, result uint8, diagsrc int, dia
 		ApplicationContextName: NewApplicationContextName(ctx, ctxver),
 		Result:                 NewResult(result),
 		ResultSourceDiagnostic: NewResultSourceDiagnostic(diagsrc, diagreason),
-		AbortSource: &IE{
-			Tag:    NewContextSpecificPrimitiveTag(0),
-			Length: 1,
-			Value:  []byte{abortsrc},
-		},
-	}
-	if len(userinfo) > 0 {
-		d.UserInformation = &IE{
-			Tag:   NewContextSpecificConstructorTag(30),
-			Value: userinfo[0].Value,
-		}
-		d.UserInformation.SetLength()
+		AbortSource:            NewAbortSource(abortsrc),
 	}
+	d.UserInformation = joinUserInformation(userinfo)
 	d.SetLength()
 	return d
 }
 
+// joinUserInformation returns the user information with the contents of all
+// the ones given concatenated, e.g. the ones of NewUserInformation each with
+// an External, or nil if none is given.
+func joinUserInformation(userinfo []*IE) *IE {
+	if len(userinfo) == 0 {
+		return nil
+	}
+
+	var v []byte
+	for _, ui := range userinfo {
+		v = append(v, ui.Value...)
+	}
+	ie := &IE{Tag: NewContextSpecificConstructorTag(30), Value: v}
+	ie.SetLength()
+	return ie
+}
+
 // NewApplicationContextName creates a new ApplicationContextName as an IE.
 // Note: In this function, each length in fields are hard-coded.
 func NewApplicationContextName(ctx, ver uint8) *IE {
@@ -172,7 +203,7 @@ func NewResultSourceDiagnostic(dtype int, reason uint8) *IE {
 // NewAbortSource returns a new AbortSource as an IE.
 func NewAbortSource(src uint8) *IE {
 	return &IE{
-		Tag:    NewContextSpecificPrimitiveTag(4),
+		Tag:    NewContextSpecificPrimitiveTag(0),
 		Length: 1,
 		Value:  []byte{src},
 	}
@@ -188,13 +219,7 @@ func NewAARQ(protover int, context, contextver uint8, userinfo ...*IE) *Dialogue
 		},
 		ApplicationContextName: NewApplicationContextName(context, contextver),
 	}
-	if len(userinfo) > 0 {
-		d.UserInformation = &IE{
-			Tag:   NewContextSpecificConstructorTag(30),
-			Value: userinfo[0].Value,
-		}
-		d.UserInformation.SetLength()
-	}
+	d.UserInformation = joinUserInformation(userinfo)
 	d.SetLength()
 	return d
 }
@@ -211,13 +236,7 @@ func NewAARE(protover int, context, contextver, result uint8, diagsrc int, reaso
 		Result:                 NewResult(result),
 		ResultSourceDiagnostic: NewResultSourceDiagnostic(diagsrc, reason),
 	}
-	if len(userinfo) > 0 {
-		d.UserInformation = &IE{
-			Tag:   NewContextSpecificConstructorTag(30),
-			Value: userinfo[0].Value,
-		}
-		d.UserInformation.SetLength()
-	}
+	d.UserInformation = joinUserInformation(userinfo)
 	d.SetLength()
 	return d
 }
@@ -225,54 +244,26 @@ func NewAARE(protover int, context, contextver, result uint8, diagsrc int, reaso
 // NewABRT returns a new ABRT(Dialogue Abort).
 func NewABRT(abortsrc uint8, userinfo ...*IE) *DialoguePDU {
 	d := &DialoguePDU{
-		Type: NewApplicationWideConstructorTag(ABRT),
-		AbortSource: &IE{
-			Tag:    NewContextSpecificPrimitiveTag(0),
-			Length: 1,
-			Value:  []byte{abortsrc},
-		},
-	}
-	if len(userinfo) > 0 {
-		d.UserInformation = &IE{
-			Tag:   NewContextSpecificConstructorTag(30),
-			Value: userinfo[0].Value,
-		}
-		d.UserInformation.SetLength()
+		Type:        NewApplicationWideConstructorTag(ABRT),
+		AbortSource: NewAbortSource(abortsrc),
 	}
+	d.UserInformation = joinUserInformation(userinfo)
 	d.SetLength()
 	return d
 }
 
-/*
 // NewAUDT returns a new AUDT(Unidirectional Dialogue).
+//
+// AUDT is encoded in the same way as AARQ, and is to be carried in the Dialogue
+// with UnidialogueAsID.
 func NewAUDT(protover int, context, contextver uint8, userinfo ...*IE) *DialoguePDU {
-    d := NewDialoguePDU(
-        AUDT,
-        protover,
-        context,
-        contextver,
-        0,
-        0,
-        0,
-        0,
-    )
-    if len(userinfo) > 0 {
-        d.UserInformation = userinfo[0]
-    }
-    d.ProtocolVersion.Clear()
-    d.ApplicationContextName.Clear()
-    d.Result.Clear()
-    d.ResultSourceDiagnostic.Clear()
-    d.SetLength()
-
-    return d
-}
-*/
+	return NewAARQ(protover, context, contextver, userinfo...)
+}
 
 // MarshalBinary returns the byte sequence generated from a Dialogue instance.
 func (d *DialoguePDU) MarshalBinary() ([]byte, error) {
-	b := make([]byte, d.MarshalLen())
-	if err := d.MarshalTo(b); err != nil {
+	b, err := d.AppendBinary(make([]byte, 0, d.MarshalLen()))
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to marshal DialoguePDU:")
 	}
 	return b, nil
@@ -280,105 +271,47 @@ func (d *DialoguePDU) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *DialoguePDU) MarshalTo(b []byte) error {
-	if len(b) < 2 {
-		return io.ErrUnexpectedEOF
-	}
+	return marshalTo(b, d.AppendBinary)
+}
 
-	b[0] = uint8(d.Type)
-	b[1] = d.Length
+// AppendBinary appends the byte sequence generated from a DialoguePDU instance to b.
+//
+// The length is computed from the contents, not taken from the Length field.
+func (d *DialoguePDU) AppendBinary(b []byte) ([]byte, error) {
+	b, start := beginTLV(b, d.Type)
 
 	switch d.Type.Code() {
-	case AARQ:
-		return d.marshalAARQTo(b)
-	case AARE:
-		return d.marshalAARETo(b)
-	case ABRT:
-		return d.marshalABRTTo(b)
+	case AARQ, AARE, ABRT:
 	default:
-		return &InvalidCodeError{Code: d.Type.Code()}
-	}
-}
-
-func (d *DialoguePDU) marshalAARQTo(b []byte) error {
-	offset := 2
-	if field := d.ProtocolVersion; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.ApplicationContextName; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func (d *DialoguePDU) marshalAARETo(b []byte) error {
-	offset := 2
-	if field := d.ProtocolVersion; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
+		return nil, &InvalidCodeError{Code: d.Type.Code()}
 	}
 
-	if field := d.ApplicationContextName; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.Result; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.ResultSourceDiagnostic; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
+	w := newUnknownWriter(d.Unknown, d.unknownAt)
+	b = w.appendAfter(b, nil)
+	b, err := w.appendFields(b, d.fields()...)
+	if err != nil {
+		return nil, err
 	}
+	b = w.appendRest(b)
 
-	return nil
+	return endTLV(b, start), nil
 }
 
-func (d *DialoguePDU) marshalABRTTo(b []byte) error {
-	offset := 2
-	if field := d.AbortSource; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
-
-	if field := d.UserInformation; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
+// fields returns the fields that the type of DialoguePDU can have in order,
+// including the nil ones.
+func (d *DialoguePDU) fields() []*IE {
+	var fields []*IE
+	switch d.Type.Code() {
+	case AARQ:
+		fields = []*IE{d.ProtocolVersion, d.ApplicationContextName, d.UserInformation}
+	case AARE:
+		fields = []*IE{d.ProtocolVersion, d.ApplicationContextName, d.Result, d.ResultSourceDiagnostic, d.UserInformation}
+	case ABRT:
+		fields = []*IE{d.AbortSource, d.UserInformation}
+	default:
+		fields = []*IE{d.UserInformation}
 	}
-
-	return nil
+	return fields
 }
 
 // ParseDialoguePDU parses given byte sequence as an DialoguePDU.
@@ -391,182 +324,112 @@ func ParseDialoguePDU(b []byte) (*DialoguePDU, error) {
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an DialoguePDU.
+//
+// AUDT is parsed as AARQ, as they have the same tag and fields.
 func (d *DialoguePDU) UnmarshalBinary(b []byte) error {
 	if len(b) < 4 {
 		return io.ErrUnexpectedEOF
 	}
 
-	d.Type = Tag(b[0])
-	d.Length = b[1]
+	tag, v, _, err := readTLV(b)
+	if err != nil {
+		return err
+	}
+	d.Type = tag
+	d.Length = uint8(len(v))
+	d.Unknown, d.unknownAt = nil, nil
 
 	switch d.Type.Code() {
 	case AARQ:
-		return d.parseAARQFromBytes(b)
+		return d.parseAARQFromBytes(v)
 	case AARE:
-		return d.parseAAREFromBytes(b)
+		return d.parseAAREFromBytes(v)
 	case ABRT:
-		return d.parseABRTFromBytes(b)
-	case ABRT2:
-		return d.parseABRTFromBytes(b)
+		return d.parseABRTFromBytes(v)
 	default:
 		return &InvalidCodeError{Code: d.Type.Code()}
 	}
 }
 
 func (d *DialoguePDU) parseAARQFromBytes(b []byte) error {
-	var err error
-	offset := 2
-	d.ProtocolVersion, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.ProtocolVersion.MarshalLen()
-
-	d.ApplicationContextName, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.ApplicationContextName.MarshalLen()
-
-	if offset < len(b)-1 {
-		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
-			d.UserInformation, err = ParseIE(b[offset:])
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return d.parseFields(b, map[Tag]**IE{
+		NewContextSpecificPrimitiveTag(0):    &d.ProtocolVersion,
+		NewContextSpecificConstructorTag(1):  &d.ApplicationContextName,
+		NewContextSpecificConstructorTag(30): &d.UserInformation,
+	})
 }
 
 func (d *DialoguePDU) parseAAREFromBytes(b []byte) error {
-	var err error
-	offset := 2
-	d.ProtocolVersion, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.ProtocolVersion.MarshalLen()
-
-	d.ApplicationContextName, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.ApplicationContextName.MarshalLen()
-
-	d.Result, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.Result.MarshalLen()
+	return d.parseFields(b, map[Tag]**IE{
+		NewContextSpecificPrimitiveTag(0):    &d.ProtocolVersion,
+		NewContextSpecificConstructorTag(1):  &d.ApplicationContextName,
+		NewContextSpecificConstructorTag(2):  &d.Result,
+		NewContextSpecificConstructorTag(3):  &d.ResultSourceDiagnostic,
+		NewContextSpecificConstructorTag(30): &d.UserInformation,
+	})
+}
 
-	d.ResultSourceDiagnostic, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.ResultSourceDiagnostic.MarshalLen()
+func (d *DialoguePDU) parseABRTFromBytes(b []byte) error {
+	return d.parseFields(b, map[Tag]**IE{
+		NewContextSpecificPrimitiveTag(0):    &d.AbortSource,
+		NewContextSpecificConstructorTag(30): &d.UserInformation,
+	})
+}
 
-	if offset < len(b)-1 {
-		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
-			d.UserInformation, err = ParseIE(b[offset:])
-			if err != nil {
-				return err
-			}
+// parseFields parses the IEs in the contents b, and sets them in the fields by
+// the tags. The optional ones such as Protocol Version can be omitted, and the
+// ones with unknown tags are kept in Unknown.
+func (d *DialoguePDU) parseFields(b []byte, fields map[Tag]**IE) error {
+	var prev *IE
+	for len(b) != 0 {
+		_, _, n, err := readTLV(b)
+		if err != nil {
+			return err
 		}
-	}
-
-	return nil
-}
+		ie, err := ParseIE(b[:n])
+		if err != nil {
+			return err
+		}
+		b = b[n:]
 
-func (d *DialoguePDU) parseABRTFromBytes(b []byte) error {
-	var err error
-	offset := 2
-	d.AbortSource, err = ParseIE(b[offset:])
-	if err != nil {
-		return err
-	}
-	offset += d.AbortSource.MarshalLen()
-	if offset < len(b)-1 {
-		if b[offset] == uint8(NewContextSpecificConstructorTag(30)) {
-			d.UserInformation, err = ParseIE(b[offset:])
-			if err != nil {
-				return err
-			}
+		if field, ok := fields[ie.Tag]; ok {
+			*field = ie
+			prev = ie
+			continue
 		}
+		d.Unknown = d.unknownAt.add(d.Unknown, ie, prev)
 	}
-
 	return nil
 }
 
 // MarshalLen returns the serial length of DialoguePDU.
 func (d *DialoguePDU) MarshalLen() int {
-	l := 2
-	switch d.Type.Code() {
-	case AARQ:
-		if field := d.ProtocolVersion; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := d.ApplicationContextName; field != nil {
-			l += field.MarshalLen()
-		}
-	case AARE:
-		if field := d.ProtocolVersion; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := d.ApplicationContextName; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := d.Result; field != nil {
-			l += field.MarshalLen()
-		}
-		if field := d.ResultSourceDiagnostic; field != nil {
-			l += field.MarshalLen()
-		}
-	case ABRT:
-		if field := d.AbortSource; field != nil {
+	l := d.valueLen()
+	return 1 + lengthLen(l) + l
+}
+
+// valueLen returns the length of the contents of DialoguePDU.
+func (d *DialoguePDU) valueLen() int {
+	l := unknownLen(d.Unknown)
+	for _, field := range d.fields() {
+		if field != nil {
 			l += field.MarshalLen()
 		}
 	}
-
-	if field := d.UserInformation; field != nil {
-		l += field.MarshalLen()
-	}
 	return l
 }
 
 // SetLength sets the length in Length field.
 func (d *DialoguePDU) SetLength() {
-	switch d.Type.Code() {
-	case AARQ:
-		if field := d.ProtocolVersion; field != nil {
-			field.SetLength()
-		}
-		if field := d.ApplicationContextName; field != nil {
-			field.SetLength()
-		}
-	case AARE:
-		if field := d.ProtocolVersion; field != nil {
-			field.SetLength()
-		}
-		if field := d.ApplicationContextName; field != nil {
-			field.SetLength()
-		}
-		if field := d.Result; field != nil {
-			field.SetLength()
-		}
-		if field := d.ResultSourceDiagnostic; field != nil {
-			field.SetLength()
-		}
-	case ABRT:
-		if field := d.AbortSource; field != nil {
+	for _, field := range d.fields() {
+		if field != nil {
 			field.SetLength()
 		}
 	}
-	if field := d.UserInformation; field != nil {
-		field.SetLength()
+	for _, ie := range d.Unknown {
+		ie.SetLength()
 	}
-	d.Length = uint8(d.MarshalLen() - 2)
+	d.Length = uint8(d.valueLen())
 }
 
 // DialogueType returns the name of Dialogue Type in string.
@@ -583,26 +446,84 @@ func (d *DialoguePDU) DialogueType() string {
 	}
 }
 
-// Version returns Protocol Version in string.
+// Version returns Protocol Version in string, or the empty string if not present.
 func (d *DialoguePDU) Version() string {
+	if pv := d.ProtocolVersion; pv == nil || len(pv.Value) == 0 {
+		return ""
+	}
 	if d.Type.Code() == AARQ || d.Type.Code() == AARE {
 		return fmt.Sprintf("%d", d.ProtocolVersion.Value[len(d.ProtocolVersion.Value)-1]>>7)
 	}
 	return ""
 }
 
-// Version returns Protocol Version in string.
+// AbortSourceString returns Abort Source of ABRT in string, or the empty string
+// if not present.
 func (d *DialoguePDU) AbortSourceString() string {
-	switch d.AbortSource.Value[0] {
-	case 0:
+	src, ok := d.AbortSourceCode()
+	if !ok {
+		return ""
+	}
+	switch src {
+	case AbortDialogueServiceUser:
 		return "User"
-	case 1:
+	case AbortDialogueServiceProvider:
 		return "Provider"
 	default:
 		return "Unknown Abort Source"
 	}
 }
 
+// AbortSourceCode returns Abort Source of ABRT, i.e. AbortDialogueServiceUser or
+// AbortDialogueServiceProvider. It returns false if d is not ABRT or does not
+// have a valid Abort Source.
+func (d *DialoguePDU) AbortSourceCode() (int, bool) {
+	if d.Type.Code() != ABRT || d.AbortSource == nil || len(d.AbortSource.Value) == 0 {
+		return 0, false
+	}
+	return ber.DecodeInteger(d.AbortSource.Value), true
+}
+
+// AssociateResult returns Result of AARE, i.e. Accepted or RejectPerm. It
+// returns false if d is not AARE or does not have a valid Result.
+func (d *DialoguePDU) AssociateResult() (uint8, bool) {
+	if d.Type.Code() != AARE || d.Result == nil {
+		return 0, false
+	}
+	res, err := d.resultCode()
+	if err != nil {
+		return 0, false
+	}
+	return uint8(res), true
+}
+
+// Diagnostic is Result Source Diagnostic of AARE. Reason is one of the reasons
+// of the Source, e.g. NoCommonDialoguePortion with DialogueServiceProvider.
+type Diagnostic struct {
+	// Source is DialogueServiceUser or DialogueServiceProvider.
+	Source int
+	Reason uint8
+}
+
+// String returns the names of the source and the reason, e.g.
+// "dialogue-service-user: application-context-name-not-supported".
+func (g *Diagnostic) String() string {
+	return enumName(g.Source, diagnosticSourceNames) + ": " + enumName(int(g.Reason), diagnosticReasonNames[g.Source])
+}
+
+// SourceDiagnostic returns Result Source Diagnostic of AARE. It returns false
+// if d is not AARE or does not have a valid Result Source Diagnostic.
+func (d *DialoguePDU) SourceDiagnostic() (*Diagnostic, bool) {
+	if d.Type.Code() != AARE || d.ResultSourceDiagnostic == nil {
+		return nil, false
+	}
+	src, reason, err := d.diagnostic()
+	if err != nil {
+		return nil, false
+	}
+	return &Diagnostic{Source: src, Reason: uint8(reason)}, true
+}
+
 // Context returns the Context part of ApplicationContextName in string.
 func (d *DialoguePDU) Context() string {
 	appCtx := d.ApplicationContextName
@@ -719,7 +640,7 @@ func (d *DialoguePDU) ContextVersion() string {
 
 // String returns DialoguePDU in human readable string.
 func (d *DialoguePDU) String() string {
-	return fmt.Sprintf("{Type: %#x, Length: %d, ProtocolVersion: %v, ApplicationContextName: %v, Result: %v, ResultSourceDiagnostic: %v, AbortSource: %v}",
+	return fmt.Sprintf("{Type: %#x, Length: %d, ProtocolVersion: %v, ApplicationContextName: %v, Result: %v, ResultSourceDiagnostic: %v, AbortSource: %v, UserInformation: %v, Unknown: %v}",
 		d.Type,
 		d.Length,
 		d.ProtocolVersion,
@@ -727,5 +648,48 @@ func (d *DialoguePDU) String() string {
 		d.Result,
 		d.ResultSourceDiagnostic,
 		d.AbortSource,
+		d.UserInformation,
+		d.Unknown,
 	)
 }
+
+// applicationContext returns the Application Context Name in dotted notation,
+// with the name and version of it if it is one of the MAP application contexts.
+func (d *DialoguePDU) applicationContext() (oid, name string, ver int, err error) {
+	_, v, _, err := ber.ReadTLV(d.ApplicationContextName.Value)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if oid, err = ber.FormatOID(v); err != nil {
+		return "", "", 0, err
+	}
+
+	// {itu-t identified-organization etsi mobileDomain gsm-Network ac-Id ctx ver},
+	// which Context and ContextVersion can name.
+	if len(v) == 7 && bytes.HasPrefix(v, []byte{0x04, 0x00, 0x00, 0x01, 0x00}) {
+		name, ver = d.Context(), int(v[6])
+	}
+	return oid, name, ver, nil
+}
+
+// resultCode returns the value of the INTEGER in Result.
+func (d *DialoguePDU) resultCode() (int, error) {
+	_, v, _, err := ber.ReadTLV(d.Result.Value)
+	if err != nil {
+		return 0, err
+	}
+	return ber.DecodeInteger(v), nil
+}
+
+// diagnostic returns the source (DialogueServiceUser or DialogueServiceProvider)
+// and the reason in Result Source Diagnostic.
+func (d *DialoguePDU) diagnostic() (int, int, error) {
+	src, v, _, err := ber.ReadTLV(d.ResultSourceDiagnostic.Value)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, v, _, err = ber.ReadTLV(v); err != nil {
+		return 0, 0, err
+	}
+	return Tag(src).Code(), ber.DecodeInteger(v), nil
+}
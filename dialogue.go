@@ -26,7 +26,13 @@ type Dialogue struct {
 	ObjectIdentifier *IE
 	SingleAsn1Type   *IE
 	DialoguePDU      *DialoguePDU
-	Payload          []byte
+	// Unknown is the elements after SingleAsn1Type in the EXTERNAL, which are
+	// kept as they are and put after it.
+	Unknown []*IE
+	Payload []byte
+
+	raw  []byte
+	orig *original
 }
 
 // NewDialogue creates a new Dialogue with the DialoguePDU given.
@@ -53,8 +59,8 @@ func NewDialogue(oid, ver uint8, pdu *DialoguePDU, payload []byte) *Dialogue {
 
 // MarshalBinary returns the byte sequence generated from a Dialogue instance.
 func (d *Dialogue) MarshalBinary() ([]byte, error) {
-	b := make([]byte, d.MarshalLen())
-	if err := d.MarshalTo(b); err != nil {
+	b, err := d.AppendBinary(make([]byte, 0, d.MarshalLen()))
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to serialize Dialogue:")
 	}
 	return b, nil
@@ -62,38 +68,58 @@ func (d *Dialogue) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *Dialogue) MarshalTo(b []byte) error {
-	if len(b) < 4 {
-		return io.ErrUnexpectedEOF
-	}
-	b[0] = uint8(d.Tag)
-	b[1] = d.Length
-	b[2] = uint8(d.ExternalTag)
-	b[3] = d.ExternalLength
+	return marshalTo(b, d.AppendBinary)
+}
 
-	var offset = 4
-	if field := d.ObjectIdentifier; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
-	}
+// AppendBinary appends the byte sequence generated from a Dialogue instance to b.
+//
+// The lengths are computed from the contents, not taken from the Length fields.
+// If DialoguePDU is set, it is put in the SingleAsn1Type and the Value of
+// SingleAsn1Type itself is ignored.
+func (d *Dialogue) AppendBinary(b []byte) ([]byte, error) {
+	return d.appendBinary(b, true)
+}
 
-	if field := d.SingleAsn1Type; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
-		}
-		offset += field.MarshalLen()
+// appendBinary appends the Dialogue to b, with or without the Payload.
+func (d *Dialogue) appendBinary(b []byte, withPayload bool) ([]byte, error) {
+	portion := len(b)
+	b, start := beginTLV(b, d.Tag)
+	b, ext := beginTLV(b, d.ExternalTag)
+
+	var err error
+	if b, err = appendIEs(b, d.ObjectIdentifier); err != nil {
+		return nil, err
 	}
 
-	if field := d.DialoguePDU; field != nil {
-		if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-			return err
+	switch {
+	case d.DialoguePDU != nil && d.SingleAsn1Type != nil:
+		var asn1 int
+		b, asn1 = beginTLV(b, d.SingleAsn1Type.Tag)
+		if b, err = d.DialoguePDU.AppendBinary(b); err != nil {
+			return nil, err
 		}
-		offset += field.MarshalLen()
+		b = endTLV(b, asn1)
+	case d.DialoguePDU != nil:
+		b, err = d.DialoguePDU.AppendBinary(b)
+	default:
+		b, err = appendIEs(b, d.SingleAsn1Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b, err = appendIEs(b, d.Unknown...); err != nil {
+		return nil, err
 	}
 
-	copy(b[offset:], d.Payload)
-	return nil
+	if withPayload {
+		b = append(b, d.Payload...)
+	}
+	b = endTLV(b, ext)
+	b = endTLV(b, start)
+	if !withPayload || len(d.Payload) == 0 {
+		b = d.orig.replace(b, portion)
+	}
+	return b, nil
 }
 
 // ParseDialogue parses given byte sequence as an Dialogue.
@@ -106,60 +132,89 @@ func ParseDialogue(b []byte) (*Dialogue, error) {
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an Dialogue.
+//
+// Both the short and the long form of the lengths are accepted, and the
+// DialoguePDU is AUDT if the ObjectIdentifier is Unidialogue-As-Id. The bytes
+// after the Dialogue Portion are set in the Payload.
 func (d *Dialogue) UnmarshalBinary(b []byte) error {
-	l := len(b)
-	if l < 5 {
+	if len(b) < 5 {
 		return io.ErrUnexpectedEOF
 	}
 
-	d.Tag = Tag(b[0])
-	d.Length = b[1]
-	d.ExternalTag = Tag(b[2])
-	d.ExternalLength = b[3]
+	tag, v, n, err := readTLV(b)
+	if err != nil {
+		return err
+	}
+	d.Tag = tag
+	d.Length = uint8(len(v))
+	d.raw, d.orig = b[:n], nil
 
-	var err error
-	var offset = 4
-	d.ObjectIdentifier, err = ParseIE(b[offset:])
+	tag, v, _, err = readTLV(v)
 	if err != nil {
 		return err
 	}
-	offset += d.ObjectIdentifier.MarshalLen()
+	d.ExternalTag = tag
+	d.ExternalLength = uint8(len(v))
 
-	d.SingleAsn1Type, err = ParseIE(b[offset:])
+	_, _, offset, err := readTLV(v)
 	if err != nil {
 		return err
 	}
-	offset += d.SingleAsn1Type.MarshalLen()
+	if d.ObjectIdentifier, err = ParseIE(v[:offset]); err != nil {
+		return err
+	}
+	v = v[offset:]
+
+	if _, _, offset, err = readTLV(v); err != nil {
+		return err
+	}
+	if d.SingleAsn1Type, err = ParseIE(v[:offset]); err != nil {
+		return err
+	}
+
+	if d.Unknown, err = ParseMultiIEs(v[offset:]); err != nil {
+		return err
+	}
 
 	d.DialoguePDU, err = ParseDialoguePDU(d.SingleAsn1Type.Value)
 	if err != nil {
 		return err
 	}
 
-	d.Payload = b[offset:]
+	d.Payload = b[n:]
 
 	return nil
 }
 
 // SetValsFrom sets the values from IE parsed by ParseBER.
+//
+// It returns io.ErrUnexpectedEOF if the EXTERNAL has no DialoguePDU in it, or
+// InvalidCodeError if the DialoguePDU is not AARQ, AARE or ABRT.
 func (d *Dialogue) SetValsFrom(berParsed *IE) error {
 	d.Tag = berParsed.Tag
 	d.Length = berParsed.Length
 	for _, ie := range berParsed.IE {
+		if ie.Tag != 0x28 {
+			continue
+		}
+		d.ExternalTag = ie.Tag
+		d.ExternalLength = ie.Length
+
 		var dpdu *IE
-		if ie.Tag == 0x28 {
-			d.ExternalTag = ie.Tag
-			d.ExternalLength = ie.Length
-			for _, iex := range ie.IE {
-				switch iex.Tag {
-				case 0x06:
-					d.ObjectIdentifier = iex
-				case 0xa0:
-					d.SingleAsn1Type = iex
+		for _, iex := range ie.IE {
+			switch iex.Tag {
+			case 0x06:
+				d.ObjectIdentifier = iex
+			case 0xa0:
+				d.SingleAsn1Type = iex
+				if len(iex.IE) != 0 {
 					dpdu = iex.IE[0]
 				}
 			}
 		}
+		if dpdu == nil {
+			return io.ErrUnexpectedEOF
+		}
 
 		switch dpdu.Tag.Code() {
 		case AARQ, AARE, ABRT:
@@ -167,18 +222,33 @@ func (d *Dialogue) SetValsFrom(berParsed *IE) error {
 				Type:   dpdu.Tag,
 				Length: dpdu.Length,
 			}
+		default:
+			return &InvalidCodeError{Code: dpdu.Tag.Code()}
 		}
+
+		pdu := d.DialoguePDU
+		var prev *IE
 		for _, iex := range dpdu.IE {
 			switch iex.Tag {
 			case 0x80:
-				d.DialoguePDU.ProtocolVersion = iex
+				if dpdu.Tag.Code() == ABRT {
+					pdu.AbortSource = iex
+				} else {
+					pdu.ProtocolVersion = iex
+				}
 			case 0xa1:
-				d.DialoguePDU.ApplicationContextName = iex
+				pdu.ApplicationContextName = iex
 			case 0xa2:
-				d.DialoguePDU.Result = iex
+				pdu.Result = iex
 			case 0xa3:
-				d.DialoguePDU.ResultSourceDiagnostic = iex
+				pdu.ResultSourceDiagnostic = iex
+			case 0xbe:
+				pdu.UserInformation = iex
+			default:
+				pdu.Unknown = pdu.unknownAt.add(pdu.Unknown, iex, prev)
+				continue
 			}
+			prev = iex
 		}
 	}
 	return nil
@@ -186,25 +256,53 @@ func (d *Dialogue) SetValsFrom(berParsed *IE) error {
 
 // MarshalLen returns the serial length of Dialogue.
 func (d *Dialogue) MarshalLen() int {
-	l := 4
-	if field := d.ObjectIdentifier; field != nil {
-		l += field.MarshalLen()
+	return d.marshalLen(true)
+}
+
+// marshalLen returns the serial length of Dialogue, with or without the Payload.
+func (d *Dialogue) marshalLen(withPayload bool) int {
+	l := d.externalLen(withPayload)
+	l += 1 + lengthLen(l)
+	l += 1 + lengthLen(l)
+	if !withPayload || len(d.Payload) == 0 {
+		return d.orig.marshalLen(l, func(b []byte) ([]byte, error) {
+			return d.appendBinary(b, false)
+		})
 	}
-	if field := d.SingleAsn1Type; field != nil {
-		l += field.MarshalLen()
+	return l
+}
+
+// externalLen returns the length of the contents of the EXTERNAL, in the same
+// way as appendBinary puts them.
+func (d *Dialogue) externalLen(withPayload bool) int {
+	l := unknownLen(d.Unknown)
+	if withPayload {
+		l += len(d.Payload)
 	}
-	if field := d.DialoguePDU; field != nil {
+	if field := d.ObjectIdentifier; field != nil {
 		l += field.MarshalLen()
 	}
-	l += len(d.Payload)
 
+	switch {
+	case d.DialoguePDU != nil && d.SingleAsn1Type != nil:
+		n := d.DialoguePDU.MarshalLen()
+		l += 1 + lengthLen(n) + n
+	case d.DialoguePDU != nil:
+		l += d.DialoguePDU.MarshalLen()
+	case d.SingleAsn1Type != nil:
+		l += d.SingleAsn1Type.MarshalLen()
+	}
 	return l
 }
 
 // SetLength sets the length in Length field.
 func (d *Dialogue) SetLength() {
-	d.Length = uint8(d.MarshalLen() - 2)
-	d.ExternalLength = uint8(d.MarshalLen() - 4)
+	l := d.externalLen(true)
+	d.ExternalLength = uint8(l)
+	d.Length = uint8(1 + lengthLen(l) + l)
+	if d.DialoguePDU != nil && d.SingleAsn1Type != nil {
+		d.SingleAsn1Type.Length = uint8(d.DialoguePDU.MarshalLen())
+	}
 }
 
 // String returns the SCCP common header values in human readable format.
@@ -247,3 +345,32 @@ func (d *Dialogue) ContextVersion() string {
 
 	return d.DialoguePDU.ContextVersion()
 }
+
+// AssociateResult returns Result of AARE in the Dialogue, or false if not present.
+func (d *Dialogue) AssociateResult() (uint8, bool) {
+	if d.DialoguePDU == nil {
+		return 0, false
+	}
+
+	return d.DialoguePDU.AssociateResult()
+}
+
+// SourceDiagnostic returns Result Source Diagnostic of AARE in the Dialogue, or
+// false if not present.
+func (d *Dialogue) SourceDiagnostic() (*Diagnostic, bool) {
+	if d.DialoguePDU == nil {
+		return nil, false
+	}
+
+	return d.DialoguePDU.SourceDiagnostic()
+}
+
+// AbortSourceCode returns Abort Source of ABRT in the Dialogue, or false if not
+// present.
+func (d *Dialogue) AbortSourceCode() (int, bool) {
+	if d.DialoguePDU == nil {
+		return 0, false
+	}
+
+	return d.DialoguePDU.AbortSourceCode()
+}
@@ -0,0 +1,258 @@
+package tcap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDialoguePDUAccessors(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		serialized  string
+		result      int
+		diagnostic  string
+		abortSource int
+	}{
+		{
+			"AARE accepted",
+			"611b80020780a109060704000001001302a203020100a305a103020100",
+			int(Accepted), "dialogue-service-user: null", -1,
+		}, {
+			"AARE rejected without protocol-version",
+			"6117a109060704000001001302a203020101a305a203020102",
+			int(RejectPerm), "dialogue-service-provider: no-common-dialogue-portion", -1,
+		}, {
+			"ABRT by provider",
+			"6403800101",
+			-1, "", AbortDialogueServiceProvider,
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			d, err := ParseDialoguePDU(mustHexString(c.serialized))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, ok := d.AssociateResult()
+			got := int(res)
+			if !ok {
+				got = -1
+			}
+			if got != c.result {
+				t.Errorf("AssociateResult: got %d, want %d", got, c.result)
+			}
+
+			var diag string
+			if g, ok := d.SourceDiagnostic(); ok {
+				diag = g.String()
+			}
+			if diag != c.diagnostic {
+				t.Errorf("SourceDiagnostic: got %q, want %q", diag, c.diagnostic)
+			}
+
+			src, ok := d.AbortSourceCode()
+			if !ok {
+				src = -1
+			}
+			if src != c.abortSource {
+				t.Errorf("AbortSourceCode: got %d, want %d", src, c.abortSource)
+			}
+		})
+	}
+}
+
+func TestDialoguePDUMissingFields(t *testing.T) {
+	d := &DialoguePDU{Type: NewApplicationWideConstructorTag(ABRT)}
+	if got := d.AbortSourceString(); got != "" {
+		t.Errorf("AbortSourceString: got %q, want empty", got)
+	}
+	if got := d.Version(); got != "" {
+		t.Errorf("Version: got %q, want empty", got)
+	}
+
+	var tc TCAP
+	if _, ok := tc.AssociateResult(); ok {
+		t.Error("AssociateResult: got a result without Dialogue Portion")
+	}
+	if _, ok := tc.SourceDiagnostic(); ok {
+		t.Error("SourceDiagnostic: got a diagnostic without Dialogue Portion")
+	}
+	if _, ok := tc.AbortSourceCode(); ok {
+		t.Error("AbortSourceCode: got a source without Dialogue Portion")
+	}
+}
+
+func TestABRT(t *testing.T) {
+	b, err := NewABRT(uint8(AbortDialogueServiceUser)).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b, mustHexString("6403800100"); string(got) != string(want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	d, err := ParseDialoguePDU(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.AbortSourceString(), "User"; got != want {
+		t.Errorf("AbortSourceString: got %q, want %q", got, want)
+	}
+}
+
+func TestDialogueRoundTrip(t *testing.T) {
+	data := append([]byte{0x04, 0x81, 0xc8}, bytes.Repeat([]byte{0xaa}, 200)...)
+	ui, err := NewUserInformation(NewExternal("0.4.0.0.1.1.1.1", data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := NewBeginInvoke(1, 1, 59, []byte{0x04, 0x01, 0x0f})
+	long.Dialogue = NewDialogue(DialogueAsID, 1, NewAARQ(1, NetworkUnstructuredSsContext, 2, ui), nil)
+	long.SetLength()
+
+	for _, c := range []struct {
+		description string
+		structured  *TCAP
+		serialized  string
+	}{
+		{
+			"Unidirectional - AUDT - Invoke",
+			NewUnidirectionalInvokeWithDialogue(NetworkUnstructuredSsContext, 2, 1, 61, []byte{0x04, 0x01, 0x0f}),
+			"612d" +
+				"6b1e281c060700118605010201a011600f80020780a109060704000001001302" +
+				"6c0ba10902010102013d04010f",
+		}, {
+			"Begin - AARQ with long user information - Invoke",
+			long,
+			"62820114480400000001" +
+				// Dialogue Portion, EXTERNAL and single-ASN1-type in the long form.
+				"6b81fe2881fb060700118605010101a081ef" +
+				"6081ec80020780a109060704000001001302" +
+				"be81da2881d7060704000001010101a081cb0481c8" + strings.Repeat("aa", 200) +
+				"6c0ba10902010102013b04010f",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			want := mustHexString(c.serialized)
+
+			b, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("MarshalBinary:\ngot  %x\nwant %x", b, want)
+			}
+			if got := c.structured.MarshalLen(); got != len(want) {
+				t.Errorf("MarshalLen: got %d, want %d", got, len(want))
+			}
+
+			parsed, err := Parse(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := parsed.Validate(); err != nil {
+				t.Error(err)
+			}
+			if got, want := parsed.Dialogue.isUnidialogue(), c.structured.Dialogue.isUnidialogue(); got != want {
+				t.Errorf("unidialogue: got %v, want %v", got, want)
+			}
+			exts, err := parsed.Dialogue.DialoguePDU.Externals()
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantExts, _ := c.structured.Dialogue.DialoguePDU.Externals()
+			if len(exts) != len(wantExts) || len(exts) != 0 && !bytes.Equal(exts[0].Data, wantExts[0].Data) {
+				t.Errorf("Externals: got %v, want %v", exts, wantExts)
+			}
+
+			b, err = parsed.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("re-encoded:\ngot  %x\nwant %x", b, want)
+			}
+			if got := parsed.MarshalLen(); got != len(want) {
+				t.Errorf("re-encoded MarshalLen: got %d, want %d", got, len(want))
+			}
+		})
+	}
+}
+
+func TestDialoguePDUUnknownElements(t *testing.T) {
+	// The elements not in Q.773, e.g. the security context and confidentiality
+	// that some implementations put in AARQ, are kept in Unknown.
+	serialized := "601c80020780a109060704000001001302" +
+		"86032a0304" +
+		"a703800105" +
+		"8901ff"
+
+	structured := NewAARQ(1, NetworkUnstructuredSsContext, 2)
+	structured.Unknown = []*IE{
+		NewIE(NewContextSpecificPrimitiveTag(6), []byte{0x2a, 0x03, 0x04}),
+		NewIE(NewContextSpecificConstructorTag(7), []byte{0x80, 0x01, 0x05}),
+		NewIE(NewContextSpecificPrimitiveTag(9), []byte{0xff}),
+	}
+	structured.SetLength()
+
+	b, err := structured.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b, mustHexString(serialized); !bytes.Equal(got, want) {
+		t.Errorf("MarshalBinary: got %x, want %x", got, want)
+	}
+
+	d, err := ParseDialoguePDU(mustHexString(serialized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(d.Unknown); got != 3 {
+		t.Fatalf("Unknown: got %d elements, want 3", got)
+	}
+
+	b, err = d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b, mustHexString(serialized); !bytes.Equal(got, want) {
+		t.Errorf("re-encoded: got %x, want %x", got, want)
+	}
+	if got, want := d.MarshalLen(), len(b); got != want {
+		t.Errorf("MarshalLen: got %d, want %d", got, want)
+	}
+
+	j, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON := &DialoguePDU{}
+	if err := fromJSON.UnmarshalJSON(j); err != nil {
+		t.Fatal(err)
+	}
+	b, err = fromJSON.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b, mustHexString(serialized); !bytes.Equal(got, want) {
+		t.Errorf("from JSON: got %x, want %x", got, want)
+	}
+}
+
+func TestParseBERMalformedDialogue(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		serialized  string
+	}{
+		{"no single-ASN.1-type", "620d4804000000016b052803060100"},
+		{"empty single-ASN.1-type", "620f4804000000016b072805060100a000"},
+		{"unknown DialoguePDU", "62114804000000016b092807060100a0026200"},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := ParseBER(mustHexString(c.serialized)); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The methods below edit a TCAP in place, e.g. when forwarding it in a proxy.
+//
+// They keep the rest of the message as it is. The fields are modified in place
+// where possible so that the unknown elements stay where they were, and the
+// Length fields of the edited elements are updated. The lengths of the portions
+// containing them are computed when encoding anyway. With WithOriginalBytes, the
+// portions not edited are put as the original bytes without being re-encoded.
+
+// SetOTID sets the Originating Transaction ID, in 4 octets.
+//
+// It returns EditError if the message type does not have one, i.e. other than
+// Begin and Continue.
+func (t *TCAP) SetOTID(otid uint32) error {
+	tx := t.Transaction
+	if tx == nil {
+		return &EditError{Reason: "no Transaction Portion"}
+	}
+	switch tx.Type.Code() {
+	case Begin, Continue:
+	default:
+		return &EditError{Reason: fmt.Sprintf("%s has no OTID", tx.MessageTypeString())}
+	}
+
+	tx.OrigTransactionID = setTID(tx.OrigTransactionID, NewApplicationWidePrimitiveTag(8), otid)
+	return nil
+}
+
+// SetDTID sets the Destination Transaction ID, in 4 octets.
+//
+// It returns EditError if the message type does not have one, i.e. other than
+// End, Continue and Abort.
+func (t *TCAP) SetDTID(dtid uint32) error {
+	tx := t.Transaction
+	if tx == nil {
+		return &EditError{Reason: "no Transaction Portion"}
+	}
+	switch tx.Type.Code() {
+	case End, Continue, Abort:
+	default:
+		return &EditError{Reason: fmt.Sprintf("%s has no DTID", tx.MessageTypeString())}
+	}
+
+	tx.DestTransactionID = setTID(tx.DestTransactionID, NewApplicationWidePrimitiveTag(9), dtid)
+	return nil
+}
+
+// setTID sets id in the transaction ID field, or returns a new one if field is nil.
+func setTID(field *IE, tag Tag, id uint32) *IE {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint32(v, id)
+	if field == nil {
+		return NewIE(tag, v)
+	}
+	field.Value = v
+	field.SetLength()
+	return field
+}
+
+// SetApplicationContext sets the Application Context Name of the AARQ, AARE or
+// AUDT in the Dialogue Portion, in the same form as NewApplicationContextName.
+//
+// It returns EditError if the message has no DialoguePDU that can have one.
+func (t *TCAP) SetApplicationContext(ctx, ver uint8) error {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil {
+		return &EditError{Reason: "no DialoguePDU"}
+	}
+	pdu := d.DialoguePDU
+	switch pdu.Type.Code() {
+	case AARQ, AARE:
+	default:
+		return &EditError{Reason: fmt.Sprintf("%s has no application context name", pdu.DialogueType())}
+	}
+
+	acn := NewApplicationContextName(ctx, ver)
+	if field := pdu.ApplicationContextName; field != nil {
+		field.Value, field.IE = acn.Value, nil
+		field.SetLength()
+	} else {
+		pdu.ApplicationContextName = acn
+	}
+
+	pdu.SetLength()
+	if d.SingleAsn1Type != nil {
+		d.SingleAsn1Type.Length = uint8(pdu.MarshalLen())
+	}
+	return nil
+}
+
+// ReplaceComponent replaces the i-th Component in the Component Portion with c.
+//
+// It returns EditError if there is no i-th Component.
+func (t *TCAP) ReplaceComponent(i int, c *Component) error {
+	if err := t.checkComponent(i); err != nil {
+		return err
+	}
+
+	c.SetLength()
+	t.Components.Component[i] = c
+	t.Components.SetLength()
+	return nil
+}
+
+// RemoveComponent removes the i-th Component in the Component Portion. The
+// Component Portion itself is removed when the last Component is removed.
+//
+// It returns EditError if there is no i-th Component.
+func (t *TCAP) RemoveComponent(i int) error {
+	if err := t.checkComponent(i); err != nil {
+		return err
+	}
+
+	comps := t.Components
+	comps.Component = append(comps.Component[:i], comps.Component[i+1:]...)
+	if len(comps.Component) != 0 {
+		comps.SetLength()
+		return nil
+	}
+
+	// The Payloads of the parsed message have the Component Portion in them,
+	// which would be put instead when it is nil.
+	t.Components = nil
+	if d := t.Dialogue; d != nil {
+		d.Payload = nil
+	}
+	if tx := t.Transaction; tx != nil {
+		tx.Payload = nil
+	}
+	return nil
+}
+
+// WithParameter replaces the contents of the Parameter in the i-th Component
+// with the ones returned by f, which is given the current contents, or nil if
+// the Component has no Parameter. The Parameter is removed if f returns nil.
+//
+// It returns the error returned by f, or EditError if there is no i-th Component
+// or it cannot have a Parameter.
+func (t *TCAP) WithParameter(i int, f func(param []byte) ([]byte, error)) error {
+	if err := t.checkComponent(i); err != nil {
+		return err
+	}
+	c := t.Components.Component[i]
+	switch c.Type.Code() {
+	case Invoke, ReturnError:
+	case ReturnResultLast, ReturnResultNotLast:
+		if c.ResultRetres == nil {
+			return &EditError{Reason: fmt.Sprintf("Component[%d] has no result to put a Parameter in", i)}
+		}
+	default:
+		return &EditError{Reason: fmt.Sprintf("%s has no Parameter", c.ComponentTypeString())}
+	}
+
+	var cur []byte
+	if c.Parameter != nil {
+		cur = c.Parameter.Value
+	}
+	v, err := f(cur)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v == nil:
+		c.Parameter = nil
+	case c.Parameter == nil:
+		c.Parameter = NewIE(NewUniversalConstructorTag(0x10), v)
+	default:
+		c.Parameter.Value = v
+	}
+	if c.Parameter != nil {
+		c.Parameter.IE, _ = ParseMultiIEs(v)
+	}
+
+	c.SetLength()
+	t.Components.SetLength()
+	return nil
+}
+
+// checkComponent returns EditError if there is no i-th Component.
+func (t *TCAP) checkComponent(i int) error {
+	if t.Components == nil || i < 0 || i >= len(t.Components.Component) {
+		return &EditError{Reason: fmt.Sprintf("no Component[%d]", i)}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package tcap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSetTID(t *testing.T) {
+	for _, c := range []struct {
+		description      string
+		tcap             *TCAP
+		otidOK, dtidOK   bool
+		wantOTID, wantDT uint32
+	}{
+		{
+			"Begin",
+			NewBeginInvoke(0x01, 1, 2, []byte{0x30, 0x03, 0x80, 0x01, 0x05}),
+			true, false, 0x11223344, 0,
+		}, {
+			"Continue",
+			NewContinueInvoke(0x01, 0x02, 1, 2, []byte{0x30, 0x03, 0x80, 0x01, 0x05}),
+			true, true, 0x11223344, 0x55667788,
+		}, {
+			"End",
+			NewEndReturnResult(0x02, 1, 2, true, []byte{0x30, 0x03, 0x80, 0x01, 0x05}),
+			false, true, 0, 0x55667788,
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			var eerr *EditError
+			if err := c.tcap.SetOTID(0x11223344); c.otidOK && err != nil {
+				t.Fatal(err)
+			} else if !c.otidOK && !errors.As(err, &eerr) {
+				t.Errorf("SetOTID: got %v, want EditError", err)
+			}
+			if err := c.tcap.SetDTID(0x55667788); c.dtidOK && err != nil {
+				t.Fatal(err)
+			} else if !c.dtidOK && !errors.As(err, &eerr) {
+				t.Errorf("SetDTID: got %v, want EditError", err)
+			}
+
+			b, err := c.tcap.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parsed.OTID(); got != c.wantOTID {
+				t.Errorf("OTID: got %#x, want %#x", got, c.wantOTID)
+			}
+			if got := parsed.DTID(); got != c.wantDT {
+				t.Errorf("DTID: got %#x, want %#x", got, c.wantDT)
+			}
+			if got := parsed.Components.Component[0].Parameter.Value; !bytes.Equal(got, []byte{0x80, 0x01, 0x05}) {
+				t.Errorf("Parameter: got %x", got)
+			}
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	// Begin with the long form of the length of the Dialogue Portion.
+	const begin = "62314804000000016b811e281c060700118605010101a011600f80020780a1090607040000010013026c08a106020101020102"
+
+	for _, c := range []struct {
+		description string
+		edit        func(*TCAP) error
+		serialized  string
+	}{
+		{
+			"SetOTID",
+			func(t *TCAP) error { return t.SetOTID(2) },
+			"62314804000000026b811e281c060700118605010101a011600f80020780a1090607040000010013026c08a106020101020102",
+		}, {
+			"SetApplicationContext",
+			func(t *TCAP) error { return t.SetApplicationContext(0x13, 3) },
+			"62304804000000016b1e281c060700118605010101a011600f80020780a1090607040000010013036c08a106020101020102",
+		}, {
+			"RemoveComponent",
+			func(t *TCAP) error { return t.RemoveComponent(0) },
+			"62274804000000016b811e281c060700118605010101a011600f80020780a109060704000001001302",
+		}, {
+			"ReplaceComponent",
+			func(t *TCAP) error {
+				return t.ReplaceComponent(0, NewInvoke(1, -1, 2, true, []byte{0x30, 0x03, 0x80, 0x01, 0x05}))
+			},
+			"62364804000000016b811e281c060700118605010101a011600f80020780a1090607040000010013026c0da10b0201010201023003800105",
+		}, {
+			"WithParameter",
+			func(t *TCAP) error {
+				return t.WithParameter(0, func(param []byte) ([]byte, error) {
+					if param != nil {
+						return nil, errors.New("unexpected Parameter")
+					}
+					return []byte{0x80, 0x01, 0x05}, nil
+				})
+			},
+			"62364804000000016b811e281c060700118605010101a011600f80020780a1090607040000010013026c0da10b0201010201023003800105",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			tc, err := Parse(mustHexString(begin), WithOriginalBytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.edit(tc); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tc.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := mustHexString(c.serialized)
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %x, want %x", got, want)
+			}
+			if l := tc.MarshalLen(); l != len(want) {
+				t.Errorf("MarshalLen: got %d, want %d", l, len(want))
+			}
+		})
+	}
+}
+
+func TestEditError(t *testing.T) {
+	tc := NewBeginInvoke(1, 1, 2, nil)
+	for _, c := range []struct {
+		description string
+		edit        func() error
+	}{
+		{"SetDTID of Begin", func() error { return tc.SetDTID(1) }},
+		{"SetApplicationContext without Dialogue", func() error { return tc.SetApplicationContext(0x13, 2) }},
+		{"RemoveComponent out of range", func() error { return tc.RemoveComponent(1) }},
+		{"ReplaceComponent out of range", func() error { return tc.ReplaceComponent(-1, NewInvoke(1, -1, 2, true, nil)) }},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			var eerr *EditError
+			if err := c.edit(); !errors.As(err, &eerr) {
+				t.Errorf("got %v, want EditError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+var appendBinaryCases = []struct {
+	name string
+	hex  string
+}{
+	{
+		name: "Begin - AARQ with user information - Invoke / USSD short length",
+		hex:  "62664804000000026b3f283d060700118605010101a032603080020780a109060704000001001302be1f281d060704000001010101a012a01080069121436587f981069121436587f96c1da11b02010002013b301304010f0404f4f29c0e800891111111111111f1",
+	}, {
+		name: "Begin - AARQ with user information - Invoke / USSD long length",
+		hex:  "6281f248040000000a6b3f283d060700118605010101a032603080020780a109060704000001001302be1f281d060704000001010101a012a01080069121436587f981069121436587f96c81a8a181a502010002013b30819c04010f04818c5474d8bd06e5df7590f92d07d5e769f71944479741c7373bec3e83aad32911342fcbed65b90b94a683d27310b96c2fb3dff0321904afcbcbec3ce8ed069ddfecb0fb0c0abbc9a0341d549f97e7a0e9700865819ab36a90059a0ea95016283875b24043e194053a4e9b3750d84d0625a955d09c1e7693c372f2dc0542bee16550fe5d0795ddea771e4447bbf1800891111111111111f1",
+	},
+}
+
+func TestAppendBinary(t *testing.T) {
+	for _, c := range appendBinaryCases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := hex.DecodeString(c.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prefix := []byte{0xde, 0xad}
+			got, err := parsed.AppendBinary(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got[:2], prefix) {
+				t.Errorf("prefix overwritten: got %x", got[:2])
+			}
+			if !bytes.Equal(got[2:], b) {
+				t.Errorf("got %x, want %x", got[2:], b)
+			}
+		})
+	}
+}
+
+func TestAppendBinaryLongLength(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xaa}, 300)
+	ie := NewIE(NewUniversalPrimitiveTag(4), payload)
+
+	got, err := ie.AppendBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x04, 0x82, 0x01, 0x2c}; !bytes.Equal(got[:4], want) {
+		t.Errorf("got header %x, want %x", got[:4], want)
+	}
+
+	c := &Components{Tag: NewApplicationWideConstructorTag(12)}
+	c.Component = append(c.Component, &Component{
+		Type:          NewContextSpecificConstructorTag(Invoke),
+		InvokeID:      NewIE(NewUniversalPrimitiveTag(2), []byte{1}),
+		OperationCode: NewOperationCode(59, true),
+		Parameter:     ie,
+	})
+	got, err = c.AppendBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte{
+		0x6c, 0x82, 0x01, 0x3a, 0xa1, 0x82, 0x01, 0x36, 0x02, 0x01, 0x01, 0x02, 0x01, 0x3b,
+		0x04, 0x82, 0x01, 0x2c,
+	}, payload...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestMarshalLenLongLength(t *testing.T) {
+	param := append([]byte{0x04, 0x82, 0x01, 0x2c}, bytes.Repeat([]byte{0xaa}, 300)...)
+	tc := NewContinueInvoke(0x11111111, 0x22222222, 1, 61, param)
+	tc.SetLength()
+
+	want, err := tc.AppendBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tc.MarshalLen(); got != len(want) {
+		t.Errorf("MarshalLen: got %d, want %d", got, len(want))
+	}
+	b := make([]byte, tc.MarshalLen())
+	if err := tc.MarshalTo(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got %x, want %x", b, want)
+	}
+
+	tx := NewContinue(0x11111111, 0x22222222, param)
+	tx.SetLength()
+	if want, _ := tx.AppendBinary(nil); tx.MarshalLen() != len(want) {
+		t.Errorf("Transaction.MarshalLen: got %d, want %d", tx.MarshalLen(), len(want))
+	}
+	b = make([]byte, tx.MarshalLen())
+	if err := tx.MarshalTo(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Length field does not count without the Value, as in AppendBinary.
+	ie := &IE{Tag: NewUniversalPrimitiveTag(4), Length: 200}
+	if b, _ := ie.AppendBinary(nil); ie.MarshalLen() != len(b) {
+		t.Errorf("IE.MarshalLen: got %d, want %d", ie.MarshalLen(), len(b))
+	}
+}
+
+func TestMarshalToShortBuffer(t *testing.T) {
+	tc := NewContinueInvoke(0x11111111, 0x22222222, 1, 61, []byte{0x30, 0x03, 0x04, 0x01, 0x0f})
+
+	b := make([]byte, 8, 64)
+	if err := tc.MarshalTo(b); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if got := b[:cap(b)][8:]; !bytes.Equal(got, make([]byte, len(got))) {
+		t.Errorf("wrote beyond the given buffer: %x", got)
+	}
+}
+
+var benchmarkCases = []struct {
+	name string
+	tcap *TCAP
+}{
+	{
+		name: "Begin",
+		tcap: NewBeginInvokeWithDialogue(
+			0x11111111, DialogueAsID, LocationCancellationContext, 3, 0, 3,
+			[]byte{0x30, 0x0a, 0x04, 0x08, 0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xf9},
+		),
+	}, {
+		name: "Continue",
+		tcap: NewContinueInvoke(
+			0x11111111, 0x22222222, 1, 61,
+			[]byte{0x30, 0x0e, 0x04, 0x01, 0x0f, 0x04, 0x09, 0xaa, 0x1b, 0x2e, 0x47, 0xab, 0xd9, 0x46, 0xaa, 0x11},
+		),
+	}, {
+		name: "End",
+		tcap: NewEndReturnResultWithDialogue(
+			0x11111111, DialogueAsID, AnyTimeInfoEnquiryContext, 3, 1, 71, true,
+			[]byte{0x30, 0x04, 0xde, 0xad, 0xbe, 0xef},
+		),
+	},
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	for _, c := range benchmarkCases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.tcap.MarshalBinary(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAppendBinary(b *testing.B) {
+	for _, c := range benchmarkCases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf := GetBuffer()
+				var err error
+				if buf.B, err = c.tcap.AppendBinary(buf.B); err != nil {
+					b.Fatal(err)
+				}
+				PutBuffer(buf)
+			}
+		})
+	}
+}
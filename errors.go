@@ -4,7 +4,16 @@
 
 package tcap
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// ErrInvalidLength indicates that the length of an element is in the indefinite form,
+// or too long to be handled.
+var ErrInvalidLength = ber.ErrInvalidLength
 
 // InvalidCodeError indicates that Code in TCAP message is invalid.
 type InvalidCodeError struct {
@@ -15,3 +24,76 @@ type InvalidCodeError struct {
 func (e *InvalidCodeError) Error() string {
 	return fmt.Sprintf("tcap: got invalid code: %d", e.Code)
 }
+
+// Violation is a single structural rule of Q.773 that a message violates.
+type Violation struct {
+	// Portion is the name of the portion or element that violates the rule,
+	// e.g. "Transaction" or "Component[0]".
+	Portion string
+	// Reason describes the violation.
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (v *Violation) Error() string {
+	return v.Portion + ": " + v.Reason
+}
+
+// ValidationError indicates that a message violates one or more structural rules.
+type ValidationError struct {
+	Violations []*Violation
+}
+
+// Error returns error message with all the violations.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	return fmt.Sprintf("tcap: got %d violation(s): %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// InvalidNameError indicates that a name in the semantic representation of a
+// message is unknown or malformed.
+type InvalidNameError struct {
+	// Field is the name of the field the name is given in, e.g. "type".
+	Field string
+	Name  string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("tcap: got invalid %s: %q", e.Field, e.Name)
+}
+
+// InvalidExternalError indicates that an EXTERNAL in the user information cannot
+// be encoded or decoded.
+type InvalidExternalError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidExternalError) Error() string {
+	return "tcap: got invalid EXTERNAL: " + e.Reason
+}
+
+// EditError indicates that a TCAP cannot be edited as requested, e.g. setting
+// the OTID of End.
+type EditError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *EditError) Error() string {
+	return "tcap: cannot edit: " + e.Reason
+}
+
+// SegmentError indicates that a result cannot be segmented or reassembled.
+type SegmentError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *SegmentError) Error() string {
+	return "tcap: cannot segment result: " + e.Reason
+}
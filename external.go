@@ -0,0 +1,204 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Encoding definitions of EXTERNAL, which are the tags of the encoding CHOICE.
+const (
+	SingleASN1Type = iota
+	OctetAligned
+	Arbitrary
+)
+
+// External is an EXTERNAL in X.690, which is carried in the user information of
+// the DialoguePDUs, e.g. MAP-OpenInfo in MAP with "0.4.0.0.1.1.1.1" as the
+// DirectReference.
+type External struct {
+	// DirectReference is the OBJECT IDENTIFIER in the dotted notation, which
+	// is empty if not present.
+	DirectReference string
+	// IndirectReference is nil if not present.
+	IndirectReference *int
+	// DataValueDescriptor is empty if not present.
+	DataValueDescriptor string
+	// Encoding is SingleASN1Type, OctetAligned or Arbitrary.
+	Encoding int
+	// Data is the whole element of the type with SingleASN1Type, the octets
+	// with OctetAligned, or the contents of the BIT STRING including the
+	// number of the unused bits with Arbitrary.
+	Data []byte
+}
+
+// NewExternal creates an External with the single ASN.1 type of the whole
+// element data, identified by the OBJECT IDENTIFIER in the dotted notation.
+func NewExternal(oid string, data []byte) *External {
+	return &External{DirectReference: oid, Encoding: SingleASN1Type, Data: data}
+}
+
+// ParseExternal parses given byte sequence as an External.
+func ParseExternal(b []byte) (*External, error) {
+	e := &External{}
+	if err := e.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// MarshalBinary returns the byte sequence generated from an External.
+func (e *External) MarshalBinary() ([]byte, error) {
+	return e.AppendBinary(nil)
+}
+
+// AppendBinary appends the byte sequence generated from an External to b.
+func (e *External) AppendBinary(b []byte) ([]byte, error) {
+	var v []byte
+	if e.DirectReference != "" {
+		oid, err := ber.EncodeOID(e.DirectReference)
+		if err != nil {
+			return nil, &InvalidExternalError{Reason: fmt.Sprintf("invalid direct-reference: %q", e.DirectReference)}
+		}
+		v = ber.AppendElement(v, ber.ClassUniversal, false, 6, oid)
+	}
+	if e.IndirectReference != nil {
+		v = ber.AppendElement(v, ber.ClassUniversal, false, 2, ber.EncodeInteger(*e.IndirectReference))
+	}
+	if e.DataValueDescriptor != "" {
+		v = ber.AppendElement(v, ber.ClassUniversal, false, 7, []byte(e.DataValueDescriptor))
+	}
+
+	switch e.Encoding {
+	case SingleASN1Type:
+		if _, n, err := ber.ReadElement(e.Data); err != nil || n != len(e.Data) {
+			return nil, &InvalidExternalError{Reason: fmt.Sprintf("single-ASN1-type is not a single element: %x", e.Data)}
+		}
+		v = ber.AppendElement(v, ber.ClassContextSpecific, true, SingleASN1Type, e.Data)
+	case OctetAligned, Arbitrary:
+		v = ber.AppendElement(v, ber.ClassContextSpecific, false, e.Encoding, e.Data)
+	default:
+		return nil, &InvalidExternalError{Reason: fmt.Sprintf("unknown encoding: %d", e.Encoding)}
+	}
+	return ber.AppendElement(b, ber.ClassUniversal, true, 8, v), nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an External.
+func (e *External) UnmarshalBinary(b []byte) error {
+	el, n, err := ber.ReadElement(b)
+	if err != nil {
+		return err
+	}
+	if n != len(b) {
+		return &InvalidExternalError{Reason: fmt.Sprintf("%d trailing byte(s)", len(b)-n)}
+	}
+	if !el.Is(ber.ClassUniversal, 8) || !el.Constructed {
+		return &InvalidExternalError{Reason: fmt.Sprintf("unexpected tag: %x", el.Raw[0])}
+	}
+	es, err := ber.ReadElements(el.Value)
+	if err != nil {
+		return err
+	}
+
+	*e = External{}
+	for len(es) != 0 && es[0].Class == ber.ClassUniversal {
+		switch es[0].Tag {
+		case 6:
+			if e.DirectReference, err = ber.FormatOID(es[0].Value); err != nil {
+				return err
+			}
+		case 2:
+			v := ber.DecodeInteger(es[0].Value)
+			e.IndirectReference = &v
+		case 7:
+			e.DataValueDescriptor = string(es[0].Value)
+		default:
+			return &InvalidExternalError{Reason: fmt.Sprintf("unexpected element: %x", es[0].Raw)}
+		}
+		es = es[1:]
+	}
+
+	if len(es) != 1 || es[0].Class != ber.ClassContextSpecific {
+		return &InvalidExternalError{Reason: "missing encoding"}
+	}
+	switch enc := es[0]; enc.Tag {
+	case SingleASN1Type:
+		e.Encoding, e.Data = SingleASN1Type, append([]byte{}, enc.Value...)
+	case OctetAligned, Arbitrary:
+		e.Encoding, e.Data = enc.Tag, append([]byte{}, enc.Value...)
+	default:
+		return &InvalidExternalError{Reason: fmt.Sprintf("unknown encoding: %d", enc.Tag)}
+	}
+	return nil
+}
+
+// String returns External in human readable string.
+func (e *External) String() string {
+	return fmt.Sprintf("{DirectReference: %s, Encoding: %d, Data: %x}", e.DirectReference, e.Encoding, e.Data)
+}
+
+// NewUserInformation creates the user information of the DialoguePDUs with
+// the Externals, which can be given to NewAARQ, NewAARE and NewABRT as it is.
+func NewUserInformation(exts ...*External) (*IE, error) {
+	var v []byte
+	for _, e := range exts {
+		var err error
+		if v, err = e.AppendBinary(v); err != nil {
+			return nil, err
+		}
+	}
+
+	ie := &IE{Tag: NewContextSpecificConstructorTag(30), Value: v}
+	ie.SetLength()
+	return ie, nil
+}
+
+// ParseUserInformation parses the contents of the user information as the
+// sequence of Externals.
+func ParseUserInformation(b []byte) ([]*External, error) {
+	var exts []*External
+	for len(b) != 0 {
+		_, n, err := ber.ReadElement(b)
+		if err != nil {
+			return nil, err
+		}
+		e, err := ParseExternal(b[:n])
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, e)
+		b = b[n:]
+	}
+	return exts, nil
+}
+
+// Externals returns the Externals in the user information, or nil if d does
+// not have it.
+func (d *DialoguePDU) Externals() ([]*External, error) {
+	if d.UserInformation == nil {
+		return nil, nil
+	}
+	return ParseUserInformation(d.UserInformation.Value)
+}
+
+// SetUserInformation sets the user information with the Externals, or removes
+// it if none is given.
+func (d *DialoguePDU) SetUserInformation(exts ...*External) error {
+	if len(exts) == 0 {
+		d.UserInformation = nil
+		d.SetLength()
+		return nil
+	}
+
+	ui, err := NewUserInformation(exts...)
+	if err != nil {
+		return err
+	}
+	d.UserInformation = ui
+	d.SetLength()
+	return nil
+}
@@ -0,0 +1,145 @@
+package tcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func TestExternal(t *testing.T) {
+	ref := 3
+	for _, c := range []struct {
+		description string
+		structured  *External
+		serialized  string
+	}{
+		{
+			"MAP-OpenInfo",
+			NewExternal("0.4.0.0.1.1.1.1", mustHexString("a01080069121436587f981069121436587f9")),
+			"281d060704000001010101a012a01080069121436587f981069121436587f9",
+		}, {
+			"octet-aligned",
+			&External{IndirectReference: &ref, DataValueDescriptor: "vendor", Encoding: OctetAligned, Data: []byte{1, 2, 3}},
+			"2810020103070676656e646f728103010203",
+		}, {
+			"arbitrary",
+			&External{DirectReference: "1.2.3", Encoding: Arbitrary, Data: []byte{0x04, 0xf0}},
+			"280806022a03820204f0",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHexString(c.serialized)
+
+			got, err := ParseExternal(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verify.Values(t, "", got, c.structured)
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, b) {
+				t.Errorf("got %x, want %x", encoded, b)
+			}
+		})
+	}
+
+	for _, s := range []string{
+		"2800",              // missing encoding
+		"2803830100",        // unknown encoding
+		"2803810100" + "00", // trailing bytes
+		"3003810100",        // not EXTERNAL
+		"280604012a810100",  // unexpected universal element
+	} {
+		if _, err := ParseExternal(mustHexString(s)); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+	if _, err := NewExternal("0.4.0", []byte{0x01}).MarshalBinary(); err == nil {
+		t.Error("expected error with single-ASN1-type not a single element")
+	}
+}
+
+func TestUserInformation(t *testing.T) {
+	exts := []*External{
+		NewExternal("0.4.0.0.1.1.1.1", mustHexString("a01080069121436587f981069121436587f9")),
+		{DirectReference: "1.2.3", Encoding: OctetAligned, Data: []byte{0xff}},
+	}
+
+	for _, pdu := range []*DialoguePDU{
+		NewAARQ(1, NetworkUnstructuredSsContext, 2),
+		NewABRT(0),
+	} {
+		if err := pdu.SetUserInformation(exts...); err != nil {
+			t.Fatal(err)
+		}
+		msg := NewBeginInvoke(1, 0, 59, []byte{0x04, 0x01, 0x0f})
+		msg.Dialogue = NewDialogue(DialogueAsID, 1, pdu, nil)
+		msg.SetLength()
+
+		b, err := msg.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parsed.Dialogue.DialoguePDU.Externals()
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, pdu.DialogueType(), got, exts)
+
+		bers, err := ParseBER(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := bers[0].Dialogue.DialoguePDU.Externals(); err != nil || len(got) != len(exts) {
+			t.Errorf("%s: got %v, %v with ParseBER", pdu.DialogueType(), got, err)
+		}
+	}
+
+	ui, err := NewUserInformation(exts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdu := NewAARE(1, NetworkUnstructuredSsContext, 2, Accepted, DialogueServiceUser, Null, ui)
+	if got, err := pdu.Externals(); err != nil || len(got) != 1 {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	if err := pdu.SetUserInformation(); err != nil || pdu.UserInformation != nil {
+		t.Errorf("got %v, %v", pdu.UserInformation, err)
+	}
+
+	// The user information given in more than one are joined.
+	ui2, err := NewUserInformation(exts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pdu := range []*DialoguePDU{
+		NewDialoguePDU(AARQ, 1, NetworkUnstructuredSsContext, 2, 0, 0, 0, 0, ui, ui2),
+		NewAARQ(1, NetworkUnstructuredSsContext, 2, ui, ui2),
+		NewAARE(1, NetworkUnstructuredSsContext, 2, Accepted, DialogueServiceUser, Null, ui, ui2),
+		NewABRT(0, ui, ui2),
+	} {
+		got, err := pdu.Externals()
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify.Values(t, pdu.DialogueType(), got, exts)
+	}
+}
+
+func mustHexString(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
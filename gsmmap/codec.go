@@ -0,0 +1,135 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// Tag classes and the universal tag numbers used in the Parameters.
+const (
+	univ = codec.Universal
+	ctx  = codec.ContextSpecific
+
+	tagBoolean     = 1
+	tagInteger     = 2
+	tagOctetString = 4
+	tagNull        = 5
+	tagOID         = 6
+	tagEnumerated  = 10
+	tagSequence    = 16
+)
+
+// encoder is the encoder of the Parameters, which is shared with cap package.
+type encoder = codec.Encoder
+
+// dec reads the Parameters, reporting the invalid ones in InvalidParameterError.
+var dec = &codec.Decoder{Invalid: func(reason string) error {
+	return &InvalidParameterError{Reason: reason}
+}}
+
+// address appends a if not nil.
+func address(e *encoder, class, tag int, a *AddressString) {
+	if a != nil {
+		e.Value(class, tag, a)
+	}
+}
+
+// Nature of Address Indicator definitions.
+const (
+	NatureUnknown         = param.NatureUnknown
+	NatureInternational   = param.NatureInternational
+	NatureNational        = param.NatureNational
+	NatureNetworkSpecific = param.NatureNetworkSpecific
+	NatureSubscriber      = param.NatureSubscriber
+	NatureAbbreviated     = param.NatureAbbreviated
+)
+
+// Numbering Plan Indicator definitions.
+const (
+	PlanUnknown    = param.PlanUnknown
+	PlanISDN       = param.PlanISDN
+	PlanData       = param.PlanData
+	PlanTelex      = param.PlanTelex
+	PlanLandMobile = param.PlanLandMobile
+	PlanNational   = param.PlanNational
+	PlanPrivate    = param.PlanPrivate
+)
+
+// AddressString is an AddressString or ISDN-AddressString.
+type AddressString = param.AddressString
+
+// NewISDNAddress creates an AddressString of the international number in ISDN
+// numbering plan, e.g. MSISDN and the number of the network nodes.
+func NewISDNAddress(digits string) *AddressString {
+	return param.NewISDNAddress(digits)
+}
+
+func decodeAddressString(e *ber.Element) (*AddressString, error) {
+	a := &AddressString{}
+	if err := a.Decode(e.Value); err != nil {
+		return nil, dec.Unexpected(e)
+	}
+	return a, nil
+}
+
+// IMSI is the IMSI as the argument of sendAuthenticationInfo v2.
+type IMSI = param.IMSI
+
+// Identity is the IMSI, or the IMSI with LMSI if LMSI is not nil, as the
+// argument of cancelLocation v1/v2.
+type Identity struct {
+	IMSI string
+	LMSI []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an Identity.
+func (i *Identity) MarshalBinary() ([]byte, error) {
+	e := &encoder{}
+	i.encode(e)
+	return e.Bytes()
+}
+
+func (i *Identity) encode(e *encoder) {
+	if i.LMSI == nil {
+		e.TBCD(univ, tagOctetString, i.IMSI)
+		return
+	}
+	e.Constructed(univ, tagSequence, func(e *encoder) {
+		e.TBCD(univ, tagOctetString, i.IMSI)
+		e.Octets(univ, tagOctetString, i.LMSI)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an Identity.
+func (i *Identity) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+	return i.decode(e)
+}
+
+func (i *Identity) decode(e *ber.Element) error {
+	if e.Is(univ, tagOctetString) {
+		i.IMSI, i.LMSI = param.DecodeTBCD(e.Value), nil
+		return nil
+	}
+	if !e.Is(univ, tagSequence) {
+		return dec.Unexpected(e)
+	}
+
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+	if len(es) < 2 || !es[0].Is(univ, tagOctetString) || !es[1].Is(univ, tagOctetString) {
+		return dec.Missing("imsi-WithLMSI")
+	}
+	i.IMSI, i.LMSI = param.DecodeTBCD(es[0].Value), codec.Clone(es[1].Value)
+	return nil
+}
@@ -0,0 +1,375 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// DialogueAS is the OBJECT IDENTIFIER of map-DialogueAS, which identifies the
+// MAP-DialoguePDU in the user information of the dialogue portion.
+const DialogueAS = "0.4.0.0.1.1.1.1"
+
+// MAP-DialoguePDU type definitions, which are the tags of the CHOICE.
+const (
+	MAPOpen = iota
+	MAPAccept
+	MAPClose
+	MAPRefuse
+	MAPUserAbort
+	MAPProviderAbort
+)
+
+// dialoguePDUs have the constructors of the MAP-DialoguePDUs by the type.
+var dialoguePDUs = map[int]func() Parameter{
+	MAPOpen:          func() Parameter { return &OpenInfo{} },
+	MAPAccept:        func() Parameter { return &AcceptInfo{} },
+	MAPClose:         func() Parameter { return &CloseInfo{} },
+	MAPRefuse:        func() Parameter { return &RefuseInfo{} },
+	MAPUserAbort:     func() Parameter { return &UserAbortInfo{} },
+	MAPProviderAbort: func() Parameter { return &ProviderAbortInfo{} },
+}
+
+// DecodeDialoguePDU decodes b as a MAP-DialoguePDU, which is one of OpenInfo,
+// AcceptInfo, CloseInfo, RefuseInfo, UserAbortInfo and ProviderAbortInfo.
+func DecodeDialoguePDU(b []byte) (Parameter, error) {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := dialoguePDUs[e.Tag]
+	if e.Class != ctx || !ok {
+		return nil, dec.Unexpected(e)
+	}
+
+	p := f()
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewDialogueExternal returns p in an External with DialogueAS, which is put in
+// the user information of the dialogue portion.
+func NewDialogueExternal(p Parameter) (*tcap.External, error) {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return tcap.NewExternal(DialogueAS, b), nil
+}
+
+// NewUserInformation returns the user information with p, which can be given
+// to tcap.NewAARQ, tcap.NewAARE and tcap.NewABRT as it is.
+//
+//	ui, err := gsmmap.NewUserInformation(&gsmmap.OpenInfo{
+//		DestinationReference: &gsmmap.AddressString{NatureOfAddress: gsmmap.NatureInternational, NumberingPlan: gsmmap.PlanLandMobile, Digits: imsi},
+//		OriginationReference: gsmmap.NewISDNAddress("8190123456"),
+//	})
+//	pdu := tcap.NewAARQ(1, tcap.NetworkLocUpContext, 3, ui)
+func NewUserInformation(p Parameter) (*tcap.IE, error) {
+	ext, err := NewDialogueExternal(p)
+	if err != nil {
+		return nil, err
+	}
+	return tcap.NewUserInformation(ext)
+}
+
+// DialogueInfo returns the MAP-DialoguePDU in the user information of the
+// dialogue portion of t, or nil without error if not present.
+func DialogueInfo(t *tcap.TCAP) (Parameter, error) {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil {
+		return nil, nil
+	}
+	exts, err := d.DialoguePDU.Externals()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range exts {
+		if ext.DirectReference != DialogueAS {
+			continue
+		}
+		if ext.Encoding != tcap.SingleASN1Type {
+			return nil, &InvalidParameterError{Reason: fmt.Sprintf("unexpected encoding of MAP-DialoguePDU: %d", ext.Encoding)}
+		}
+		return DecodeDialoguePDU(ext.Data)
+	}
+	return nil, nil
+}
+
+// OpenInfo is MAP-OpenInfo, which is sent in AARQ.
+type OpenInfo struct {
+	// DestinationReference and OriginationReference are nil if not present.
+	DestinationReference *AddressString
+	OriginationReference *AddressString
+	Extra                []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an OpenInfo.
+func (o *OpenInfo) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, MAPOpen, func(e *encoder) {
+		address(e, ctx, 0, o.DestinationReference)
+		address(e, ctx, 1, o.OriginationReference)
+		e.Raw(o.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an OpenInfo.
+func (o *OpenInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPOpen)
+	if err != nil {
+		return err
+	}
+
+	*o = OpenInfo{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			if o.DestinationReference, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 1):
+			if o.OriginationReference, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		default:
+			o.Extra = append(o.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// AcceptInfo is MAP-AcceptInfo, which is sent in AARE with the result accepted.
+type AcceptInfo struct {
+	Extra []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an AcceptInfo.
+func (a *AcceptInfo) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, MAPAccept, func(e *encoder) {
+		e.Raw(a.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AcceptInfo.
+func (a *AcceptInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPAccept)
+	if err != nil {
+		return err
+	}
+
+	*a = AcceptInfo{}
+	for _, e := range es {
+		a.Extra = append(a.Extra, e.Raw...)
+	}
+	return nil
+}
+
+// CloseInfo is MAP-CloseInfo, which is sent in AARE in End.
+type CloseInfo struct {
+	Extra []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a CloseInfo.
+func (c *CloseInfo) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, MAPClose, func(e *encoder) {
+		e.Raw(c.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CloseInfo.
+func (c *CloseInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPClose)
+	if err != nil {
+		return err
+	}
+
+	*c = CloseInfo{}
+	for _, e := range es {
+		c.Extra = append(c.Extra, e.Raw...)
+	}
+	return nil
+}
+
+// Reason definitions of RefuseInfo.
+const (
+	RefuseNoReasonGiven uint8 = iota
+	RefuseInvalidDestinationReference
+	RefuseInvalidOriginatingReference
+)
+
+// RefuseInfo is MAP-RefuseInfo, which is sent in AARE with the result
+// reject-permanent.
+type RefuseInfo struct {
+	Reason uint8
+	// AlternativeApplicationContext is the Application Context Name in the
+	// dotted notation, which is empty if not present.
+	AlternativeApplicationContext string
+	Extra                         []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a RefuseInfo.
+func (r *RefuseInfo) MarshalBinary() ([]byte, error) {
+	var oid []byte
+	if r.AlternativeApplicationContext != "" {
+		var err error
+		if oid, err = ber.EncodeOID(r.AlternativeApplicationContext); err != nil {
+			return nil, &InvalidParameterError{Reason: fmt.Sprintf("invalid alternativeApplicationContext: %q", r.AlternativeApplicationContext)}
+		}
+	}
+
+	return codec.Tagged(ctx, MAPRefuse, func(e *encoder) {
+		e.Octet(univ, tagEnumerated, r.Reason)
+		e.Raw(r.Extra)
+		e.Octets(univ, tagOID, oid)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RefuseInfo.
+func (r *RefuseInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPRefuse)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(univ, tagEnumerated) {
+		return dec.Missing("reason")
+	}
+
+	*r = RefuseInfo{}
+	if r.Reason, err = dec.Octet(es[0]); err != nil {
+		return err
+	}
+	for _, e := range es[1:] {
+		switch {
+		case e.Is(univ, tagOID):
+			if r.AlternativeApplicationContext, err = ber.FormatOID(e.Value); err != nil {
+				return dec.Unexpected(e)
+			}
+		default:
+			r.Extra = append(r.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// MAP-UserAbortChoice definitions.
+const (
+	UserSpecificReason = iota
+	UserResourceLimitation
+	ResourceUnavailable
+	ApplicationProcedureCancellation
+)
+
+// ResourceUnavailableReason definitions.
+const (
+	ShortTermResourceLimitation uint8 = iota
+	LongTermResourceLimitation
+)
+
+// ProcedureCancellationReason definitions.
+const (
+	HandoverCancellation uint8 = iota
+	RadioChannelRelease
+	NetworkPathRelease
+	CallRelease
+	AssociatedProcedureFailure
+	TandemDialogueRelease
+	RemoteOperationsFailure
+)
+
+// UserAbortInfo is MAP-UserAbortInfo, which is sent in ABRT by the user.
+type UserAbortInfo struct {
+	// Choice is one of the MAP-UserAbortChoice definitions.
+	Choice int
+	// Reason is ResourceUnavailableReason with ResourceUnavailable, or
+	// ProcedureCancellationReason with ApplicationProcedureCancellation. It is
+	// ignored with the others.
+	Reason uint8
+	Extra  []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a UserAbortInfo.
+func (u *UserAbortInfo) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, MAPUserAbort, func(e *encoder) {
+		switch u.Choice {
+		case UserSpecificReason, UserResourceLimitation:
+			e.Null(ctx, u.Choice, true)
+		case ResourceUnavailable, ApplicationProcedureCancellation:
+			e.Octet(ctx, u.Choice, u.Reason)
+		default:
+			e.SetError(&InvalidParameterError{Reason: fmt.Sprintf("unknown map-UserAbortChoice: %d", u.Choice)})
+		}
+		e.Raw(u.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a UserAbortInfo.
+func (u *UserAbortInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPUserAbort)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || es[0].Class != ctx || es[0].Tag > ApplicationProcedureCancellation {
+		return dec.Missing("map-UserAbortChoice")
+	}
+
+	*u = UserAbortInfo{Choice: es[0].Tag}
+	if u.Choice == ResourceUnavailable || u.Choice == ApplicationProcedureCancellation {
+		if u.Reason, err = dec.Octet(es[0]); err != nil {
+			return err
+		}
+	}
+	for _, e := range es[1:] {
+		u.Extra = append(u.Extra, e.Raw...)
+	}
+	return nil
+}
+
+// MAP-ProviderAbortReason definitions.
+const (
+	AbnormalDialogue uint8 = iota
+	InvalidPDU
+)
+
+// ProviderAbortInfo is MAP-ProviderAbortInfo, which is sent in ABRT by the MAP
+// service provider.
+type ProviderAbortInfo struct {
+	Reason uint8
+	Extra  []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ProviderAbortInfo.
+func (p *ProviderAbortInfo) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, MAPProviderAbort, func(e *encoder) {
+		e.Octet(univ, tagEnumerated, p.Reason)
+		e.Raw(p.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ProviderAbortInfo.
+func (p *ProviderAbortInfo) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, MAPProviderAbort)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(univ, tagEnumerated) {
+		return dec.Missing("map-ProviderAbortReason")
+	}
+
+	*p = ProviderAbortInfo{}
+	if p.Reason, err = dec.Octet(es[0]); err != nil {
+		return err
+	}
+	for _, e := range es[1:] {
+		p.Extra = append(p.Extra, e.Raw...)
+	}
+	return nil
+}
@@ -0,0 +1,549 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+)
+
+// Error Code definitions.
+const (
+	UnknownSubscriber              = 1
+	UnknownBaseStation             = 2
+	UnknownMSC                     = 3
+	SecureTransportError           = 4
+	UnidentifiedSubscriber         = 5
+	AbsentSubscriberSM             = 6
+	UnknownEquipment               = 7
+	RoamingNotAllowed              = 8
+	IllegalSubscriber              = 9
+	BearerServiceNotProvisioned    = 10
+	TeleserviceNotProvisioned      = 11
+	IllegalEquipment               = 12
+	CallBarred                     = 13
+	ForwardingViolation            = 14
+	CUGReject                      = 15
+	IllegalSSOperation             = 16
+	SSErrorStatus                  = 17
+	SSNotAvailable                 = 18
+	SSSubscriptionViolation        = 19
+	SSIncompatibility              = 20
+	FacilityNotSupported           = 21
+	OngoingGroupCall               = 22
+	InvalidTargetBaseStation       = 23
+	NoRadioResourceAvailable       = 24
+	NoHandoverNumberAvailable      = 25
+	SubsequentHandoverFailure      = 26
+	AbsentSubscriber               = 27
+	IncompatibleTerminal           = 28
+	ShortTermDenial                = 29
+	LongTermDenial                 = 30
+	SubscriberBusyForMTSMS         = 31
+	SMDeliveryFailure              = 32
+	MessageWaitingListFull         = 33
+	SystemFailure                  = 34
+	DataMissing                    = 35
+	UnexpectedDataValue            = 36
+	PWRegistrationFailure          = 37
+	NegativePWCheck                = 38
+	NoRoamingNumberAvailable       = 39
+	TracingBufferFull              = 40
+	TargetCellOutsideGroupCallArea = 42
+	NumberOfPWAttemptsViolation    = 43
+	NumberChanged                  = 44
+	BusySubscriber                 = 45
+	NoSubscriberReply              = 46
+	ForwardingFailed               = 47
+	ORNotAllowed                   = 48
+	ATINotAllowed                  = 49
+	NoGroupCallNumberAvailable     = 50
+	ResourceLimitation             = 51
+	UnauthorizedRequestingNetwork  = 52
+	UnauthorizedLCSClient          = 53
+	PositionMethodFailure          = 54
+	UnknownOrUnreachableLCSClient  = 58
+	MMEventNotSupported            = 59
+	ATSINotAllowed                 = 60
+	ATMNotAllowed                  = 61
+	InformationNotAvailable        = 62
+	UnknownAlphabet                = 71
+	USSDBusy                       = 72
+)
+
+var errorNames = map[int]string{
+	UnknownSubscriber:              "unknownSubscriber",
+	UnknownBaseStation:             "unknownBaseStation",
+	UnknownMSC:                     "unknownMSC",
+	SecureTransportError:           "secureTransportError",
+	UnidentifiedSubscriber:         "unidentifiedSubscriber",
+	AbsentSubscriberSM:             "absentSubscriberSM",
+	UnknownEquipment:               "unknownEquipment",
+	RoamingNotAllowed:              "roamingNotAllowed",
+	IllegalSubscriber:              "illegalSubscriber",
+	BearerServiceNotProvisioned:    "bearerServiceNotProvisioned",
+	TeleserviceNotProvisioned:      "teleserviceNotProvisioned",
+	IllegalEquipment:               "illegalEquipment",
+	CallBarred:                     "callBarred",
+	ForwardingViolation:            "forwardingViolation",
+	CUGReject:                      "cug-Reject",
+	IllegalSSOperation:             "illegalSS-Operation",
+	SSErrorStatus:                  "ss-ErrorStatus",
+	SSNotAvailable:                 "ss-NotAvailable",
+	SSSubscriptionViolation:        "ss-SubscriptionViolation",
+	SSIncompatibility:              "ss-Incompatibility",
+	FacilityNotSupported:           "facilityNotSupported",
+	OngoingGroupCall:               "ongoingGroupCall",
+	InvalidTargetBaseStation:       "invalidTargetBaseStation",
+	NoRadioResourceAvailable:       "noRadioResourceAvailable",
+	NoHandoverNumberAvailable:      "noHandoverNumberAvailable",
+	SubsequentHandoverFailure:      "subsequentHandoverFailure",
+	AbsentSubscriber:               "absentSubscriber",
+	IncompatibleTerminal:           "incompatibleTerminal",
+	ShortTermDenial:                "shortTermDenial",
+	LongTermDenial:                 "longTermDenial",
+	SubscriberBusyForMTSMS:         "subscriberBusyForMT-SMS",
+	SMDeliveryFailure:              "sm-DeliveryFailure",
+	MessageWaitingListFull:         "messageWaitingListFull",
+	SystemFailure:                  "systemFailure",
+	DataMissing:                    "dataMissing",
+	UnexpectedDataValue:            "unexpectedDataValue",
+	PWRegistrationFailure:          "pw-RegistrationFailure",
+	NegativePWCheck:                "negativePW-Check",
+	NoRoamingNumberAvailable:       "noRoamingNumberAvailable",
+	TracingBufferFull:              "tracingBufferFull",
+	TargetCellOutsideGroupCallArea: "targetCellOutsideGroupCallArea",
+	NumberOfPWAttemptsViolation:    "numberOfPW-AttemptsViolation",
+	NumberChanged:                  "numberChanged",
+	BusySubscriber:                 "busySubscriber",
+	NoSubscriberReply:              "noSubscriberReply",
+	ForwardingFailed:               "forwardingFailed",
+	ORNotAllowed:                   "or-NotAllowed",
+	ATINotAllowed:                  "ati-NotAllowed",
+	NoGroupCallNumberAvailable:     "noGroupCallNumberAvailable",
+	ResourceLimitation:             "resourceLimitation",
+	UnauthorizedRequestingNetwork:  "unauthorizedRequestingNetwork",
+	UnauthorizedLCSClient:          "unauthorizedLCSClient",
+	PositionMethodFailure:          "positionMethodFailure",
+	UnknownOrUnreachableLCSClient:  "unknownOrUnreachableLCSClient",
+	MMEventNotSupported:            "mm-EventNotSupported",
+	ATSINotAllowed:                 "atsi-NotAllowed",
+	ATMNotAllowed:                  "atm-NotAllowed",
+	InformationNotAvailable:        "informationNotAvailable",
+	UnknownAlphabet:                "unknownAlphabet",
+	USSDBusy:                       "ussd-Busy",
+}
+
+// ErrorString returns the name of the Error Code, e.g. "unknownSubscriber".
+func ErrorString(code int) string {
+	if name, ok := errorNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", code)
+}
+
+// errorParameters have the constructors of the typed parameters by the Error
+// Code. The parameters of the ones not in it are not modeled.
+var errorParameters = map[int]func() Parameter{
+	UnknownSubscriber:  func() Parameter { return &UnknownSubscriberParam{} },
+	AbsentSubscriberSM: func() Parameter { return &AbsentSubscriberSMParam{} },
+	CallBarred:         func() Parameter { return &CallBarredParam{} },
+	AbsentSubscriber:   func() Parameter { return &AbsentSubscriberParam{} },
+	SMDeliveryFailure:  func() Parameter { return &SMDeliveryFailureCause{} },
+	SystemFailure:      func() Parameter { return &SystemFailureParam{} },
+}
+
+// NewErrorParameter returns the empty parameter of the error.
+//
+// It returns nil without error if the parameter of the error is not modeled.
+func NewErrorParameter(code int) (Parameter, error) {
+	if _, ok := errorNames[code]; !ok {
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("unknown error code: %d", code)}
+	}
+	f, ok := errorParameters[code]
+	if !ok {
+		return nil, nil
+	}
+	return f(), nil
+}
+
+// DecodeError decodes b as the parameter of the error.
+func DecodeError(code int, b []byte) (Parameter, error) {
+	p, err := NewErrorParameter(code)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("parameter of error %s is not supported", ErrorString(code))}
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// decodeEnumerated decodes e as an ENUMERATED in a uint8.
+func decodeEnumerated(e *ber.Element) (*uint8, error) {
+	v, err := dec.Octet(e)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// UnknownSubscriberDiagnostic definitions.
+const (
+	IMSIUnknown uint8 = iota
+	GPRSEPSSubscriptionUnknown
+	NPDBMismatch
+)
+
+// UnknownSubscriberParam is the parameter of unknownSubscriber.
+type UnknownSubscriberParam struct {
+	// Diagnostic is nil if not present.
+	Diagnostic *uint8
+	Extra      []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an UnknownSubscriberParam.
+func (u *UnknownSubscriberParam) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		if u.Diagnostic != nil {
+			e.Octet(univ, tagEnumerated, *u.Diagnostic)
+		}
+		e.Raw(u.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an UnknownSubscriberParam.
+func (u *UnknownSubscriberParam) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*u = UnknownSubscriberParam{}
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagEnumerated):
+			if u.Diagnostic, err = decodeEnumerated(e); err != nil {
+				return err
+			}
+		default:
+			u.Extra = append(u.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// AbsentSubscriberSMParam is the parameter of absentSubscriberSM, with the
+// absent subscriber diagnostic for SM in 3GPP TS 23.040.
+type AbsentSubscriberSMParam struct {
+	// Diagnostic and AdditionalDiagnostic are nil if not present.
+	Diagnostic           *int
+	AdditionalDiagnostic *int
+	Extra                []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an AbsentSubscriberSMParam.
+func (a *AbsentSubscriberSMParam) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		if a.Diagnostic != nil {
+			e.Integer(univ, tagInteger, *a.Diagnostic)
+		}
+		if a.AdditionalDiagnostic != nil {
+			e.Integer(ctx, 0, *a.AdditionalDiagnostic)
+		}
+		e.Raw(a.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AbsentSubscriberSMParam.
+func (a *AbsentSubscriberSMParam) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*a = AbsentSubscriberSMParam{}
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagInteger):
+			v := ber.DecodeInteger(e.Value)
+			a.Diagnostic = &v
+		case e.Is(ctx, 0):
+			v := ber.DecodeInteger(e.Value)
+			a.AdditionalDiagnostic = &v
+		default:
+			a.Extra = append(a.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// AbsentSubscriberReason definitions.
+const (
+	AbsentIMSIDetach uint8 = iota
+	AbsentRestrictedArea
+	AbsentNoPageResponse
+	AbsentPurgedMS
+	AbsentMTRoamingRetry
+	AbsentBusySubscriber
+)
+
+// AbsentSubscriberParam is the parameter of absentSubscriber.
+type AbsentSubscriberParam struct {
+	// Reason is nil if not present.
+	Reason *uint8
+	Extra  []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an AbsentSubscriberParam.
+func (a *AbsentSubscriberParam) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Raw(a.Extra)
+		if a.Reason != nil {
+			e.Octet(ctx, 0, *a.Reason)
+		}
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AbsentSubscriberParam.
+func (a *AbsentSubscriberParam) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*a = AbsentSubscriberParam{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			if a.Reason, err = decodeEnumerated(e); err != nil {
+				return err
+			}
+		default:
+			a.Extra = append(a.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// CallBarringCause definitions.
+const (
+	BarringServiceActive uint8 = iota
+	OperatorBarring
+)
+
+// CallBarredParam is the parameter of callBarred, which is either the
+// CallBarringCause only or ExtensibleCallBarredParam if Extensible is true.
+type CallBarredParam struct {
+	// Cause is nil if not present, which is mandatory if Extensible is false.
+	Cause                         *uint8
+	Extensible                    bool
+	UnauthorisedMessageOriginator bool
+	AnonymousCallRejection        bool
+	Extra                         []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a CallBarredParam.
+func (c *CallBarredParam) MarshalBinary() ([]byte, error) {
+	if !c.Extensible {
+		if c.Cause == nil {
+			return nil, dec.Missing("callBarringCause")
+		}
+		return ber.AppendElement(nil, univ, false, tagEnumerated, []byte{*c.Cause}), nil
+	}
+
+	return codec.Sequence(func(e *encoder) {
+		if c.Cause != nil {
+			e.Octet(univ, tagEnumerated, *c.Cause)
+		}
+		e.Null(ctx, 1, c.UnauthorisedMessageOriginator)
+		e.Null(ctx, 2, c.AnonymousCallRejection)
+		e.Raw(c.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CallBarredParam.
+func (c *CallBarredParam) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+
+	*c = CallBarredParam{}
+	if e.Is(univ, tagEnumerated) {
+		c.Cause, err = decodeEnumerated(e)
+		return err
+	}
+	if !e.Is(univ, tagSequence) || !e.Constructed {
+		return dec.Unexpected(e)
+	}
+
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+	c.Extensible = true
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagEnumerated):
+			if c.Cause, err = decodeEnumerated(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 1):
+			c.UnauthorisedMessageOriginator = true
+		case e.Is(ctx, 2):
+			c.AnonymousCallRejection = true
+		default:
+			c.Extra = append(c.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// SM-EnumeratedDeliveryFailureCause definitions.
+const (
+	MemoryCapacityExceeded uint8 = iota
+	EquipmentProtocolError
+	EquipmentNotSMEquipped
+	UnknownServiceCentre
+	SCCongestion
+	InvalidSMEAddress
+	SubscriberNotSCSubscriber
+)
+
+// SMDeliveryFailureCause is the parameter of sm-DeliveryFailure.
+type SMDeliveryFailureCause struct {
+	Cause uint8
+	// DiagnosticInfo is the SMS-DELIVER-REPORT or SMS-SUBMIT-REPORT TPDU in
+	// 3GPP TS 23.040, which is nil if not present.
+	DiagnosticInfo []byte
+	Extra          []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a SMDeliveryFailureCause.
+func (s *SMDeliveryFailureCause) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Octet(univ, tagEnumerated, s.Cause)
+		e.Octets(univ, tagOctetString, s.DiagnosticInfo)
+		e.Raw(s.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SMDeliveryFailureCause.
+func (s *SMDeliveryFailureCause) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(univ, tagEnumerated) {
+		return dec.Missing("sm-EnumeratedDeliveryFailureCause")
+	}
+
+	*s = SMDeliveryFailureCause{}
+	if s.Cause, err = dec.Octet(es[0]); err != nil {
+		return err
+	}
+	for _, e := range es[1:] {
+		switch {
+		case e.Is(univ, tagOctetString):
+			s.DiagnosticInfo = codec.Clone(e.Value)
+		default:
+			s.Extra = append(s.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// NetworkResource definitions.
+const (
+	ResourcePLMN uint8 = iota
+	ResourceHLR
+	ResourceVLR
+	ResourcePVLR
+	ResourceControllingMSC
+	ResourceVMSC
+	ResourceEIR
+	ResourceRSS
+)
+
+// SystemFailureParam is the parameter of systemFailure, which is either the
+// NetworkResource only or ExtensibleSystemFailureParam if Extensible is true.
+type SystemFailureParam struct {
+	// NetworkResource is nil if not present, which is mandatory if Extensible
+	// is false.
+	NetworkResource *uint8
+	Extensible      bool
+	// AdditionalNetworkResource and FailureCause are nil if not present.
+	AdditionalNetworkResource *uint8
+	FailureCause              *uint8
+	Extra                     []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a SystemFailureParam.
+func (s *SystemFailureParam) MarshalBinary() ([]byte, error) {
+	if !s.Extensible {
+		if s.NetworkResource == nil {
+			return nil, dec.Missing("networkResource")
+		}
+		return ber.AppendElement(nil, univ, false, tagEnumerated, []byte{*s.NetworkResource}), nil
+	}
+
+	return codec.Sequence(func(e *encoder) {
+		if s.NetworkResource != nil {
+			e.Octet(univ, tagEnumerated, *s.NetworkResource)
+		}
+		if s.AdditionalNetworkResource != nil {
+			e.Octet(ctx, 0, *s.AdditionalNetworkResource)
+		}
+		if s.FailureCause != nil {
+			e.Octet(ctx, 1, *s.FailureCause)
+		}
+		e.Raw(s.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SystemFailureParam.
+func (s *SystemFailureParam) UnmarshalBinary(b []byte) error {
+	e, err := dec.ReadElement(b)
+	if err != nil {
+		return err
+	}
+
+	*s = SystemFailureParam{}
+	if e.Is(univ, tagEnumerated) {
+		s.NetworkResource, err = decodeEnumerated(e)
+		return err
+	}
+	if !e.Is(univ, tagSequence) || !e.Constructed {
+		return dec.Unexpected(e)
+	}
+
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+	s.Extensible = true
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagEnumerated):
+			s.NetworkResource, err = decodeEnumerated(e)
+		case e.Is(ctx, 0):
+			s.AdditionalNetworkResource, err = decodeEnumerated(e)
+		case e.Is(ctx, 1):
+			s.FailureCause, err = decodeEnumerated(e)
+		default:
+			s.Extra = append(s.Extra, e.Raw...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package gsmmap provides the typed arguments and results of the common MAP
+operations in 3GPP TS 29.002, which are encoded in and decoded from Parameter
+in Component.
+
+The types and the encodings depend on the operation and the version of the
+Application Context Name, as the older versions have different ones for some
+operations (e.g. cancelLocation v1/v2 has Identity as its argument, while v3
+has CancelLocationArg).
+
+	// Decode the Parameter in an Invoke component.
+	p, err := gsmmap.Decode(t.Components.Component[0], gsmmap.ContextVersion(t))
+	if err != nil {
+		// ...
+	}
+	if arg, ok := p.(*gsmmap.Identity); ok {
+		fmt.Println(arg.IMSI)
+	}
+
+	// Set the Parameter in a component.
+	err := gsmmap.SetParameter(c, &gsmmap.Identity{IMSI: "001010123456789"})
+
+The parameters of the common errors such as systemFailure and absentSubscriber
+are decoded in the same way from ReturnError, and the names of all the Error
+Codes are given by ErrorString.
+
+The MAP dialogue PDUs such as OpenInfo are carried in the user information of
+the dialogue portion, which are built with NewUserInformation and read with
+DialogueInfo.
+
+The elements not modeled in the structs (e.g. the extension container) are kept
+in Extra as they are encoded, which is appended at the end of the SEQUENCE in
+encoding.
+*/
+package gsmmap
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Operation Code definitions.
+const (
+	UpdateLocation               = 2
+	CancelLocation               = 3
+	InsertSubscriberData         = 7
+	MTForwardSM                  = 44
+	SendRoutingInfoForSM         = 45
+	MOForwardSM                  = 46 // forwardSM in v1 and v2.
+	SendAuthenticationInfo       = 56
+	ProcessUnstructuredSSRequest = 59
+	AnyTimeInterrogation         = 71
+)
+
+// Parameter is an argument or a result of an operation, which is encoded as
+// the whole element including the tag and the length.
+type Parameter = tcap.TypedParameter
+
+type key struct {
+	opCode, version int
+}
+
+// arguments and results have the constructors of the Parameters by the
+// Operation Code and the version. The nil one means that the operation does
+// not have the Parameter in the version.
+var (
+	arguments = map[key]func() Parameter{
+		{UpdateLocation, 2}:               func() Parameter { return &UpdateLocationArg{} },
+		{UpdateLocation, 3}:               func() Parameter { return &UpdateLocationArg{} },
+		{CancelLocation, 1}:               func() Parameter { return &Identity{} },
+		{CancelLocation, 2}:               func() Parameter { return &Identity{} },
+		{CancelLocation, 3}:               func() Parameter { return &CancelLocationArg{} },
+		{InsertSubscriberData, 2}:         func() Parameter { return &InsertSubscriberDataArg{} },
+		{InsertSubscriberData, 3}:         func() Parameter { return &InsertSubscriberDataArg{} },
+		{MTForwardSM, 3}:                  func() Parameter { return &ForwardSMArg{} },
+		{SendRoutingInfoForSM, 1}:         func() Parameter { return &RoutingInfoForSMArg{} },
+		{SendRoutingInfoForSM, 2}:         func() Parameter { return &RoutingInfoForSMArg{} },
+		{SendRoutingInfoForSM, 3}:         func() Parameter { return &RoutingInfoForSMArg{} },
+		{MOForwardSM, 1}:                  func() Parameter { return &ForwardSMArg{} },
+		{MOForwardSM, 2}:                  func() Parameter { return &ForwardSMArg{} },
+		{MOForwardSM, 3}:                  func() Parameter { return &ForwardSMArg{} },
+		{SendAuthenticationInfo, 2}:       func() Parameter { return new(IMSI) },
+		{SendAuthenticationInfo, 3}:       func() Parameter { return &SendAuthenticationInfoArg{} },
+		{ProcessUnstructuredSSRequest, 2}: func() Parameter { return &USSDArg{} },
+		{AnyTimeInterrogation, 3}:         func() Parameter { return &AnyTimeInterrogationArg{} },
+	}
+
+	results = map[key]func() Parameter{
+		{UpdateLocation, 2}:               func() Parameter { return &UpdateLocationRes{} },
+		{UpdateLocation, 3}:               func() Parameter { return &UpdateLocationRes{} },
+		{CancelLocation, 1}:               nil,
+		{CancelLocation, 2}:               nil,
+		{CancelLocation, 3}:               func() Parameter { return &CancelLocationRes{} },
+		{InsertSubscriberData, 2}:         func() Parameter { return &InsertSubscriberDataRes{} },
+		{InsertSubscriberData, 3}:         func() Parameter { return &InsertSubscriberDataRes{} },
+		{MTForwardSM, 3}:                  func() Parameter { return &ForwardSMRes{} },
+		{SendRoutingInfoForSM, 1}:         func() Parameter { return &RoutingInfoForSMRes{} },
+		{SendRoutingInfoForSM, 2}:         func() Parameter { return &RoutingInfoForSMRes{} },
+		{SendRoutingInfoForSM, 3}:         func() Parameter { return &RoutingInfoForSMRes{} },
+		{MOForwardSM, 1}:                  nil,
+		{MOForwardSM, 2}:                  nil,
+		{MOForwardSM, 3}:                  func() Parameter { return &ForwardSMRes{} },
+		{SendAuthenticationInfo, 2}:       func() Parameter { return &TripletList{} },
+		{SendAuthenticationInfo, 3}:       func() Parameter { return &SendAuthenticationInfoRes{} },
+		{ProcessUnstructuredSSRequest, 2}: func() Parameter { return &USSDRes{} },
+		{AnyTimeInterrogation, 3}:         func() Parameter { return &AnyTimeInterrogationRes{} },
+	}
+)
+
+// NewArgument returns the empty argument of the operation in the version.
+//
+// It returns nil without error if the operation does not have the argument.
+func NewArgument(opCode, version int) (Parameter, error) {
+	return newParameter(arguments, opCode, version)
+}
+
+// NewResult returns the empty result of the operation in the version.
+//
+// It returns nil without error if the operation does not have the result.
+func NewResult(opCode, version int) (Parameter, error) {
+	return newParameter(results, opCode, version)
+}
+
+func newParameter(m map[key]func() Parameter, opCode, version int) (Parameter, error) {
+	f, ok := m[key{opCode, version}]
+	if !ok {
+		return nil, &UnsupportedOperationError{OpCode: opCode, Version: version}
+	}
+	if f == nil {
+		return nil, nil
+	}
+	return f(), nil
+}
+
+// DecodeArgument decodes b as the argument of the operation in the version.
+func DecodeArgument(opCode, version int, b []byte) (Parameter, error) {
+	return decode(arguments, opCode, version, b)
+}
+
+// DecodeResult decodes b as the result of the operation in the version.
+func DecodeResult(opCode, version int, b []byte) (Parameter, error) {
+	return decode(results, opCode, version, b)
+}
+
+func decode(m map[key]func() Parameter, opCode, version int, b []byte) (Parameter, error) {
+	p, err := newParameter(m, opCode, version)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("operation %d does not have parameter in version %d", opCode, version)}
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Decode decodes the Parameter in c, as the argument if c is Invoke, as the
+// result if c is ReturnResult(Last/NotLast) by the Operation Code in c and the
+// version given, or as the parameter of the error if c is ReturnError.
+//
+// It returns nil without error if c does not have the Parameter.
+func Decode(c *tcap.Component, version int) (Parameter, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+
+	var code *tcap.IE
+	switch c.Type.Code() {
+	case tcap.Invoke, tcap.ReturnResultLast, tcap.ReturnResultNotLast:
+		if c.OperationCode == nil {
+			return nil, &InvalidParameterError{Reason: "no operation code in component"}
+		}
+		code = c.OperationCode
+	case tcap.ReturnError:
+		if c.ErrorCode == nil {
+			return nil, &InvalidParameterError{Reason: "no error code in component"}
+		}
+		code = c.ErrorCode
+	default:
+		return nil, &InvalidParameterError{Reason: fmt.Sprintf("unexpected component type: %s", c.ComponentTypeString())}
+	}
+	if code.Tag != tcap.NewUniversalPrimitiveTag(2) {
+		return nil, &InvalidParameterError{Reason: "global code is not supported"}
+	}
+	v := ber.DecodeInteger(code.Value)
+
+	b, err := c.Parameter.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Type.Code() {
+	case tcap.Invoke:
+		return DecodeArgument(v, version, b)
+	case tcap.ReturnError:
+		return DecodeError(v, b)
+	}
+	return DecodeResult(v, version, b)
+}
+
+// SetParameter sets p encoded as the Parameter in c.
+func SetParameter(c *tcap.Component, p Parameter) error {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	ie, err := tcap.ParseIERecursive(b)
+	if err != nil {
+		return err
+	}
+	c.Parameter = ie
+	c.SetLength()
+	return nil
+}
+
+// ContextVersion returns the version of the MAP Application Context Name in the
+// dialogue portion of t, or 0 if not present.
+func ContextVersion(t *tcap.TCAP) int {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil || d.DialoguePDU.ApplicationContextName == nil {
+		return 0
+	}
+	_, v, _, err := ber.ReadTLV(d.DialoguePDU.ApplicationContextName.Value)
+	if err != nil {
+		return 0
+	}
+
+	// {itu-t identified-organization etsi mobileDomain gsm-Network ac-Id ctx ver}
+	if len(v) != 7 || !bytes.HasPrefix(v, []byte{0x04, 0x00, 0x00, 0x01, 0x00}) {
+		return 0
+	}
+	return int(v[6])
+}
+
+// InvalidParameterError indicates that a Parameter cannot be encoded or decoded.
+type InvalidParameterError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidParameterError) Error() string {
+	return "gsmmap: invalid parameter: " + e.Reason
+}
+
+// UnsupportedOperationError indicates that the operation is not supported in
+// the version.
+type UnsupportedOperationError struct {
+	OpCode  int
+	Version int
+}
+
+// Error returns error message with violating content.
+func (e *UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("gsmmap: unsupported operation: %d in version %d", e.OpCode, e.Version)
+}
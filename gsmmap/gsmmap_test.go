@@ -0,0 +1,575 @@
+package gsmmap_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/goe/verify"
+
+	"github.com/danievanzyl/go-ya-tcap"
+	"github.com/danievanzyl/go-ya-tcap/gsmmap"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	imsi   = "001010123456789"
+	msisdn = gsmmap.NewISDNAddress("81901234567")
+	hlr    = gsmmap.NewISDNAddress("8190123455")
+	msc    = gsmmap.NewISDNAddress("8190123456")
+	vlr    = gsmmap.NewISDNAddress("8190123457")
+	rand   = mustHex("000102030405060708090a0b0c0d0e0f")
+)
+
+func uint8p(v uint8) *uint8 {
+	return &v
+}
+
+func intp(v int) *int {
+	return &v
+}
+
+var cases = []struct {
+	description string
+	opCode      int
+	version     int
+	result      bool
+	structured  gsmmap.Parameter
+	serialized  string
+}{
+	{
+		"updateLocation/Arg", gsmmap.UpdateLocation, 3, false,
+		&gsmmap.UpdateLocationArg{
+			IMSI: imsi, MSCNumber: msc, VLRNumber: vlr,
+			VLRCapability: mustHex("800205e0"),
+			Extra:         mustHex("8b00"),
+		},
+		"3022040800010121436587f981069118092143650406911809214375a604800205e08b00",
+	}, {
+		"updateLocation/Res", gsmmap.UpdateLocation, 2, true,
+		&gsmmap.UpdateLocationRes{HLRNumber: hlr, AddCapability: true},
+		"300a04069118092143550500",
+	}, {
+		"cancelLocation/Arg v2", gsmmap.CancelLocation, 2, false,
+		&gsmmap.Identity{IMSI: imsi},
+		"040800010121436587f9",
+	}, {
+		"cancelLocation/Arg v1 with LMSI", gsmmap.CancelLocation, 1, false,
+		&gsmmap.Identity{IMSI: imsi, LMSI: []byte{1, 2, 3, 4}},
+		"3010040800010121436587f9040401020304",
+	}, {
+		"cancelLocation/Arg v3", gsmmap.CancelLocation, 3, false,
+		&gsmmap.CancelLocationArg{Identity: gsmmap.Identity{IMSI: imsi}, CancellationType: uint8p(gsmmap.SubscriptionWithdraw)},
+		"a30d040800010121436587f90a0101",
+	}, {
+		"cancelLocation/Res v3", gsmmap.CancelLocation, 3, true,
+		&gsmmap.CancelLocationRes{},
+		"3000",
+	}, {
+		"insertSubscriberData/Arg", gsmmap.InsertSubscriberData, 3, false,
+		&gsmmap.InsertSubscriberDataArg{
+			IMSI: imsi, MSISDN: msisdn, Category: []byte{0x0a},
+			SubscriberStatus: uint8p(gsmmap.ServiceGranted),
+			TeleserviceList:  [][]byte{{0x11}, {0x21}},
+		},
+		"3021800800010121436587f98107911809214365f782010a830100a606040111040121",
+	}, {
+		"insertSubscriberData/Res", gsmmap.InsertSubscriberData, 2, true,
+		&gsmmap.InsertSubscriberDataRes{TeleserviceList: [][]byte{{0x22}}},
+		"3005a103040122",
+	}, {
+		"sendAuthenticationInfo/Arg v2", gsmmap.SendAuthenticationInfo, 2, false,
+		func() gsmmap.Parameter { i := gsmmap.IMSI(imsi); return &i }(),
+		"040800010121436587f9",
+	}, {
+		"sendAuthenticationInfo/Res v2", gsmmap.SendAuthenticationInfo, 2, true,
+		&gsmmap.TripletList{{RAND: rand, SRES: mustHex("a1a2a3a4"), Kc: mustHex("b1b2b3b4b5b6b7b8")}},
+		"302430220410000102030405060708090a0b0c0d0e0f0404a1a2a3a40408b1b2b3b4b5b6b7b8",
+	}, {
+		"sendAuthenticationInfo/Arg v3", gsmmap.SendAuthenticationInfo, 3, false,
+		&gsmmap.SendAuthenticationInfoArg{
+			IMSI: imsi, NumberOfRequestedVectors: 5,
+			SegmentationProhibited: true, ImmediateResponsePreferred: true,
+		},
+		"a311800800010121436587f902010505008100",
+	}, {
+		"sendAuthenticationInfo/Res v3", gsmmap.SendAuthenticationInfo, 3, true,
+		&gsmmap.SendAuthenticationInfoRes{
+			Quintuplets: []*gsmmap.AuthenticationQuintuplet{{
+				RAND: rand,
+				XRES: mustHex("c1c2c3c4c5c6c7c8"),
+				CK:   mustHex("d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0"),
+				IK:   mustHex("e0e0e0e0e0e0e0e0e0e0e0e0e0e0e0e0"),
+				AUTN: mustHex("f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0"),
+			}},
+		},
+		"a356a15430520410000102030405060708090a0b0c0d0e0f0408c1c2c3c4c5c6c7c80410d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d00410e0e0e0e0e0e0e0e0e0e0e0e0e0e0e0e00410f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0",
+	}, {
+		"sendRoutingInfoForSM/Arg", gsmmap.SendRoutingInfoForSM, 3, false,
+		&gsmmap.RoutingInfoForSMArg{MSISDN: msisdn, SMRPPRI: true, ServiceCentreAddress: hlr, GPRSSupportIndicator: true},
+		"30168007911809214365f78101ff82069118092143558700",
+	}, {
+		"sendRoutingInfoForSM/Res", gsmmap.SendRoutingInfoForSM, 2, true,
+		&gsmmap.RoutingInfoForSMRes{
+			IMSI:         imsi,
+			LocationInfo: gsmmap.LocationInfoWithLMSI{NetworkNodeNumber: vlr, LMSI: []byte{1, 2, 3, 4}},
+		},
+		"301a040800010121436587f9a00e8106911809214375040401020304",
+	}, {
+		"mo-forwardSM/Arg", gsmmap.MOForwardSM, 3, false,
+		&gsmmap.ForwardSMArg{
+			DA:   gsmmap.SMRPDA{ServiceCentreAddress: hlr},
+			OA:   gsmmap.SMRPOA{MSISDN: msisdn},
+			UI:   []byte{1, 2, 3, 4, 5},
+			IMSI: imsi,
+		},
+		"302284069118092143558207911809214365f704050102030405040800010121436587f9",
+	}, {
+		"mt-forwardSM/Arg", gsmmap.MTForwardSM, 3, false,
+		&gsmmap.ForwardSMArg{
+			DA:                 gsmmap.SMRPDA{IMSI: imsi},
+			OA:                 gsmmap.SMRPOA{ServiceCentreAddress: hlr},
+			UI:                 []byte{1, 2, 3},
+			MoreMessagesToSend: true,
+		},
+		"3019800800010121436587f9840691180921435504030102030500",
+	}, {
+		"mt-forwardSM/Res", gsmmap.MTForwardSM, 3, true,
+		&gsmmap.ForwardSMRes{},
+		"3000",
+	}, {
+		"processUnstructuredSS-Request/Arg", gsmmap.ProcessUnstructuredSSRequest, 2, false,
+		&gsmmap.USSDArg{DataCodingScheme: 0x0f, String: mustHex("aa582c36"), MSISDN: msisdn},
+		"301204010f0404aa582c368007911809214365f7",
+	}, {
+		"processUnstructuredSS-Request/Res", gsmmap.ProcessUnstructuredSSRequest, 2, true,
+		&gsmmap.USSDRes{DataCodingScheme: 0x0f, String: mustHex("aa582c36")},
+		"300904010f0404aa582c36",
+	}, {
+		"anyTimeInterrogation/Arg", gsmmap.AnyTimeInterrogation, 3, false,
+		&gsmmap.AnyTimeInterrogationArg{
+			MSISDN:        msisdn,
+			RequestedInfo: gsmmap.RequestedInfo{LocationInformation: true, SubscriberState: true},
+			GsmSCFAddress: hlr,
+		},
+		"3019a0098107911809214365f7a104800081008306911809214355",
+	}, {
+		"anyTimeInterrogation/Res", gsmmap.AnyTimeInterrogation, 3, true,
+		&gsmmap.AnyTimeInterrogationRes{
+			SubscriberInfo: gsmmap.SubscriberInfo{
+				LocationInformation: &gsmmap.LocationInformation{
+					AgeOfLocationInformation: intp(5),
+					VLRNumber:                vlr,
+					CellGlobalID:             mustHex("25f01000010002"),
+				},
+				SubscriberState: &gsmmap.SubscriberState{State: gsmmap.AssumedIdle},
+			},
+		},
+		"301e301ca0160201058106911809214375a309800725f01000010002a1028000",
+	},
+}
+
+func TestParameters(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+
+			decode := gsmmap.DecodeArgument
+			if c.result {
+				decode = gsmmap.DecodeResult
+			}
+			got, err := decode(c.opCode, c.version, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// The MAP cancelLocation v3 in the example client, of which the argument is
+	// encoded as in v2.
+	c := tcap.NewBeginInvokeWithDialogue(0x11111111, tcap.DialogueAsID, tcap.LocationCancellationContext, 2, 0, 3,
+		mustHex("040800010121436587f9"))
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := gsmmap.ContextVersion(msg)
+	if version != 2 {
+		t.Fatalf("got version %d, want 2", version)
+	}
+	p, err := gsmmap.Decode(msg.Components.Component[0], version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := p.(*gsmmap.Identity); !ok || id.IMSI != imsi {
+		t.Errorf("got %#v", p)
+	}
+
+	if _, err := gsmmap.Decode(msg.Components.Component[0], 9); err == nil {
+		t.Error("expected error with unsupported version")
+	}
+}
+
+func TestSetParameter(t *testing.T) {
+	c := tcap.NewInvoke(1, -1, gsmmap.AnyTimeInterrogation, true, nil)
+	arg := &gsmmap.AnyTimeInterrogationArg{
+		IMSI:          imsi,
+		RequestedInfo: gsmmap.RequestedInfo{SubscriberState: true},
+		GsmSCFAddress: hlr,
+	}
+	if err := gsmmap.SetParameter(c, arg); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := tcap.ParseComponents(append([]byte{0x6c, byte(len(b))}, b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gsmmap.Decode(parsed.Component[0], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, arg)
+}
+
+func TestInvalidParameter(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		opCode      int
+		version     int
+		serialized  string
+	}{
+		{"truncated", gsmmap.UpdateLocation, 3, "3022040800"},
+		{"missing mandatory", gsmmap.UpdateLocation, 3, "300a040800010121436587f9"},
+		{"unexpected tag", gsmmap.UpdateLocation, 3, "a000"},
+		{"trailing bytes", gsmmap.ProcessUnstructuredSSRequest, 2, "300904010f0404aa582c3600"},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := gsmmap.DecodeArgument(c.opCode, c.version, mustHex(c.serialized)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := gsmmap.DecodeResult(gsmmap.CancelLocation, 2, mustHex("3000")); err == nil {
+		t.Error("expected error with the operation without result")
+	}
+	if _, err := (&gsmmap.ForwardSMArg{IMSI: "12x"}).MarshalBinary(); err == nil {
+		t.Error("expected error with invalid digits")
+	}
+}
+
+func TestDecodeUSSD(t *testing.T) {
+	msg, err := tcap.Parse(mustHex("6281f248040000000a6b3f283d060700118605010101a032603080020780a109060704000001001302be1f281d060704000001010101a012a01080069121436587f981069121436587f96c81a8a181a502010002013b30819c04010f04818c5474d8bd06e5df7590f92d07d5e769f71944479741c7373bec3e83aad32911342fcbed65b90b94a683d27310b96c2fb3dff0321904afcbcbec3ce8ed069ddfecb0fb0c0abbc9a0341d549f97e7a0e9700865819ab36a90059a0ea95016283875b24043e194053a4e9b3750d84d0625a955d09c1e7693c372f2dc0542bee16550fe5d0795ddea771e4447bbf1800891111111111111f1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := gsmmap.Decode(msg.Components.Component[0], gsmmap.ContextVersion(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg, ok := p.(*gsmmap.USSDArg)
+	if !ok {
+		t.Fatalf("got %T, want *gsmmap.USSDArg", p)
+	}
+	if arg.DataCodingScheme != 0x0f || len(arg.String) != 140 {
+		t.Errorf("got DCS %#x, %d octets of string", arg.DataCodingScheme, len(arg.String))
+	}
+	verify.Values(t, "", arg.MSISDN, gsmmap.NewISDNAddress("1111111111111"))
+
+	text, err := arg.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Thank you for using the Golang USSD server."; !strings.HasPrefix(text, want) {
+		t.Errorf("got %q, want prefix %q", text, want)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	op := tcap.LookupOperation(gsmmap.ApplicationContext(tcap.NetworkLocUpContext, 3), gsmmap.UpdateLocation)
+	if op == nil {
+		t.Fatal("updateLocation not registered")
+	}
+	if op.Name != "updateLocation" || op.Class != tcap.Class1 || op.Timer != 30*time.Second {
+		t.Errorf("got %+v", op)
+	}
+
+	c := tcap.NewInvoke(0, -1, gsmmap.UpdateLocation, true,
+		mustHex("3022040800010121436587f981069118092143650406911809214375a604800205e08b00"))
+	p, err := op.Decode(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arg, ok := p.(*gsmmap.UpdateLocationArg); !ok || arg.IMSI != imsi {
+		t.Errorf("got %#v", p)
+	}
+
+	for _, c := range []struct {
+		ctx     uint8
+		version int
+		code    int
+		name    string
+	}{
+		{tcap.ShortMsgRelayContext, 2, gsmmap.MOForwardSM, "forwardSM"},
+		{tcap.ShortMsgRelayContext, 3, gsmmap.MOForwardSM, "mo-forwardSM"},
+		{tcap.NetworkLocUpContext, 3, gsmmap.InsertSubscriberData, "insertSubscriberData"},
+		{tcap.NetworkUnstructuredSsContext, 2, gsmmap.ProcessUnstructuredSSRequest, "processUnstructuredSS-Request"},
+	} {
+		op := tcap.LookupOperation(gsmmap.ApplicationContext(c.ctx, c.version), c.code)
+		if op == nil || op.Name != c.name {
+			t.Errorf("%d/%d: got %v, want %s", c.ctx, c.version, op, c.name)
+		}
+	}
+
+	if op := tcap.LookupOperation(gsmmap.ApplicationContext(tcap.NetworkLocUpContext, 3), gsmmap.AnyTimeInterrogation); op != nil {
+		t.Errorf("got %v in the other context", op)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		code        int
+		structured  gsmmap.Parameter
+		serialized  string
+	}{
+		{
+			"unknownSubscriber", gsmmap.UnknownSubscriber,
+			&gsmmap.UnknownSubscriberParam{Diagnostic: uint8p(gsmmap.GPRSEPSSubscriptionUnknown)},
+			"30030a0101",
+		}, {
+			"absentSubscriberSM", gsmmap.AbsentSubscriberSM,
+			&gsmmap.AbsentSubscriberSMParam{Diagnostic: intp(5), AdditionalDiagnostic: intp(6)},
+			"3006020105800106",
+		}, {
+			"absentSubscriber", gsmmap.AbsentSubscriber,
+			&gsmmap.AbsentSubscriberParam{Reason: uint8p(gsmmap.AbsentPurgedMS), Extra: mustHex("3000")},
+			"30053000800103",
+		}, {
+			"callBarred", gsmmap.CallBarred,
+			&gsmmap.CallBarredParam{Cause: uint8p(gsmmap.OperatorBarring)},
+			"0a0101",
+		}, {
+			"callBarred extensible", gsmmap.CallBarred,
+			&gsmmap.CallBarredParam{Cause: uint8p(gsmmap.BarringServiceActive), Extensible: true, UnauthorisedMessageOriginator: true},
+			"30050a01008100",
+		}, {
+			"sm-DeliveryFailure", gsmmap.SMDeliveryFailure,
+			&gsmmap.SMDeliveryFailureCause{Cause: gsmmap.MemoryCapacityExceeded, DiagnosticInfo: []byte{1, 2}},
+			"30070a010004020102",
+		}, {
+			"systemFailure", gsmmap.SystemFailure,
+			&gsmmap.SystemFailureParam{NetworkResource: uint8p(gsmmap.ResourceHLR)},
+			"0a0101",
+		}, {
+			"systemFailure extensible", gsmmap.SystemFailure,
+			&gsmmap.SystemFailureParam{NetworkResource: uint8p(gsmmap.ResourceVLR), Extensible: true, FailureCause: uint8p(0)},
+			"30060a0102810100",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+			got, err := gsmmap.DecodeError(c.code, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+
+	if got := gsmmap.ErrorString(gsmmap.AbsentSubscriber); got != "absentSubscriber" {
+		t.Errorf("got %s", got)
+	}
+	if got := gsmmap.ErrorString(100); got != "unknown(100)" {
+		t.Errorf("got %s", got)
+	}
+	if _, err := gsmmap.DecodeError(gsmmap.DataMissing, mustHex("3000")); err == nil {
+		t.Error("expected error with the error of which the parameter is not supported")
+	}
+	if _, err := (&gsmmap.SystemFailureParam{}).MarshalBinary(); err == nil {
+		t.Error("expected error without networkResource")
+	}
+}
+
+func TestDecodeReturnError(t *testing.T) {
+	c := tcap.NewReturnError(1, gsmmap.SystemFailure, true, nil)
+	param := &gsmmap.SystemFailureParam{NetworkResource: uint8p(gsmmap.ResourceVLR)}
+	if err := gsmmap.SetParameter(c, param); err != nil {
+		t.Fatal(err)
+	}
+	// MAP errors are in all the MAP Application Contexts, including the ones
+	// without the operations registered, but not in the others, e.g. CAP.
+	for _, acn := range []string{
+		gsmmap.ApplicationContext(tcap.ShortMsgGatewayContext, 3),
+		gsmmap.ApplicationContext(tcap.ResetContext, 1),
+		gsmmap.ApplicationContext(tcap.AnyTimeInfoHandlingContext, 3),
+	} {
+		if got := c.ErrorName(acn); got != "systemFailure" {
+			t.Errorf("got %q in %s", got, acn)
+		}
+	}
+	if got := c.ErrorName(""); got != "" {
+		t.Errorf("got %q in any Application Context", got)
+	}
+	if e := tcap.LookupError("0.4.0.0.1.21.3.4", gsmmap.SystemFailure); e != nil {
+		t.Errorf("got %q in CAP", e.Name)
+	}
+
+	p, err := gsmmap.Decode(c, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", p, param)
+
+	e := tcap.LookupError(gsmmap.ApplicationContext(tcap.NetworkLocUpContext, 3), gsmmap.SystemFailure)
+	if e == nil {
+		t.Fatal("systemFailure not registered")
+	}
+	if e.Name != "systemFailure" {
+		t.Errorf("got %q", e.Name)
+	}
+	if p, err := e.Decode(c); err != nil {
+		t.Error(err)
+	} else {
+		verify.Values(t, "", p, param)
+	}
+}
+
+func TestDialoguePDUs(t *testing.T) {
+	ref := &gsmmap.AddressString{NatureOfAddress: gsmmap.NatureInternational, NumberingPlan: gsmmap.PlanISDN, Digits: "123456789"}
+	for _, c := range []struct {
+		description string
+		structured  gsmmap.Parameter
+		serialized  string
+	}{
+		{
+			"map-open",
+			&gsmmap.OpenInfo{DestinationReference: ref, OriginationReference: ref},
+			"a01080069121436587f981069121436587f9",
+		}, {
+			"map-accept",
+			&gsmmap.AcceptInfo{},
+			"a100",
+		}, {
+			"map-close",
+			&gsmmap.CloseInfo{Extra: mustHex("3000")},
+			"a2023000",
+		}, {
+			"map-refuse",
+			&gsmmap.RefuseInfo{Reason: gsmmap.RefuseInvalidDestinationReference, AlternativeApplicationContext: "0.4.0.0.1.0.1.2"},
+			"a30c0a0101060704000001000102",
+		}, {
+			"map-userAbort",
+			&gsmmap.UserAbortInfo{Choice: gsmmap.UserSpecificReason},
+			"a4028000",
+		}, {
+			"map-userAbort with reason",
+			&gsmmap.UserAbortInfo{Choice: gsmmap.ApplicationProcedureCancellation, Reason: gsmmap.CallRelease},
+			"a403830103",
+		}, {
+			"map-providerAbort",
+			&gsmmap.ProviderAbortInfo{Reason: gsmmap.InvalidPDU},
+			"a5030a0101",
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+			got, err := gsmmap.DecodeDialoguePDU(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+
+	for _, s := range []string{"a600", "3000", "a300", "a4020500"} {
+		if _, err := gsmmap.DecodeDialoguePDU(mustHex(s)); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestDialogueInfo(t *testing.T) {
+	open := &gsmmap.OpenInfo{
+		DestinationReference: &gsmmap.AddressString{NatureOfAddress: gsmmap.NatureInternational, NumberingPlan: gsmmap.PlanLandMobile, Digits: imsi},
+		OriginationReference: msc,
+	}
+	ui, err := gsmmap.NewUserInformation(open)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := tcap.NewBeginInvoke(1, 0, gsmmap.UpdateLocation, mustHex("040800010121436587f9"))
+	msg.Dialogue = tcap.NewDialogue(tcap.DialogueAsID, 1, tcap.NewAARQ(1, tcap.NetworkLocUpContext, 3, ui), nil)
+	msg.SetLength()
+	b, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := tcap.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gsmmap.DialogueInfo(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify.Values(t, "", got, open)
+
+	if got, err := gsmmap.DialogueInfo(tcap.NewBeginInvoke(1, 0, gsmmap.UpdateLocation, nil)); got != nil || err != nil {
+		t.Errorf("got %v, %v without dialogue portion", got, err)
+	}
+}
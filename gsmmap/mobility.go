@@ -0,0 +1,575 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// UpdateLocationArg is the argument of updateLocation.
+type UpdateLocationArg struct {
+	IMSI      string
+	MSCNumber *AddressString
+	VLRNumber *AddressString
+	LMSI      []byte
+	// VLRCapability is the contents of vlr-Capability as they are encoded.
+	VLRCapability []byte
+	Extra         []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an UpdateLocationArg.
+func (u *UpdateLocationArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.TBCD(univ, tagOctetString, u.IMSI)
+		address(e, ctx, 1, u.MSCNumber)
+		address(e, univ, tagOctetString, u.VLRNumber)
+		e.Octets(ctx, 10, u.LMSI)
+		if u.VLRCapability != nil {
+			e.RawConstructed(ctx, 6, u.VLRCapability)
+		}
+		e.Raw(u.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an UpdateLocationArg.
+func (u *UpdateLocationArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*u = UpdateLocationArg{}
+	octets := 0
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagOctetString) && octets == 0:
+			u.IMSI = param.DecodeTBCD(e.Value)
+			octets++
+		case e.Is(univ, tagOctetString) && octets == 1:
+			if u.VLRNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+			octets++
+		case e.Is(ctx, 1):
+			if u.MSCNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 10):
+			u.LMSI = codec.Clone(e.Value)
+		case e.Is(ctx, 6):
+			u.VLRCapability = codec.Clone(e.Value)
+		default:
+			u.Extra = append(u.Extra, e.Raw...)
+		}
+	}
+
+	switch {
+	case u.IMSI == "":
+		return dec.Missing("imsi")
+	case u.MSCNumber == nil:
+		return dec.Missing("msc-Number")
+	case u.VLRNumber == nil:
+		return dec.Missing("vlr-Number")
+	}
+	return nil
+}
+
+// UpdateLocationRes is the result of updateLocation.
+type UpdateLocationRes struct {
+	HLRNumber            *AddressString
+	AddCapability        bool
+	PagingAreaCapability bool
+	Extra                []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an UpdateLocationRes.
+func (u *UpdateLocationRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		address(e, univ, tagOctetString, u.HLRNumber)
+		e.Raw(u.Extra)
+		e.Null(univ, tagNull, u.AddCapability)
+		e.Null(ctx, 0, u.PagingAreaCapability)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an UpdateLocationRes.
+func (u *UpdateLocationRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*u = UpdateLocationRes{}
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagOctetString):
+			if u.HLRNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(univ, tagNull):
+			u.AddCapability = true
+		case e.Is(ctx, 0):
+			u.PagingAreaCapability = true
+		default:
+			u.Extra = append(u.Extra, e.Raw...)
+		}
+	}
+
+	if u.HLRNumber == nil {
+		return dec.Missing("hlr-Number")
+	}
+	return nil
+}
+
+// CancellationType definitions.
+const (
+	UpdateProcedure uint8 = iota
+	SubscriptionWithdraw
+	InitialAttachProcedure
+)
+
+// CancelLocationArg is the argument of cancelLocation v3.
+type CancelLocationArg struct {
+	Identity Identity
+	// CancellationType is nil if not present.
+	CancellationType *uint8
+	Extra            []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a CancelLocationArg.
+func (c *CancelLocationArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, 3, func(e *encoder) {
+		c.Identity.encode(e)
+		if c.CancellationType != nil {
+			e.Octet(univ, tagEnumerated, *c.CancellationType)
+		}
+		e.Raw(c.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CancelLocationArg.
+func (c *CancelLocationArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, 3)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 {
+		return dec.Missing("identity")
+	}
+
+	*c = CancelLocationArg{}
+	if err := c.Identity.decode(es[0]); err != nil {
+		return err
+	}
+	for _, e := range es[1:] {
+		switch {
+		case e.Is(univ, tagEnumerated):
+			t, err := dec.Octet(e)
+			if err != nil {
+				return err
+			}
+			c.CancellationType = &t
+		default:
+			c.Extra = append(c.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// CancelLocationRes is the result of cancelLocation v3.
+type CancelLocationRes struct {
+	Extra []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a CancelLocationRes.
+func (c *CancelLocationRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Raw(c.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a CancelLocationRes.
+func (c *CancelLocationRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*c = CancelLocationRes{}
+	for _, e := range es {
+		c.Extra = append(c.Extra, e.Raw...)
+	}
+	return nil
+}
+
+// SubscriberStatus definitions.
+const (
+	ServiceGranted uint8 = iota
+	OperatorDeterminedBarring
+)
+
+// InsertSubscriberDataArg is the argument of insertSubscriberData, with the
+// part of SubscriberData.
+type InsertSubscriberDataArg struct {
+	IMSI     string
+	MSISDN   *AddressString
+	Category []byte
+	// SubscriberStatus is nil if not present.
+	SubscriberStatus  *uint8
+	BearerServiceList [][]byte
+	TeleserviceList   [][]byte
+	Extra             []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InsertSubscriberDataArg.
+func (i *InsertSubscriberDataArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.TBCD(ctx, 0, i.IMSI)
+		address(e, ctx, 1, i.MSISDN)
+		e.Octets(ctx, 2, i.Category)
+		if i.SubscriberStatus != nil {
+			e.Octet(ctx, 3, *i.SubscriberStatus)
+		}
+		encodeList(e, 4, i.BearerServiceList)
+		encodeList(e, 6, i.TeleserviceList)
+		e.Raw(i.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InsertSubscriberDataArg.
+func (i *InsertSubscriberDataArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*i = InsertSubscriberDataArg{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			i.IMSI = param.DecodeTBCD(e.Value)
+		case e.Is(ctx, 1):
+			if i.MSISDN, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 2):
+			i.Category = codec.Clone(e.Value)
+		case e.Is(ctx, 3):
+			s, err := dec.Octet(e)
+			if err != nil {
+				return err
+			}
+			i.SubscriberStatus = &s
+		case e.Is(ctx, 4):
+			if i.BearerServiceList, err = decodeList(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 6):
+			if i.TeleserviceList, err = decodeList(e); err != nil {
+				return err
+			}
+		default:
+			i.Extra = append(i.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// InsertSubscriberDataRes is the result of insertSubscriberData, with the
+// services not supported by the VLR.
+type InsertSubscriberDataRes struct {
+	TeleserviceList   [][]byte
+	BearerServiceList [][]byte
+	Extra             []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an InsertSubscriberDataRes.
+func (i *InsertSubscriberDataRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		encodeList(e, 1, i.TeleserviceList)
+		encodeList(e, 2, i.BearerServiceList)
+		e.Raw(i.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an InsertSubscriberDataRes.
+func (i *InsertSubscriberDataRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*i = InsertSubscriberDataRes{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 1):
+			if i.TeleserviceList, err = decodeList(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 2):
+			if i.BearerServiceList, err = decodeList(e); err != nil {
+				return err
+			}
+		default:
+			i.Extra = append(i.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// encodeList appends the SEQUENCE OF the service codes in OCTET STRING if not empty.
+func encodeList(e *encoder, tag int, list [][]byte) {
+	if len(list) == 0 {
+		return
+	}
+	e.Constructed(ctx, tag, func(e *encoder) {
+		for _, code := range list {
+			e.Octets(univ, tagOctetString, code)
+		}
+	})
+}
+
+func decodeList(e *ber.Element) ([][]byte, error) {
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([][]byte, 0, len(es))
+	for _, x := range es {
+		if !x.Is(univ, tagOctetString) {
+			return nil, dec.Unexpected(x)
+		}
+		list = append(list, codec.Clone(x.Value))
+	}
+	return list, nil
+}
+
+// SendAuthenticationInfoArg is the argument of sendAuthenticationInfo v3.
+type SendAuthenticationInfoArg struct {
+	IMSI                       string
+	NumberOfRequestedVectors   int
+	SegmentationProhibited     bool
+	ImmediateResponsePreferred bool
+	// ReSynchronisationInfo is nil if not present.
+	ReSynchronisationInfo *ReSynchronisationInfo
+	Extra                 []byte
+}
+
+// ReSynchronisationInfo is the RAND and AUTS to re-synchronise the sequence number.
+type ReSynchronisationInfo struct {
+	RAND []byte
+	AUTS []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a SendAuthenticationInfoArg.
+func (s *SendAuthenticationInfoArg) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, 3, func(e *encoder) {
+		e.TBCD(ctx, 0, s.IMSI)
+		e.Integer(univ, tagInteger, s.NumberOfRequestedVectors)
+		e.Null(univ, tagNull, s.SegmentationProhibited)
+		e.Null(ctx, 1, s.ImmediateResponsePreferred)
+		if r := s.ReSynchronisationInfo; r != nil {
+			e.Constructed(univ, tagSequence, func(e *encoder) {
+				e.Octets(univ, tagOctetString, r.RAND)
+				e.Octets(univ, tagOctetString, r.AUTS)
+			})
+		}
+		e.Raw(s.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendAuthenticationInfoArg.
+func (s *SendAuthenticationInfoArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, 3)
+	if err != nil {
+		return err
+	}
+
+	*s = SendAuthenticationInfoArg{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			s.IMSI = param.DecodeTBCD(e.Value)
+		case e.Is(univ, tagInteger):
+			s.NumberOfRequestedVectors = ber.DecodeInteger(e.Value)
+		case e.Is(univ, tagNull):
+			s.SegmentationProhibited = true
+		case e.Is(ctx, 1):
+			s.ImmediateResponsePreferred = true
+		case e.Is(univ, tagSequence):
+			xs, err := ber.ReadElements(e.Value)
+			if err != nil {
+				return err
+			}
+			if len(xs) != 2 {
+				return dec.Unexpected(e)
+			}
+			s.ReSynchronisationInfo = &ReSynchronisationInfo{RAND: codec.Clone(xs[0].Value), AUTS: codec.Clone(xs[1].Value)}
+		default:
+			s.Extra = append(s.Extra, e.Raw...)
+		}
+	}
+
+	if s.IMSI == "" {
+		return dec.Missing("imsi")
+	}
+	return nil
+}
+
+// AuthenticationTriplet is the authentication vector for GSM.
+type AuthenticationTriplet struct {
+	RAND []byte
+	SRES []byte
+	Kc   []byte
+}
+
+// AuthenticationQuintuplet is the authentication vector for UMTS.
+type AuthenticationQuintuplet struct {
+	RAND []byte
+	XRES []byte
+	CK   []byte
+	IK   []byte
+	AUTN []byte
+}
+
+// TripletList is the list of the authentication triplets, as the result of
+// sendAuthenticationInfo v2.
+type TripletList []*AuthenticationTriplet
+
+// MarshalBinary returns the byte sequence generated from a TripletList.
+func (t *TripletList) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		encodeTriplets(e, *t)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a TripletList.
+func (t *TripletList) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	*t, err = decodeTriplets(es)
+	return err
+}
+
+// SendAuthenticationInfoRes is the result of sendAuthenticationInfo v3, with
+// either Triplets or Quintuplets.
+type SendAuthenticationInfoRes struct {
+	Triplets    []*AuthenticationTriplet
+	Quintuplets []*AuthenticationQuintuplet
+	Extra       []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a SendAuthenticationInfoRes.
+func (s *SendAuthenticationInfoRes) MarshalBinary() ([]byte, error) {
+	return codec.Tagged(ctx, 3, func(e *encoder) {
+		switch {
+		case s.Triplets != nil:
+			e.Constructed(ctx, 0, func(e *encoder) {
+				encodeTriplets(e, s.Triplets)
+			})
+		case s.Quintuplets != nil:
+			e.Constructed(ctx, 1, func(e *encoder) {
+				for _, q := range s.Quintuplets {
+					e.Constructed(univ, tagSequence, func(e *encoder) {
+						for _, v := range [][]byte{q.RAND, q.XRES, q.CK, q.IK, q.AUTN} {
+							e.Octets(univ, tagOctetString, v)
+						}
+					})
+				}
+			})
+		}
+		e.Raw(s.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SendAuthenticationInfoRes.
+func (s *SendAuthenticationInfoRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadTagged(b, ctx, 3)
+	if err != nil {
+		return err
+	}
+
+	*s = SendAuthenticationInfoRes{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			xs, err := ber.ReadElements(e.Value)
+			if err != nil {
+				return err
+			}
+			if s.Triplets, err = decodeTriplets(xs); err != nil {
+				return err
+			}
+		case e.Is(ctx, 1):
+			xs, err := ber.ReadElements(e.Value)
+			if err != nil {
+				return err
+			}
+			s.Quintuplets = make([]*AuthenticationQuintuplet, 0, len(xs))
+			for _, x := range xs {
+				vs, err := decodeVector(x, 5)
+				if err != nil {
+					return err
+				}
+				s.Quintuplets = append(s.Quintuplets, &AuthenticationQuintuplet{
+					RAND: vs[0], XRES: vs[1], CK: vs[2], IK: vs[3], AUTN: vs[4],
+				})
+			}
+		default:
+			s.Extra = append(s.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+func encodeTriplets(e *encoder, ts []*AuthenticationTriplet) {
+	for _, t := range ts {
+		e.Constructed(univ, tagSequence, func(e *encoder) {
+			e.Octets(univ, tagOctetString, t.RAND)
+			e.Octets(univ, tagOctetString, t.SRES)
+			e.Octets(univ, tagOctetString, t.Kc)
+		})
+	}
+}
+
+func decodeTriplets(es []*ber.Element) ([]*AuthenticationTriplet, error) {
+	ts := make([]*AuthenticationTriplet, 0, len(es))
+	for _, e := range es {
+		vs, err := decodeVector(e, 3)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, &AuthenticationTriplet{RAND: vs[0], SRES: vs[1], Kc: vs[2]})
+	}
+	return ts, nil
+}
+
+// decodeVector decodes e as a SEQUENCE of n OCTET STRINGs, ignoring the rest.
+func decodeVector(e *ber.Element, n int) ([][]byte, error) {
+	if !e.Is(univ, tagSequence) {
+		return nil, dec.Unexpected(e)
+	}
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return nil, err
+	}
+	if len(es) < n {
+		return nil, dec.Unexpected(e)
+	}
+
+	vs := make([][]byte, n)
+	for i := range vs {
+		if !es[i].Is(univ, tagOctetString) {
+			return nil, dec.Unexpected(es[i])
+		}
+		vs[i] = codec.Clone(es[i].Value)
+	}
+	return vs, nil
+}
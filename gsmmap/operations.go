@@ -0,0 +1,130 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+// The invocation timers in 3GPP TS 29.002, which are the upper bounds of the
+// ranges: m is 15 to 30 seconds, and ml is 1 to 10 minutes.
+const (
+	timerMedium     = 30 * time.Second
+	timerMediumLong = 10 * time.Minute
+)
+
+// operations are the operations with the Application Contexts they are used
+// in. They are registered in tcap.DefaultRegistry in the versions that have
+// the Parameters in arguments, and the errors are registered in all the
+// Application Contexts of MAP.
+var operations = []struct {
+	contexts []uint8
+	tcap.Operation
+}{
+	{
+		[]uint8{tcap.NetworkLocUpContext},
+		tcap.Operation{
+			Name: "updateLocation", Code: UpdateLocation, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{SystemFailure, DataMissing, UnexpectedDataValue, UnknownSubscriber, RoamingNotAllowed},
+		},
+	}, {
+		[]uint8{tcap.LocationCancellationContext},
+		tcap.Operation{
+			Name: "cancelLocation", Code: CancelLocation, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{DataMissing, UnexpectedDataValue},
+		},
+	}, {
+		[]uint8{tcap.SubscriberDataMngtContext, tcap.NetworkLocUpContext},
+		tcap.Operation{
+			Name: "insertSubscriberData", Code: InsertSubscriberData, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{DataMissing, UnexpectedDataValue, UnidentifiedSubscriber},
+		},
+	}, {
+		[]uint8{tcap.ShortMsgMTRelayContext},
+		tcap.Operation{
+			Name: "mt-forwardSM", Code: MTForwardSM, Class: tcap.Class1, Timer: timerMediumLong,
+			Errors: []int{
+				SystemFailure, DataMissing, UnexpectedDataValue, FacilityNotSupported, UnidentifiedSubscriber,
+				IllegalSubscriber, IllegalEquipment, SubscriberBusyForMTSMS, SMDeliveryFailure, AbsentSubscriberSM,
+			},
+		},
+	}, {
+		[]uint8{tcap.ShortMsgGatewayContext},
+		tcap.Operation{
+			Name: "sendRoutingInfoForSM", Code: SendRoutingInfoForSM, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{
+				SystemFailure, DataMissing, UnexpectedDataValue, FacilityNotSupported, UnknownSubscriber,
+				TeleserviceNotProvisioned, CallBarred, AbsentSubscriberSM,
+			},
+		},
+	}, {
+		[]uint8{tcap.ShortMsgRelayContext},
+		tcap.Operation{
+			Name: "mo-forwardSM", Code: MOForwardSM, Class: tcap.Class1, Timer: timerMediumLong,
+			Errors: []int{SystemFailure, UnexpectedDataValue, FacilityNotSupported, SMDeliveryFailure},
+		},
+	}, {
+		[]uint8{tcap.InfoRetrievalContext},
+		tcap.Operation{
+			Name: "sendAuthenticationInfo", Code: SendAuthenticationInfo, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{SystemFailure, DataMissing, UnexpectedDataValue, UnknownSubscriber},
+		},
+	}, {
+		[]uint8{tcap.NetworkUnstructuredSsContext},
+		tcap.Operation{
+			Name: "processUnstructuredSS-Request", Code: ProcessUnstructuredSSRequest, Class: tcap.Class1, Timer: timerMediumLong,
+			Errors: []int{SystemFailure, DataMissing, UnexpectedDataValue, UnknownAlphabet, CallBarred},
+		},
+	}, {
+		[]uint8{tcap.AnyTimeInfoEnquiryContext},
+		tcap.Operation{
+			Name: "anyTimeInterrogation", Code: AnyTimeInterrogation, Class: tcap.Class1, Timer: timerMedium,
+			Errors: []int{SystemFailure, ATINotAllowed, DataMissing, UnexpectedDataValue, UnknownSubscriber},
+		},
+	},
+}
+
+// maxVersion is the latest version of the Application Contexts of MAP.
+const maxVersion = 4
+
+func init() {
+	for _, o := range operations {
+		for k, arg := range arguments {
+			if k.opCode != o.Code {
+				continue
+			}
+
+			op := o.Operation
+			op.NewArgument, op.NewResult = arg, results[k]
+			if k.opCode == MOForwardSM && k.version < 3 {
+				op.Name = "forwardSM"
+			}
+			for _, ctx := range o.contexts {
+				tcap.RegisterOperation(ApplicationContext(ctx, k.version), &op)
+			}
+		}
+	}
+
+	// The Error Codes are common to all the Application Contexts of MAP, but
+	// not to the other protocols such as CAP, so they are registered in each
+	// of them in all the versions instead of in any Application Context.
+	for code, name := range errorNames {
+		e := &tcap.Error{Name: name, Code: code, NewParameter: errorParameters[code]}
+		for ctx := tcap.NetworkLocUpContext; ctx <= tcap.AnyTimeInfoHandlingContext; ctx++ {
+			for v := 1; v <= maxVersion; v++ {
+				tcap.RegisterError(ApplicationContext(ctx, v), e)
+			}
+		}
+	}
+}
+
+// ApplicationContext returns the MAP Application Context Name of the context
+// and the version in the dotted notation, which is used in tcap.Registry.
+func ApplicationContext(ctx uint8, version int) string {
+	return fmt.Sprintf("0.4.0.0.1.0.%d.%d", ctx, version)
+}
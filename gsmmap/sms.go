@@ -0,0 +1,310 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// RoutingInfoForSMArg is the argument of sendRoutingInfoForSM.
+type RoutingInfoForSMArg struct {
+	MSISDN               *AddressString
+	SMRPPRI              bool
+	ServiceCentreAddress *AddressString
+	GPRSSupportIndicator bool
+	IMSI                 string
+	Extra                []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a RoutingInfoForSMArg.
+func (r *RoutingInfoForSMArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		address(e, ctx, 0, r.MSISDN)
+		e.Boolean(ctx, 1, r.SMRPPRI)
+		address(e, ctx, 2, r.ServiceCentreAddress)
+		e.Raw(r.Extra)
+		e.Null(ctx, 7, r.GPRSSupportIndicator)
+		e.TBCD(ctx, 12, r.IMSI)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RoutingInfoForSMArg.
+func (r *RoutingInfoForSMArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*r = RoutingInfoForSMArg{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			if r.MSISDN, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 1):
+			r.SMRPPRI = codec.Boolean(e)
+		case e.Is(ctx, 2):
+			if r.ServiceCentreAddress, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 7):
+			r.GPRSSupportIndicator = true
+		case e.Is(ctx, 12):
+			r.IMSI = param.DecodeTBCD(e.Value)
+		default:
+			r.Extra = append(r.Extra, e.Raw...)
+		}
+	}
+
+	switch {
+	case r.MSISDN == nil:
+		return dec.Missing("msisdn")
+	case r.ServiceCentreAddress == nil:
+		return dec.Missing("serviceCentreAddress")
+	}
+	return nil
+}
+
+// RoutingInfoForSMRes is the result of sendRoutingInfoForSM.
+type RoutingInfoForSMRes struct {
+	IMSI         string
+	LocationInfo LocationInfoWithLMSI
+	Extra        []byte
+}
+
+// LocationInfoWithLMSI is the location of the subscriber to deliver the short message.
+type LocationInfoWithLMSI struct {
+	NetworkNodeNumber *AddressString
+	LMSI              []byte
+	GPRSNodeIndicator bool
+	Extra             []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a RoutingInfoForSMRes.
+func (r *RoutingInfoForSMRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.TBCD(univ, tagOctetString, r.IMSI)
+		e.Constructed(ctx, 0, func(e *encoder) {
+			l := r.LocationInfo
+			address(e, ctx, 1, l.NetworkNodeNumber)
+			e.Octets(univ, tagOctetString, l.LMSI)
+			e.Raw(l.Extra)
+			e.Null(ctx, 5, l.GPRSNodeIndicator)
+		})
+		e.Raw(r.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a RoutingInfoForSMRes.
+func (r *RoutingInfoForSMRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*r = RoutingInfoForSMRes{}
+	hasLocation := false
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagOctetString):
+			r.IMSI = param.DecodeTBCD(e.Value)
+		case e.Is(ctx, 0):
+			if err := r.LocationInfo.decode(e); err != nil {
+				return err
+			}
+			hasLocation = true
+		default:
+			r.Extra = append(r.Extra, e.Raw...)
+		}
+	}
+
+	switch {
+	case r.IMSI == "":
+		return dec.Missing("imsi")
+	case !hasLocation:
+		return dec.Missing("locationInfoWithLMSI")
+	}
+	return nil
+}
+
+func (l *LocationInfoWithLMSI) decode(e *ber.Element) error {
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 1):
+			if l.NetworkNodeNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(univ, tagOctetString):
+			l.LMSI = codec.Clone(e.Value)
+		case e.Is(ctx, 5):
+			l.GPRSNodeIndicator = true
+		default:
+			l.Extra = append(l.Extra, e.Raw...)
+		}
+	}
+
+	if l.NetworkNodeNumber == nil {
+		return dec.Missing("networkNode-Number")
+	}
+	return nil
+}
+
+// SMRPDA is the destination address of the short message, which is one of
+// IMSI, LMSI and ServiceCentreAddress, or noSM-RP-DA if none of them is set.
+type SMRPDA struct {
+	IMSI                 string
+	LMSI                 []byte
+	ServiceCentreAddress *AddressString
+}
+
+// SMRPOA is the originating address of the short message, which is one of
+// MSISDN and ServiceCentreAddress, or noSM-RP-OA if none of them is set.
+type SMRPOA struct {
+	MSISDN               *AddressString
+	ServiceCentreAddress *AddressString
+}
+
+// ForwardSMArg is the argument of mo-forwardSM, mt-forwardSM and forwardSM
+// in v1/v2.
+type ForwardSMArg struct {
+	DA SMRPDA
+	OA SMRPOA
+	// UI is the short message TPDU in SM-RP-UI.
+	UI []byte
+	// MoreMessagesToSend is used in mt-forwardSM and forwardSM.
+	MoreMessagesToSend bool
+	// IMSI is used in mo-forwardSM v3.
+	IMSI  string
+	Extra []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ForwardSMArg.
+func (f *ForwardSMArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		switch da := f.DA; {
+		case da.IMSI != "":
+			e.TBCD(ctx, 0, da.IMSI)
+		case da.LMSI != nil:
+			e.Octets(ctx, 1, da.LMSI)
+		case da.ServiceCentreAddress != nil:
+			address(e, ctx, 4, da.ServiceCentreAddress)
+		default:
+			e.Null(ctx, 5, true)
+		}
+
+		switch oa := f.OA; {
+		case oa.MSISDN != nil:
+			address(e, ctx, 2, oa.MSISDN)
+		case oa.ServiceCentreAddress != nil:
+			address(e, ctx, 4, oa.ServiceCentreAddress)
+		default:
+			e.Null(ctx, 5, true)
+		}
+
+		e.Octets(univ, tagOctetString, f.UI)
+		e.Null(univ, tagNull, f.MoreMessagesToSend)
+		e.Raw(f.Extra)
+		e.TBCD(univ, tagOctetString, f.IMSI)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ForwardSMArg.
+func (f *ForwardSMArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) < 3 {
+		return dec.Missing("sm-RP-UI")
+	}
+
+	*f = ForwardSMArg{}
+	switch e := es[0]; {
+	case e.Is(ctx, 0):
+		f.DA.IMSI = param.DecodeTBCD(e.Value)
+	case e.Is(ctx, 1):
+		f.DA.LMSI = codec.Clone(e.Value)
+	case e.Is(ctx, 4):
+		if f.DA.ServiceCentreAddress, err = decodeAddressString(e); err != nil {
+			return err
+		}
+	case e.Is(ctx, 5):
+	default:
+		return dec.Unexpected(e)
+	}
+
+	switch e := es[1]; {
+	case e.Is(ctx, 2):
+		if f.OA.MSISDN, err = decodeAddressString(e); err != nil {
+			return err
+		}
+	case e.Is(ctx, 4):
+		if f.OA.ServiceCentreAddress, err = decodeAddressString(e); err != nil {
+			return err
+		}
+	case e.Is(ctx, 5):
+	default:
+		return dec.Unexpected(e)
+	}
+
+	if !es[2].Is(univ, tagOctetString) {
+		return dec.Unexpected(es[2])
+	}
+	f.UI = codec.Clone(es[2].Value)
+
+	for _, e := range es[3:] {
+		switch {
+		case e.Is(univ, tagNull):
+			f.MoreMessagesToSend = true
+		case e.Is(univ, tagOctetString):
+			f.IMSI = param.DecodeTBCD(e.Value)
+		default:
+			f.Extra = append(f.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// ForwardSMRes is the result of mo-forwardSM and mt-forwardSM v3.
+type ForwardSMRes struct {
+	// UI is the short message TPDU in SM-RP-UI, which is nil if not present.
+	UI    []byte
+	Extra []byte
+}
+
+// MarshalBinary returns the byte sequence generated from a ForwardSMRes.
+func (f *ForwardSMRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Octets(univ, tagOctetString, f.UI)
+		e.Raw(f.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a ForwardSMRes.
+func (f *ForwardSMRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*f = ForwardSMRes{}
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagOctetString):
+			f.UI = codec.Clone(e.Value)
+		default:
+			f.Extra = append(f.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// AnyTimeInterrogationArg is the argument of anyTimeInterrogation.
+type AnyTimeInterrogationArg struct {
+	// The subscriber is identified by either IMSI or MSISDN.
+	IMSI          string
+	MSISDN        *AddressString
+	RequestedInfo RequestedInfo
+	GsmSCFAddress *AddressString
+	Extra         []byte
+}
+
+// RequestedInfo is the information requested in anyTimeInterrogation.
+type RequestedInfo struct {
+	LocationInformation bool
+	SubscriberState     bool
+	CurrentLocation     bool
+	MSClassmark         bool
+	IMEI                bool
+	MNPRequestedInfo    bool
+	Extra               []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an AnyTimeInterrogationArg.
+func (a *AnyTimeInterrogationArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Constructed(ctx, 0, func(e *encoder) {
+			if a.MSISDN != nil {
+				address(e, ctx, 1, a.MSISDN)
+				return
+			}
+			e.TBCD(ctx, 0, a.IMSI)
+		})
+		e.Constructed(ctx, 1, func(e *encoder) {
+			r := a.RequestedInfo
+			e.Null(ctx, 0, r.LocationInformation)
+			e.Null(ctx, 1, r.SubscriberState)
+			e.Null(ctx, 3, r.CurrentLocation)
+			e.Null(ctx, 5, r.MSClassmark)
+			e.Null(ctx, 6, r.IMEI)
+			e.Null(ctx, 7, r.MNPRequestedInfo)
+			e.Raw(r.Extra)
+		})
+		address(e, ctx, 3, a.GsmSCFAddress)
+		e.Raw(a.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AnyTimeInterrogationArg.
+func (a *AnyTimeInterrogationArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+
+	*a = AnyTimeInterrogationArg{}
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			id, _, err := ber.ReadElement(e.Value)
+			if err != nil {
+				return err
+			}
+			switch {
+			case id.Is(ctx, 0):
+				a.IMSI = param.DecodeTBCD(id.Value)
+			case id.Is(ctx, 1):
+				if a.MSISDN, err = decodeAddressString(id); err != nil {
+					return err
+				}
+			default:
+				return dec.Unexpected(id)
+			}
+		case e.Is(ctx, 1):
+			if err := a.RequestedInfo.decode(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 3):
+			if a.GsmSCFAddress, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		default:
+			a.Extra = append(a.Extra, e.Raw...)
+		}
+	}
+
+	switch {
+	case a.IMSI == "" && a.MSISDN == nil:
+		return dec.Missing("subscriberIdentity")
+	case a.GsmSCFAddress == nil:
+		return dec.Missing("gsmSCF-Address")
+	}
+	return nil
+}
+
+func (r *RequestedInfo) decode(e *ber.Element) error {
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			r.LocationInformation = true
+		case e.Is(ctx, 1):
+			r.SubscriberState = true
+		case e.Is(ctx, 3):
+			r.CurrentLocation = true
+		case e.Is(ctx, 5):
+			r.MSClassmark = true
+		case e.Is(ctx, 6):
+			r.IMEI = true
+		case e.Is(ctx, 7):
+			r.MNPRequestedInfo = true
+		default:
+			r.Extra = append(r.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// AnyTimeInterrogationRes is the result of anyTimeInterrogation.
+type AnyTimeInterrogationRes struct {
+	SubscriberInfo SubscriberInfo
+	Extra          []byte
+}
+
+// SubscriberInfo is the information of the subscriber.
+type SubscriberInfo struct {
+	// LocationInformation is nil if not present.
+	LocationInformation *LocationInformation
+	// SubscriberState is nil if not present.
+	SubscriberState *SubscriberState
+	IMEI            string
+	Extra           []byte
+}
+
+// LocationInformation is the location of the subscriber in the CS domain.
+type LocationInformation struct {
+	// AgeOfLocationInformation is the minutes since the last update, which is
+	// nil if not present.
+	AgeOfLocationInformation *int
+	GeographicalInformation  []byte
+	VLRNumber                *AddressString
+	LocationNumber           []byte
+	// Either CellGlobalID (or Service Area ID) or LAI is present.
+	CellGlobalID             []byte
+	LAI                      []byte
+	MSCNumber                *AddressString
+	CurrentLocationRetrieved bool
+	SAIPresent               bool
+	Extra                    []byte
+}
+
+// SubscriberState definitions.
+const (
+	AssumedIdle uint8 = iota
+	CamelBusy
+	NetDetNotReachable
+	NotProvidedFromVLR
+)
+
+// SubscriberState is the state of the subscriber.
+type SubscriberState struct {
+	State uint8
+	// NotReachableReason is used with NetDetNotReachable.
+	NotReachableReason uint8
+}
+
+// MarshalBinary returns the byte sequence generated from an AnyTimeInterrogationRes.
+func (a *AnyTimeInterrogationRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Constructed(univ, tagSequence, func(e *encoder) {
+			s := a.SubscriberInfo
+			if l := s.LocationInformation; l != nil {
+				e.Constructed(ctx, 0, l.encode)
+			}
+			if st := s.SubscriberState; st != nil {
+				e.Constructed(ctx, 1, func(e *encoder) {
+					switch st.State {
+					case AssumedIdle:
+						e.Null(ctx, 0, true)
+					case CamelBusy:
+						e.Null(ctx, 1, true)
+					case NetDetNotReachable:
+						e.Octet(univ, tagEnumerated, st.NotReachableReason)
+					default:
+						e.Null(ctx, 2, true)
+					}
+				})
+			}
+			e.Raw(s.Extra)
+			e.TBCD(ctx, 5, s.IMEI)
+		})
+		e.Raw(a.Extra)
+	})
+}
+
+func (l *LocationInformation) encode(e *encoder) {
+	if l.AgeOfLocationInformation != nil {
+		e.Integer(univ, tagInteger, *l.AgeOfLocationInformation)
+	}
+	e.Octets(ctx, 0, l.GeographicalInformation)
+	address(e, ctx, 1, l.VLRNumber)
+	e.Octets(ctx, 2, l.LocationNumber)
+	switch {
+	case l.CellGlobalID != nil:
+		e.Constructed(ctx, 3, func(e *encoder) {
+			e.Octets(ctx, 0, l.CellGlobalID)
+		})
+	case l.LAI != nil:
+		e.Constructed(ctx, 3, func(e *encoder) {
+			e.Octets(ctx, 1, l.LAI)
+		})
+	}
+	e.Raw(l.Extra)
+	address(e, ctx, 6, l.MSCNumber)
+	e.Null(ctx, 8, l.CurrentLocationRetrieved)
+	e.Null(ctx, 9, l.SAIPresent)
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an AnyTimeInterrogationRes.
+func (a *AnyTimeInterrogationRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) == 0 || !es[0].Is(univ, tagSequence) {
+		return dec.Missing("subscriberInfo")
+	}
+
+	*a = AnyTimeInterrogationRes{}
+	if err := a.SubscriberInfo.decode(es[0]); err != nil {
+		return err
+	}
+	for _, e := range es[1:] {
+		a.Extra = append(a.Extra, e.Raw...)
+	}
+	return nil
+}
+
+func (s *SubscriberInfo) decode(e *ber.Element) error {
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range es {
+		switch {
+		case e.Is(ctx, 0):
+			s.LocationInformation = &LocationInformation{}
+			if err := s.LocationInformation.decode(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 1):
+			st, _, err := ber.ReadElement(e.Value)
+			if err != nil {
+				return err
+			}
+			switch {
+			case st.Is(ctx, 0):
+				s.SubscriberState = &SubscriberState{State: AssumedIdle}
+			case st.Is(ctx, 1):
+				s.SubscriberState = &SubscriberState{State: CamelBusy}
+			case st.Is(univ, tagEnumerated):
+				reason, err := dec.Octet(st)
+				if err != nil {
+					return err
+				}
+				s.SubscriberState = &SubscriberState{State: NetDetNotReachable, NotReachableReason: reason}
+			case st.Is(ctx, 2):
+				s.SubscriberState = &SubscriberState{State: NotProvidedFromVLR}
+			default:
+				return dec.Unexpected(st)
+			}
+		case e.Is(ctx, 5):
+			s.IMEI = param.DecodeTBCD(e.Value)
+		default:
+			s.Extra = append(s.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+func (l *LocationInformation) decode(e *ber.Element) error {
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range es {
+		switch {
+		case e.Is(univ, tagInteger):
+			age := ber.DecodeInteger(e.Value)
+			l.AgeOfLocationInformation = &age
+		case e.Is(ctx, 0):
+			l.GeographicalInformation = codec.Clone(e.Value)
+		case e.Is(ctx, 1):
+			if l.VLRNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 2):
+			l.LocationNumber = codec.Clone(e.Value)
+		case e.Is(ctx, 3):
+			id, _, err := ber.ReadElement(e.Value)
+			if err != nil {
+				return err
+			}
+			switch {
+			case id.Is(ctx, 0):
+				l.CellGlobalID = codec.Clone(id.Value)
+			case id.Is(ctx, 1):
+				l.LAI = codec.Clone(id.Value)
+			default:
+				return dec.Unexpected(id)
+			}
+		case e.Is(ctx, 6):
+			if l.MSCNumber, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		case e.Is(ctx, 8):
+			l.CurrentLocationRetrieved = true
+		case e.Is(ctx, 9):
+			l.SAIPresent = true
+		default:
+			l.Extra = append(l.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gsmmap
+
+import (
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/internal/codec"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// USSDArg is the argument of processUnstructuredSS-Request.
+type USSDArg struct {
+	DataCodingScheme uint8
+	// String is the USSD string encoded as indicated by DataCodingScheme.
+	String []byte
+	// AlertingPattern is nil if not present.
+	AlertingPattern []byte
+	MSISDN          *AddressString
+	Extra           []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an USSDArg.
+func (u *USSDArg) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Octet(univ, tagOctetString, u.DataCodingScheme)
+		e.Octets(univ, tagOctetString, u.String)
+		e.Octets(univ, tagOctetString, u.AlertingPattern)
+		address(e, ctx, 0, u.MSISDN)
+		e.Raw(u.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an USSDArg.
+func (u *USSDArg) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) < 2 {
+		return dec.Missing("ussd-String")
+	}
+
+	*u = USSDArg{}
+	if u.DataCodingScheme, u.String, err = decodeUSSDString(es); err != nil {
+		return err
+	}
+	for _, e := range es[2:] {
+		switch {
+		case e.Is(univ, tagOctetString):
+			u.AlertingPattern = codec.Clone(e.Value)
+		case e.Is(ctx, 0):
+			if u.MSISDN, err = decodeAddressString(e); err != nil {
+				return err
+			}
+		default:
+			u.Extra = append(u.Extra, e.Raw...)
+		}
+	}
+	return nil
+}
+
+// Text returns String in USSDArg decoded as indicated by DataCodingScheme.
+func (u *USSDArg) Text() (string, error) {
+	return param.DecodeUSSD(u.DataCodingScheme, u.String)
+}
+
+// SetText sets s encoded in the GSM 7 bit default alphabet as String in USSDArg.
+func (u *USSDArg) SetText(s string) error {
+	b, err := param.EncodeUSSD(s)
+	if err != nil {
+		return err
+	}
+	u.DataCodingScheme, u.String = param.DefaultAlphabet, b
+	return nil
+}
+
+// USSDRes is the result of processUnstructuredSS-Request.
+type USSDRes struct {
+	DataCodingScheme uint8
+	// String is the USSD string encoded as indicated by DataCodingScheme.
+	String []byte
+	Extra  []byte
+}
+
+// MarshalBinary returns the byte sequence generated from an USSDRes.
+func (u *USSDRes) MarshalBinary() ([]byte, error) {
+	return codec.Sequence(func(e *encoder) {
+		e.Octet(univ, tagOctetString, u.DataCodingScheme)
+		e.Octets(univ, tagOctetString, u.String)
+		e.Raw(u.Extra)
+	})
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in an USSDRes.
+func (u *USSDRes) UnmarshalBinary(b []byte) error {
+	es, err := dec.ReadSequence(b)
+	if err != nil {
+		return err
+	}
+	if len(es) < 2 {
+		return dec.Missing("ussd-String")
+	}
+
+	*u = USSDRes{}
+	if u.DataCodingScheme, u.String, err = decodeUSSDString(es); err != nil {
+		return err
+	}
+	for _, e := range es[2:] {
+		u.Extra = append(u.Extra, e.Raw...)
+	}
+	return nil
+}
+
+// decodeUSSDString decodes the data coding scheme and the string at the
+// beginning of es.
+func decodeUSSDString(es []*ber.Element) (uint8, []byte, error) {
+	if !es[0].Is(univ, tagOctetString) {
+		return 0, nil, dec.Unexpected(es[0])
+	}
+	dcs, err := dec.Octet(es[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	if !es[1].Is(univ, tagOctetString) {
+		return 0, nil, dec.Unexpected(es[1])
+	}
+	return dcs, codec.Clone(es[1].Value), nil
+}
+
+// Text returns String in USSDRes decoded as indicated by DataCodingScheme.
+func (u *USSDRes) Text() (string, error) {
+	return param.DecodeUSSD(u.DataCodingScheme, u.String)
+}
+
+// SetText sets s encoded in the GSM 7 bit default alphabet as String in USSDRes.
+func (u *USSDRes) SetText(s string) error {
+	b, err := param.EncodeUSSD(s)
+	if err != nil {
+		return err
+	}
+	u.DataCodingScheme, u.String = param.DefaultAlphabet, b
+	return nil
+}
@@ -107,41 +107,52 @@ func NewIE(tag Tag, value []byte) *IE {
 
 // MarshalBinary returns the byte sequence generated from a IE instance.
 func (i *IE) MarshalBinary() ([]byte, error) {
-	b := make([]byte, i.MarshalLen())
-	if err := i.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return i.AppendBinary(make([]byte, 0, i.MarshalLen()))
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (i *IE) MarshalTo(b []byte) error {
-	if len(b) < 2 {
-		return io.ErrUnexpectedEOF
-	}
+	return marshalTo(b, i.AppendBinary)
+}
 
-	b[0] = uint8(i.Tag)
-	b[1] = i.Length
-	copy(b[2:i.MarshalLen()], i.Value)
-	fmt.Printf("IE::::: -> %x\n", b)
-	return nil
+// AppendBinary appends the byte sequence generated from an IE instance to b.
+//
+// The length is computed from the Value, not taken from the Length field.
+func (i *IE) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, uint8(i.Tag))
+	b = appendLength(b, len(i.Value))
+	return append(b, i.Value...), nil
+}
+
+// appendIEs appends the IEs given in order, skipping the nil ones.
+func appendIEs(b []byte, ies ...*IE) ([]byte, error) {
+	var err error
+	for _, ie := range ies {
+		if ie == nil {
+			continue
+		}
+		if b, err = ie.AppendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
 }
 
 // ParseMultiIEs parses multiple (unspecified number of) IEs to []*IE at a time.
 func ParseMultiIEs(b []byte) ([]*IE, error) {
 	var ies []*IE
 
-	// fmt.Printf("%x\n", b)
 	for len(b) != 0 {
-
-		i, err := ParseIE(b)
-		// fmt.Println(i, err)
+		_, _, n, err := readTLV(b)
+		if err != nil {
+			return nil, err
+		}
+		i, err := ParseIE(b[:n])
 		if err != nil {
 			return nil, err
 		}
 		ies = append(ies, i)
-		b = b[i.MarshalLen():]
-		continue
+		b = b[n:]
 	}
 	return ies, nil
 }
@@ -156,20 +167,18 @@ func ParseIE(b []byte) (*IE, error) {
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an IE.
+//
+// Both the short and the long form of the length are accepted. The Length field
+// only keeps the lowest octet of the length, which the Value always has in full.
 func (i *IE) UnmarshalBinary(b []byte) error {
-	l := len(b)
-	// fmt.Println("IE:len", l)
-	if l < 3 {
-		return io.ErrUnexpectedEOF
+	tag, v, _, err := readTLV(b)
+	if err != nil {
+		return err
 	}
 
-	i.Tag = Tag(b[0])
-	i.Length = b[1]
-	fmt.Printf("IE:tag %x [%x] len:%d\n", i.Tag, b[0], b[1])
-	if l < 2+int(i.Length) {
-		return io.ErrUnexpectedEOF
-	}
-	i.Value = b[2 : 2+int(i.Length)]
+	i.Tag = tag
+	i.Length = uint8(len(v))
+	i.Value = v
 	return nil
 }
 
@@ -199,8 +208,8 @@ func ParseAsBER(b []byte) ([]*IE, error) {
 			continue
 		}
 
-		if i.IE[0].MarshalLen() < i.MarshalLen()-2 {
-			l := 2
+		if i.IE[0].MarshalLen() < len(i.Value) {
+			l := i.MarshalLen() - len(i.Value)
 			for _, ie := range i.IE {
 				l += ie.MarshalLen()
 			}
@@ -229,17 +238,12 @@ func (i *IE) ParseRecursive(b []byte) error {
 	}
 
 	i.Tag = Tag(b[0])
-	u, _ := readLength(b)
-	i.Length = u
-	var offset int = 2
-	if i.Length > 127 {
-		offset = 3
-	}
-
-	if int(i.Length)+offset > len(b) {
+	_, v, _, err := readTLV(b)
+	if err != nil {
 		return nil
 	}
-	i.Value = b[offset : offset+int(i.Length)]
+	i.Length = uint8(len(v))
+	i.Value = v
 
 	if i.Tag.Form() == 1 {
 		x, err := ParseAsBER(i.Value)
@@ -253,11 +257,12 @@ func (i *IE) ParseRecursive(b []byte) error {
 }
 
 // MarshalLen returns the serial length of IE.
+//
+// The length is computed from the Value as AppendBinary does, not taken from
+// the Length field.
 func (i *IE) MarshalLen() int {
-	if i.Length > 127 {
-		return 3 + len(i.Value)
-	}
-	return 2 + len(i.Value)
+	l := len(i.Value)
+	return 1 + lengthLen(l) + l
 }
 
 // SetLength sets the length in Length field.
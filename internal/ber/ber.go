@@ -0,0 +1,200 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package ber provides the minimal BER (Basic Encoding Rules) helpers shared by
+// the codecs in this module.
+//
+// Only the definite form of length and single-octet tags are supported, which
+// is sufficient for the TCAP messages themselves.
+package ber
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLength indicates that the length of an element is in the indefinite form,
+// or too long to be handled.
+var ErrInvalidLength = errors.New("tcap: invalid length")
+
+// LengthLen returns the number of octets needed to encode l as a definite length.
+func LengthLen(l int) int {
+	if l <= 127 {
+		return 1
+	}
+	n := 1
+	for ; l > 0; l >>= 8 {
+		n++
+	}
+	return n
+}
+
+// AppendLength appends l to b as a definite length, using the short form whenever possible.
+func AppendLength(b []byte, l int) []byte {
+	if l <= 127 {
+		return append(b, byte(l))
+	}
+	n := LengthLen(l) - 1
+	b = append(b, byte(128|n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(l>>(8*uint(i))))
+	}
+	return b
+}
+
+// AppendTLV appends an element with the given tag and contents to b.
+func AppendTLV(b []byte, tag uint8, value []byte) []byte {
+	b = append(b, tag)
+	b = AppendLength(b, len(value))
+	return append(b, value...)
+}
+
+// BeginTLV appends the tag and a single placeholder length octet to b and returns
+// the offset where the contents start. The contents are then appended to b as usual,
+// and the length is fixed by EndTLV.
+func BeginTLV(b []byte, tag uint8) ([]byte, int) {
+	b = append(b, tag, 0)
+	return b, len(b)
+}
+
+// EndTLV sets the length of the element opened by BeginTLV at start. If the contents
+// do not fit in the short form, they are moved forward in place to make room for the
+// long form length octets.
+func EndTLV(b []byte, start int) []byte {
+	l := len(b) - start
+	if l <= 127 {
+		b[start-1] = byte(l)
+		return b
+	}
+
+	n := LengthLen(l) - 1
+	for i := 0; i < n; i++ {
+		b = append(b, 0)
+	}
+	copy(b[start+n:], b[start:start+l])
+	b[start-1] = byte(128 | n)
+	for i := 0; i < n; i++ {
+		b[start+i] = byte(l >> (8 * uint(n-1-i)))
+	}
+	return b
+}
+
+// ReadTLV reads a single element at the beginning of b without copying it, and returns
+// the tag, the contents and the number of octets consumed.
+func ReadTLV(b []byte) (uint8, []byte, int, error) {
+	if len(b) < 2 {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+
+	l, n := int(b[1]), 2
+	if l&0x80 != 0 {
+		count := l & 0x7f
+		if count == 0 || count > 4 {
+			return 0, nil, 0, ErrInvalidLength
+		}
+		if len(b) < 2+count {
+			return 0, nil, 0, io.ErrUnexpectedEOF
+		}
+		l = 0
+		for _, x := range b[2 : 2+count] {
+			l = l<<8 | int(x)
+		}
+		n += count
+	}
+
+	if l < 0 || len(b) < n+l {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+	return b[0], b[n : n+l], n + l, nil
+}
+
+// EncodeInteger returns the contents octets of an INTEGER in the minimum number of octets.
+func EncodeInteger(n int) []byte {
+	b := []byte{byte(n)}
+	for (n >= 0x80 || n < -0x80) && len(b) < 8 {
+		n >>= 8
+		b = append([]byte{byte(n)}, b...)
+	}
+	return b
+}
+
+// DecodeInteger decodes the contents octets of an INTEGER in two's complement.
+func DecodeInteger(b []byte) int {
+	if len(b) == 0 {
+		return 0
+	}
+	n := int(int8(b[0]))
+	for _, x := range b[1:] {
+		n = n<<8 | int(x)
+	}
+	return n
+}
+
+// ErrInvalidOID indicates that an OBJECT IDENTIFIER cannot be encoded or decoded.
+var ErrInvalidOID = errors.New("tcap: invalid object identifier")
+
+// FormatOID returns the contents octets of an OBJECT IDENTIFIER in dotted notation,
+// e.g. "0.0.17.773.1.1.1".
+func FormatOID(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", ErrInvalidOID
+	}
+
+	var arcs []string
+	n := 0
+	for i, x := range b {
+		n = n<<7 | int(x&0x7f)
+		if x&0x80 != 0 {
+			if i == len(b)-1 || n > 1<<24 {
+				return "", ErrInvalidOID
+			}
+			continue
+		}
+
+		if arcs == nil {
+			first := n / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, strconv.Itoa(first), strconv.Itoa(n-first*40))
+		} else {
+			arcs = append(arcs, strconv.Itoa(n))
+		}
+		n = 0
+	}
+	return strings.Join(arcs, "."), nil
+}
+
+// EncodeOID returns the contents octets of an OBJECT IDENTIFIER given in dotted notation.
+func EncodeOID(s string) ([]byte, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, ErrInvalidOID
+	}
+
+	arcs := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidOID
+		}
+		arcs[i] = n
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] >= 40) {
+		return nil, ErrInvalidOID
+	}
+
+	var b []byte
+	for _, n := range append([]int{arcs[0]*40 + arcs[1]}, arcs[2:]...) {
+		var sub []byte
+		for sub = []byte{byte(n & 0x7f)}; n > 0x7f; {
+			n >>= 7
+			sub = append([]byte{byte(n&0x7f) | 0x80}, sub...)
+		}
+		b = append(b, sub...)
+	}
+	return b, nil
+}
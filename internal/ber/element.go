@@ -0,0 +1,116 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ber
+
+import "io"
+
+// Class definitions of the tags.
+const (
+	ClassUniversal = iota
+	ClassApplication
+	ClassContextSpecific
+	ClassPrivate
+)
+
+// Element is an element with the tag of any number, used in the parameters of
+// the operations, of which the tags are not limited to a single octet.
+type Element struct {
+	Class       int
+	Constructed bool
+	Tag         int
+	// Value is the contents, and Raw is the whole element including the tag
+	// and the length, both of which refer to the given byte sequence.
+	Value []byte
+	Raw   []byte
+}
+
+// Is reports whether e has the given class and tag number.
+func (e *Element) Is(class, tag int) bool {
+	return e.Class == class && e.Tag == tag
+}
+
+// Identifier returns the identifier octets of e, which is the tag without the
+// length.
+func (e *Element) Identifier() []byte {
+	b := AppendElement(nil, e.Class, e.Constructed, e.Tag, nil)
+	return b[:len(b)-1]
+}
+
+// ReadElement reads a single element at the beginning of b without copying it,
+// and returns the number of octets consumed.
+func ReadElement(b []byte) (*Element, int, error) {
+	if len(b) < 2 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	e := &Element{
+		Class:       int(b[0] >> 6),
+		Constructed: b[0]&0x20 != 0,
+		Tag:         int(b[0] & 0x1f),
+	}
+	n := 1
+	if e.Tag == 0x1f {
+		e.Tag = 0
+		for {
+			if n >= len(b) {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			if n > 4 {
+				return nil, 0, ErrInvalidLength
+			}
+			x := b[n]
+			n++
+			e.Tag = e.Tag<<7 | int(x&0x7f)
+			if x&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	// the tag is replaced to reuse ReadTLV for the length.
+	_, v, m, err := ReadTLV(b[n-1:])
+	if err != nil {
+		return nil, 0, err
+	}
+	n += m - 1
+	e.Value, e.Raw = v, b[:n]
+	return e, n, nil
+}
+
+// ReadElements reads all the elements in b, typically the contents of a SEQUENCE.
+func ReadElements(b []byte) ([]*Element, error) {
+	var es []*Element
+	for len(b) != 0 {
+		e, n, err := ReadElement(b)
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+		b = b[n:]
+	}
+	return es, nil
+}
+
+// AppendElement appends an element with the given class, form, tag number and
+// contents to b.
+func AppendElement(b []byte, class int, constructed bool, tag int, value []byte) []byte {
+	t := byte(class << 6)
+	if constructed {
+		t |= 0x20
+	}
+	if tag < 0x1f {
+		b = append(b, t|byte(tag))
+	} else {
+		b = append(b, t|0x1f)
+		var sub []byte
+		for sub = []byte{byte(tag & 0x7f)}; tag > 0x7f; {
+			tag >>= 7
+			sub = append([]byte{byte(tag&0x7f) | 0x80}, sub...)
+		}
+		b = append(b, sub...)
+	}
+	b = AppendLength(b, len(value))
+	return append(b, value...)
+}
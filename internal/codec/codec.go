@@ -0,0 +1,212 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package codec provides the encoder and the decoder of the Parameters shared
+// by the gsmmap and cap packages, of which the tags can be of any number.
+package codec
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+// Tag classes and the universal tag numbers used in the Parameters.
+const (
+	Universal       = ber.ClassUniversal
+	ContextSpecific = ber.ClassContextSpecific
+
+	TagSequence = 16
+)
+
+// Encoder appends the elements in order, keeping the first error.
+type Encoder struct {
+	b   []byte
+	err error
+}
+
+// Octets appends v if not nil.
+func (e *Encoder) Octets(class, tag int, v []byte) {
+	if v == nil {
+		return
+	}
+	e.b = ber.AppendElement(e.b, class, false, tag, v)
+}
+
+// Octet appends v in a single octet.
+func (e *Encoder) Octet(class, tag int, v uint8) {
+	e.b = ber.AppendElement(e.b, class, false, tag, []byte{v})
+}
+
+// Integer appends n as an INTEGER.
+func (e *Encoder) Integer(class, tag, n int) {
+	e.b = ber.AppendElement(e.b, class, false, tag, ber.EncodeInteger(n))
+}
+
+// Boolean appends v as a BOOLEAN.
+func (e *Encoder) Boolean(class, tag int, v bool) {
+	var x uint8
+	if v {
+		x = 0xff
+	}
+	e.Octet(class, tag, x)
+}
+
+// Null appends NULL if v is true.
+func (e *Encoder) Null(class, tag int, v bool) {
+	if v {
+		e.b = ber.AppendElement(e.b, class, false, tag, nil)
+	}
+}
+
+// TBCD appends s in TBCD-STRING if not empty.
+func (e *Encoder) TBCD(class, tag int, s string) {
+	if s == "" || e.err != nil {
+		return
+	}
+	v, err := param.EncodeTBCD(s)
+	if err != nil {
+		e.err = err
+		return
+	}
+	e.Octets(class, tag, v)
+}
+
+// Contents is a value in param package, encoded in the contents octets of
+// which the tag is given by where it is used.
+type Contents interface {
+	Encode() ([]byte, error)
+}
+
+// Value appends v, which must not be nil.
+func (e *Encoder) Value(class, tag int, v Contents) {
+	if e.err != nil {
+		return
+	}
+	b, err := v.Encode()
+	if err != nil {
+		e.err = err
+		return
+	}
+	e.Octets(class, tag, b)
+}
+
+// Constructed appends the constructed element with the contents appended by f.
+func (e *Encoder) Constructed(class, tag int, f func(*Encoder)) {
+	if e.err != nil {
+		return
+	}
+	inner := &Encoder{}
+	f(inner)
+	if inner.err != nil {
+		e.err = inner.err
+		return
+	}
+	e.b = ber.AppendElement(e.b, class, true, tag, inner.b)
+}
+
+// RawConstructed appends the constructed element with the contents already
+// encoded.
+func (e *Encoder) RawConstructed(class, tag int, v []byte) {
+	e.b = ber.AppendElement(e.b, class, true, tag, v)
+}
+
+// SetError keeps err as the error if there is none, e.g. with an invalid value.
+func (e *Encoder) SetError(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// Raw appends the elements already encoded.
+func (e *Encoder) Raw(b []byte) {
+	e.b = append(e.b, b...)
+}
+
+// Bytes returns the elements appended, or the first error.
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.b, nil
+}
+
+// Sequence returns the SEQUENCE with the contents appended by f.
+func Sequence(f func(*Encoder)) ([]byte, error) {
+	return Tagged(Universal, TagSequence, f)
+}
+
+// Tagged returns the constructed element with the contents appended by f.
+func Tagged(class, tag int, f func(*Encoder)) ([]byte, error) {
+	e := &Encoder{}
+	e.Constructed(class, tag, f)
+	return e.Bytes()
+}
+
+// Decoder reads the elements, reporting the invalid ones with the error of
+// the package that uses it.
+type Decoder struct {
+	// Invalid returns the error with the reason, e.g. InvalidParameterError.
+	Invalid func(reason string) error
+}
+
+// ReadElement reads b as a single element.
+func (d *Decoder) ReadElement(b []byte) (*ber.Element, error) {
+	e, n, err := ber.ReadElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(b) {
+		return nil, d.Invalid(fmt.Sprintf("%d trailing byte(s)", len(b)-n))
+	}
+	return e, nil
+}
+
+// ReadSequence reads b as a SEQUENCE and returns the elements in it.
+func (d *Decoder) ReadSequence(b []byte) ([]*ber.Element, error) {
+	return d.ReadTagged(b, Universal, TagSequence)
+}
+
+// ReadTagged reads b as the constructed element with the given tag and
+// returns the elements in it.
+func (d *Decoder) ReadTagged(b []byte, class, tag int) ([]*ber.Element, error) {
+	e, err := d.ReadElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Is(class, tag) || !e.Constructed {
+		return nil, d.Unexpected(e)
+	}
+	return ber.ReadElements(e.Value)
+}
+
+// Unexpected returns the error with the element not expected.
+func (d *Decoder) Unexpected(e *ber.Element) error {
+	return d.Invalid(fmt.Sprintf("unexpected element: %x", e.Raw))
+}
+
+// Missing returns the error with the name of the mandatory element missing.
+func (d *Decoder) Missing(name string) error {
+	return d.Invalid("missing " + name)
+}
+
+// Octet decodes e as a single octet.
+func (d *Decoder) Octet(e *ber.Element) (uint8, error) {
+	if len(e.Value) != 1 {
+		return 0, d.Unexpected(e)
+	}
+	return e.Value[0], nil
+}
+
+// Boolean decodes e as a BOOLEAN.
+func Boolean(e *ber.Element) bool {
+	return len(e.Value) != 0 && e.Value[0] != 0
+}
+
+// Clone returns a copy of b, so that the decoded values do not refer to the
+// given byte sequence.
+func Clone(b []byte) []byte {
+	return append([]byte{}, b...)
+}
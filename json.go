@@ -0,0 +1,804 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// The semantic representations of TCAP and its portions in JSON.
+//
+// The enumerated values are given as the names if known, or as the numbers
+// otherwise, and both of them are accepted in decoding. The values that are
+// not interpreted by this package, such as Parameter, are given as IEs with
+// the tag and the contents in hex. The ies of them are the parsed IE tree for
+// information, and ignored in decoding.
+//
+// The unknown elements have the name of the field they followed when parsed in
+// after, or "begin" for the ones at the beginning, and are put there again when
+// decoded. The ones without after are put at the end.
+
+type tcapJSON struct {
+	Transaction *transactionJSON `json:"transaction,omitempty"`
+	Dialogue    *dialogueJSON    `json:"dialogue,omitempty"`
+	Components  *Components      `json:"components,omitempty"`
+}
+
+type transactionJSON struct {
+	Type        interface{} `json:"type"`
+	OTID        string      `json:"otid,omitempty"`
+	DTID        string      `json:"dtid,omitempty"`
+	PAbortCause interface{} `json:"pAbortCause,omitempty"`
+	Unknown     []*ieJSON   `json:"unknown,omitempty"`
+	Payload     string      `json:"payload,omitempty"`
+}
+
+type dialogueJSON struct {
+	OID     string       `json:"oid,omitempty"`
+	OIDName string       `json:"oidName,omitempty"`
+	PDU     *DialoguePDU `json:"pdu,omitempty"`
+	Unknown []*ieJSON    `json:"unknown,omitempty"`
+	Payload string       `json:"payload,omitempty"`
+}
+
+type dialoguePDUJSON struct {
+	Type                   interface{}     `json:"type"`
+	ProtocolVersion        string          `json:"protocolVersion,omitempty"`
+	ApplicationContextName *acnJSON        `json:"applicationContextName,omitempty"`
+	Result                 interface{}     `json:"result,omitempty"`
+	ResultSourceDiagnostic *diagnosticJSON `json:"resultSourceDiagnostic,omitempty"`
+	AbortSource            interface{}     `json:"abortSource,omitempty"`
+	UserInformation        *ieJSON         `json:"userInformation,omitempty"`
+	Unknown                []*ieJSON       `json:"unknown,omitempty"`
+}
+
+type acnJSON struct {
+	OID     string `json:"oid"`
+	Name    string `json:"name,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+type diagnosticJSON struct {
+	Source interface{} `json:"source"`
+	Reason interface{} `json:"reason"`
+}
+
+type componentJSON struct {
+	Type      interface{}  `json:"type"`
+	InvokeID  *int         `json:"invokeID,omitempty"`
+	LinkedID  *int         `json:"linkedID,omitempty"`
+	OpCode    interface{}  `json:"opCode,omitempty"`
+	ErrorCode interface{}  `json:"errorCode,omitempty"`
+	Problem   *problemJSON `json:"problem,omitempty"`
+	Parameter *ieJSON      `json:"parameter,omitempty"`
+	Unknown   []*ieJSON    `json:"unknown,omitempty"`
+}
+
+type problemJSON struct {
+	Type interface{} `json:"type"`
+	Code interface{} `json:"code"`
+}
+
+type ieJSON struct {
+	Tag   string    `json:"tag"`
+	Value string    `json:"value"`
+	IEs   []*ieJSON `json:"ies,omitempty"`
+	After string    `json:"after,omitempty"`
+}
+
+// MarshalJSON returns the semantic representation of TCAP in JSON.
+//
+// It can be decoded with UnmarshalJSON into a TCAP that is encoded into the
+// same byte sequence as the original one.
+func (t *TCAP) MarshalJSON() ([]byte, error) {
+	j, err := t.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+func (t *TCAP) toJSON() (*tcapJSON, error) {
+	j := &tcapJSON{Components: t.Components}
+	if tx := t.Transaction; tx != nil {
+		j.Transaction = tx.toJSON(t.Dialogue == nil && t.Components == nil)
+	}
+	if d := t.Dialogue; d != nil {
+		var err error
+		if j.Dialogue, err = d.toJSON(t.Components == nil); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (t *TCAP) UnmarshalJSON(b []byte) error {
+	j := &tcapJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	return t.fromJSON(j)
+}
+
+func (t *TCAP) fromJSON(j *tcapJSON) error {
+	*t = TCAP{Components: j.Components}
+	if j.Transaction != nil {
+		t.Transaction = &Transaction{}
+		if err := t.Transaction.fromJSON(j.Transaction); err != nil {
+			return err
+		}
+	}
+	if j.Dialogue != nil {
+		t.Dialogue = &Dialogue{}
+		if err := t.Dialogue.fromJSON(j.Dialogue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalYAML returns the semantic representation of TCAP as a value that YAML
+// encoders accept, which has the same structure as the one in JSON.
+//
+// It satisfies the Marshaler interface of gopkg.in/yaml.v2 and v3 without
+// depending on them.
+func (t *TCAP) MarshalYAML() (interface{}, error) {
+	b, err := t.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// UnmarshalYAML sets the values retrieved from the semantic representation in YAML.
+//
+// It satisfies the obsolete Unmarshaler interface of gopkg.in/yaml.v2, which is
+// also supported by v3.
+func (t *TCAP) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(jsonCompatible(v))
+	if err != nil {
+		return err
+	}
+	return t.UnmarshalJSON(b)
+}
+
+// jsonCompatible converts the maps with non-string keys that YAML decoders may
+// return into the ones that can be encoded in JSON.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(x)
+		}
+		return m
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = jsonCompatible(x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = jsonCompatible(x)
+		}
+	}
+	return v
+}
+
+// MarshalJSON returns the semantic representation of Transaction in JSON.
+func (t *Transaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.toJSON(true))
+}
+
+func (t *Transaction) toJSON(withPayload bool) *transactionJSON {
+	j := &transactionJSON{Type: enumValue(t.Type.Code(), messageTypeNames)}
+
+	// The Transaction IDs are given as the octets as they are, which can be
+	// 1 to 4 octets long.
+	switch t.Type.Code() {
+	case Begin, Continue:
+		if id := t.OrigTransactionID; id != nil {
+			j.OTID = hex.EncodeToString(id.Value)
+		}
+	}
+	switch t.Type.Code() {
+	case End, Continue, Abort:
+		if id := t.DestTransactionID; id != nil {
+			j.DTID = hex.EncodeToString(id.Value)
+		}
+	}
+	if c := t.PAbortCause; c != nil && t.Type.Code() == Abort && len(c.Value) != 0 {
+		j.PAbortCause = enumValue(int(c.Value[0]), pAbortCauseNames)
+	}
+	j.Unknown = unknownJSON(t.Unknown, t.unknownAt, t.jsonFields())
+	if withPayload {
+		j.Payload = hex.EncodeToString(t.Payload)
+	}
+	return j
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (t *Transaction) UnmarshalJSON(b []byte) error {
+	j := &transactionJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	return t.fromJSON(j)
+}
+
+func (t *Transaction) fromJSON(j *transactionJSON) error {
+	mtype, err := enumCode("type", j.Type, messageTypeNames)
+	if err != nil {
+		return err
+	}
+	*t = Transaction{Type: NewApplicationWideConstructorTag(mtype)}
+
+	if j.OTID != "" {
+		if t.OrigTransactionID, err = hexIE("otid", NewApplicationWidePrimitiveTag(8), j.OTID); err != nil {
+			return err
+		}
+	}
+	if j.DTID != "" {
+		if t.DestTransactionID, err = hexIE("dtid", NewApplicationWidePrimitiveTag(9), j.DTID); err != nil {
+			return err
+		}
+	}
+	if j.PAbortCause != nil {
+		cause, err := enumCode("pAbortCause", j.PAbortCause, pAbortCauseNames)
+		if err != nil {
+			return err
+		}
+		t.PAbortCause = NewIE(NewApplicationWidePrimitiveTag(10), []byte{uint8(cause)})
+	}
+	if t.Unknown, t.unknownAt, err = unknownIEs(j.Unknown, t.jsonFields()); err != nil {
+		return err
+	}
+	if t.Payload, err = decodeHex("payload", j.Payload); err != nil {
+		return err
+	}
+	t.SetLength()
+	return nil
+}
+
+// jsonFields returns the fields that the unknown elements can follow, by the
+// names in JSON.
+func (t *Transaction) jsonFields() map[string]*IE {
+	return map[string]*IE{
+		"otid":        t.OrigTransactionID,
+		"dtid":        t.DestTransactionID,
+		"pAbortCause": t.PAbortCause,
+		"dialogue":    afterDialogue,
+		"components":  afterComponents,
+	}
+}
+
+// MarshalJSON returns the semantic representation of Dialogue in JSON.
+func (d *Dialogue) MarshalJSON() ([]byte, error) {
+	j, err := d.toJSON(true)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+func (d *Dialogue) toJSON(withPayload bool) (*dialogueJSON, error) {
+	j := &dialogueJSON{PDU: d.DialoguePDU}
+	if oid := d.ObjectIdentifier; oid != nil {
+		var err error
+		if j.OID, err = ber.FormatOID(oid.Value); err != nil {
+			return nil, err
+		}
+		j.OIDName = dialogueOIDNames[j.OID]
+	}
+	j.Unknown = unknownJSON(d.Unknown, nil, nil)
+	if withPayload {
+		j.Payload = hex.EncodeToString(d.Payload)
+	}
+	return j, nil
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (d *Dialogue) UnmarshalJSON(b []byte) error {
+	j := &dialogueJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+	return d.fromJSON(j)
+}
+
+func (d *Dialogue) fromJSON(j *dialogueJSON) error {
+	*d = Dialogue{
+		Tag:            NewApplicationWideConstructorTag(11),
+		ExternalTag:    NewUniversalConstructorTag(8),
+		SingleAsn1Type: &IE{Tag: NewContextSpecificConstructorTag(0)},
+		DialoguePDU:    j.PDU,
+	}
+
+	if j.OID != "" {
+		oid, err := ber.EncodeOID(j.OID)
+		if err != nil {
+			return &InvalidNameError{Field: "oid", Name: j.OID}
+		}
+		d.ObjectIdentifier = NewIE(NewUniversalPrimitiveTag(6), oid)
+	}
+
+	var err error
+	if d.Unknown, _, err = unknownIEs(j.Unknown, nil); err != nil {
+		return err
+	}
+	if d.Payload, err = decodeHex("payload", j.Payload); err != nil {
+		return err
+	}
+	if d.DialoguePDU != nil {
+		d.SingleAsn1Type.Length = uint8(d.DialoguePDU.MarshalLen())
+	}
+	d.SetLength()
+	return nil
+}
+
+// MarshalJSON returns the semantic representation of DialoguePDU in JSON.
+func (d *DialoguePDU) MarshalJSON() ([]byte, error) {
+	j := &dialoguePDUJSON{
+		Type: enumValue(d.Type.Code(), dialoguePDUTypeNames),
+	}
+
+	switch d.Type.Code() {
+	case AARQ, AARE:
+		if pv := d.ProtocolVersion; pv != nil {
+			j.ProtocolVersion = hex.EncodeToString(pv.Value)
+		}
+		if d.ApplicationContextName != nil {
+			oid, name, ver, err := d.applicationContext()
+			if err != nil {
+				return nil, err
+			}
+			j.ApplicationContextName = &acnJSON{OID: oid, Name: name, Version: ver}
+		}
+	}
+
+	if d.Type.Code() == AARE {
+		if d.Result != nil {
+			res, err := d.resultCode()
+			if err != nil {
+				return nil, err
+			}
+			j.Result = enumValue(res, resultNames)
+		}
+		if d.ResultSourceDiagnostic != nil {
+			src, reason, err := d.diagnostic()
+			if err != nil {
+				return nil, err
+			}
+			j.ResultSourceDiagnostic = &diagnosticJSON{
+				Source: enumValue(src, diagnosticSourceNames),
+				Reason: enumValue(reason, diagnosticReasonNames[src]),
+			}
+		}
+	}
+
+	if d.Type.Code() == ABRT {
+		if src := d.AbortSource; src != nil {
+			j.AbortSource = enumValue(ber.DecodeInteger(src.Value), abortSourceNames)
+		}
+	}
+
+	if ui := d.UserInformation; ui != nil {
+		j.UserInformation = newIEJSON(ui.Tag, ui.Value)
+	}
+	j.Unknown = unknownJSON(d.Unknown, d.unknownAt, d.jsonFields())
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (d *DialoguePDU) UnmarshalJSON(b []byte) error {
+	j := &dialoguePDUJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	dtype, err := enumCode("type", j.Type, dialoguePDUTypeNames)
+	if err != nil {
+		return err
+	}
+	*d = DialoguePDU{Type: NewApplicationWideConstructorTag(dtype)}
+
+	if j.ProtocolVersion != "" {
+		if d.ProtocolVersion, err = hexIE("protocolVersion", NewContextSpecificPrimitiveTag(0), j.ProtocolVersion); err != nil {
+			return err
+		}
+	}
+	if acn := j.ApplicationContextName; acn != nil {
+		oid, err := ber.EncodeOID(acn.OID)
+		if err != nil {
+			return &InvalidNameError{Field: "applicationContextName", Name: acn.OID}
+		}
+		d.ApplicationContextName = NewIE(
+			NewContextSpecificConstructorTag(1),
+			ber.AppendTLV(nil, uint8(NewUniversalPrimitiveTag(6)), oid),
+		)
+	}
+	if j.Result != nil {
+		res, err := enumCode("result", j.Result, resultNames)
+		if err != nil {
+			return err
+		}
+		d.Result = NewIE(
+			NewContextSpecificConstructorTag(2),
+			ber.AppendTLV(nil, uint8(NewUniversalPrimitiveTag(2)), ber.EncodeInteger(res)),
+		)
+	}
+	if diag := j.ResultSourceDiagnostic; diag != nil {
+		src, err := enumCode("resultSourceDiagnostic source", diag.Source, diagnosticSourceNames)
+		if err != nil {
+			return err
+		}
+		reason, err := enumCode("resultSourceDiagnostic reason", diag.Reason, diagnosticReasonNames[src])
+		if err != nil {
+			return err
+		}
+		d.ResultSourceDiagnostic = NewIE(
+			NewContextSpecificConstructorTag(3),
+			ber.AppendTLV(nil, uint8(NewContextSpecificConstructorTag(src)),
+				ber.AppendTLV(nil, uint8(NewUniversalPrimitiveTag(2)), ber.EncodeInteger(reason)),
+			),
+		)
+	}
+	if j.AbortSource != nil {
+		src, err := enumCode("abortSource", j.AbortSource, abortSourceNames)
+		if err != nil {
+			return err
+		}
+		d.AbortSource = NewIE(NewContextSpecificPrimitiveTag(0), ber.EncodeInteger(src))
+	}
+	if ui := j.UserInformation; ui != nil {
+		if d.UserInformation, err = ui.ie("userInformation"); err != nil {
+			return err
+		}
+	}
+	if d.Unknown, d.unknownAt, err = unknownIEs(j.Unknown, d.jsonFields()); err != nil {
+		return err
+	}
+
+	d.SetLength()
+	return nil
+}
+
+// jsonFields returns the fields that the unknown elements can follow, by the
+// names in JSON.
+func (d *DialoguePDU) jsonFields() map[string]*IE {
+	return map[string]*IE{
+		"protocolVersion":        d.ProtocolVersion,
+		"applicationContextName": d.ApplicationContextName,
+		"result":                 d.Result,
+		"resultSourceDiagnostic": d.ResultSourceDiagnostic,
+		"abortSource":            d.AbortSource,
+		"userInformation":        d.UserInformation,
+	}
+}
+
+// MarshalJSON returns the semantic representation of Components in JSON,
+// which is the list of Component.
+func (c *Components) MarshalJSON() ([]byte, error) {
+	if c.Component == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c.Component)
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (c *Components) UnmarshalJSON(b []byte) error {
+	*c = Components{Tag: NewApplicationWideConstructorTag(12)}
+	if err := json.Unmarshal(b, &c.Component); err != nil {
+		return err
+	}
+	c.SetLength()
+	return nil
+}
+
+// MarshalJSON returns the semantic representation of Component in JSON.
+func (c *Component) MarshalJSON() ([]byte, error) {
+	ctype := c.Type.Code()
+	j := &componentJSON{
+		Type: enumValue(ctype, componentTypeNames),
+	}
+	if id := c.InvokeID; id != nil && id.Tag == NewUniversalPrimitiveTag(2) {
+		n := ber.DecodeInteger(id.Value)
+		j.InvokeID = &n
+	}
+
+	var err error
+	switch ctype {
+	case Invoke:
+		if id := c.LinkedID; id != nil {
+			n := ber.DecodeInteger(id.Value)
+			j.LinkedID = &n
+		}
+		if j.OpCode, err = codeValue(c.OperationCode); err != nil {
+			return nil, err
+		}
+		j.Parameter = parameterJSON(c.Parameter)
+	case ReturnResultLast, ReturnResultNotLast:
+		if c.ResultRetres != nil {
+			if j.OpCode, err = codeValue(c.OperationCode); err != nil {
+				return nil, err
+			}
+			j.Parameter = parameterJSON(c.Parameter)
+		}
+	case ReturnError:
+		if j.ErrorCode, err = codeValue(c.ErrorCode); err != nil {
+			return nil, err
+		}
+		j.Parameter = parameterJSON(c.Parameter)
+	case Reject:
+		if p := c.ProblemCode; p != nil {
+			ptype := p.Tag.Code()
+			j.Problem = &problemJSON{
+				Type: enumValue(ptype, problemTypeNames),
+				Code: enumValue(ber.DecodeInteger(p.Value), problemCodeNames[ptype]),
+			}
+		}
+	}
+	j.Unknown = unknownJSON(c.Unknown, c.unknownAt, c.jsonFields())
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON sets the values retrieved from the semantic representation in JSON.
+func (c *Component) UnmarshalJSON(b []byte) error {
+	j := &componentJSON{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	ctype, err := enumCode("type", j.Type, componentTypeNames)
+	if err != nil {
+		return err
+	}
+	*c = Component{Type: NewContextSpecificConstructorTag(ctype)}
+
+	switch {
+	case j.InvokeID != nil:
+		c.InvokeID = NewIE(NewUniversalPrimitiveTag(2), ber.EncodeInteger(*j.InvokeID))
+	case ctype == Reject:
+		c.InvokeID = NewIE(NewUniversalPrimitiveTag(5), []byte{})
+	}
+	if j.LinkedID != nil {
+		c.LinkedID = NewIE(NewContextSpecificPrimitiveTag(0), ber.EncodeInteger(*j.LinkedID))
+	}
+	if j.OpCode != nil {
+		if c.OperationCode, err = codeIE("opCode", j.OpCode); err != nil {
+			return err
+		}
+		if ctype == ReturnResultLast || ctype == ReturnResultNotLast {
+			c.ResultRetres = &IE{Tag: NewUniversalConstructorTag(0x10)}
+		}
+	}
+	if j.ErrorCode != nil {
+		if c.ErrorCode, err = codeIE("errorCode", j.ErrorCode); err != nil {
+			return err
+		}
+	}
+	if p := j.Problem; p != nil {
+		ptype, err := enumCode("problem type", p.Type, problemTypeNames)
+		if err != nil {
+			return err
+		}
+		code, err := enumCode("problem code", p.Code, problemCodeNames[ptype])
+		if err != nil {
+			return err
+		}
+		c.ProblemCode = NewIE(NewContextSpecificPrimitiveTag(ptype), ber.EncodeInteger(code))
+	}
+	if p := j.Parameter; p != nil {
+		if c.Parameter, err = p.ie("parameter"); err != nil {
+			return err
+		}
+	}
+	if c.Unknown, c.unknownAt, err = unknownIEs(j.Unknown, c.jsonFields()); err != nil {
+		return err
+	}
+
+	c.SetLength()
+	return nil
+}
+
+// jsonFields returns the fields that the unknown elements can follow, by the
+// names in JSON. The result is the sequence that OpCode and Parameter are in.
+func (c *Component) jsonFields() map[string]*IE {
+	return map[string]*IE{
+		"invokeID":  c.InvokeID,
+		"linkedID":  c.LinkedID,
+		"result":    c.ResultRetres,
+		"opCode":    c.OperationCode,
+		"errorCode": c.ErrorCode,
+		"problem":   c.ProblemCode,
+		"parameter": c.Parameter,
+	}
+}
+
+// unknownJSON returns the unknown elements in JSON, with the names in fields
+// of the ones they followed.
+func unknownJSON(unknown []*IE, at unknownAt, fields map[string]*IE) []*ieJSON {
+	var j []*ieJSON
+	for _, ie := range unknown {
+		u := newIEJSON(ie.Tag, ie.Value)
+		if prev, ok := at[ie]; ok {
+			u.After = fieldName(prev, fields)
+		}
+		j = append(j, u)
+	}
+	return j
+}
+
+// fieldName returns the name of field in fields, or "begin" if field is nil.
+func fieldName(field *IE, fields map[string]*IE) string {
+	if field == nil {
+		return "begin"
+	}
+	for name, f := range fields {
+		if f == field {
+			return name
+		}
+	}
+	return ""
+}
+
+// unknownIEs returns the unknown elements decoded from JSON, and the positions
+// of them as the fields named in after.
+func unknownIEs(j []*ieJSON, fields map[string]*IE) ([]*IE, unknownAt, error) {
+	var (
+		unknown []*IE
+		at      unknownAt
+	)
+	for _, u := range j {
+		ie, err := u.ie("unknown")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var prev *IE
+		switch u.After {
+		case "":
+			unknown = append(unknown, ie)
+			continue
+		case "begin":
+		default:
+			field, ok := fields[u.After]
+			if !ok {
+				return nil, nil, &InvalidNameError{Field: "unknown after", Name: u.After}
+			}
+			if field == nil {
+				// The field is not in the message, e.g. removed by hand.
+				unknown = append(unknown, ie)
+				continue
+			}
+			prev = field
+		}
+		unknown = at.add(unknown, ie, prev)
+	}
+	return unknown, at, nil
+}
+
+// enumValue returns the name of code if known, or code itself.
+func enumValue(code int, names map[int]string) interface{} {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// enumCode returns the code of v given as either the name or the number.
+func enumCode(field string, v interface{}, names map[int]string) (int, error) {
+	switch v := v.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		for code, name := range names {
+			if name == v {
+				return code, nil
+			}
+		}
+	}
+	return 0, &InvalidNameError{Field: field, Name: fmt.Sprint(v)}
+}
+
+// codeValue returns the Operation Code or Error Code, which is the number if
+// local or the OID in dotted notation if global.
+func codeValue(code *IE) (interface{}, error) {
+	if code == nil {
+		return nil, nil
+	}
+	if code.Tag == NewUniversalPrimitiveTag(6) {
+		return ber.FormatOID(code.Value)
+	}
+	return ber.DecodeInteger(code.Value), nil
+}
+
+// codeIE returns the Operation Code or Error Code from the value given by codeValue.
+func codeIE(field string, v interface{}) (*IE, error) {
+	switch v := v.(type) {
+	case float64:
+		return NewIE(NewUniversalPrimitiveTag(2), ber.EncodeInteger(int(v))), nil
+	case string:
+		oid, err := ber.EncodeOID(v)
+		if err != nil {
+			break
+		}
+		return NewIE(NewUniversalPrimitiveTag(6), oid), nil
+	}
+	return nil, &InvalidNameError{Field: field, Name: fmt.Sprint(v)}
+}
+
+func parameterJSON(param *IE) *ieJSON {
+	if param == nil {
+		return nil
+	}
+	return newIEJSON(param.Tag, param.Value)
+}
+
+// newIEJSON returns the IE with the IE tree parsed from the contents if constructed.
+func newIEJSON(tag Tag, value []byte) *ieJSON {
+	return newElementJSON(newElement(tag, value))
+}
+
+// newElementJSON returns the element with the tree parsed from the contents if
+// constructed, in which the tags can be of any number, e.g. [50] in CAP.
+func newElementJSON(e *ber.Element) *ieJSON {
+	j := &ieJSON{
+		Tag:   "0x" + hex.EncodeToString(e.Identifier()),
+		Value: hex.EncodeToString(e.Value),
+	}
+	if !e.Constructed {
+		return j
+	}
+
+	es, err := ber.ReadElements(e.Value)
+	if err != nil {
+		return j
+	}
+	for _, child := range es {
+		j.IEs = append(j.IEs, newElementJSON(child))
+	}
+	return j
+}
+
+func (j *ieJSON) ie(field string) (*IE, error) {
+	tag, err := strconv.ParseUint(j.Tag, 0, 8)
+	if err != nil {
+		return nil, &InvalidNameError{Field: field + " tag", Name: j.Tag}
+	}
+	return hexIE(field, Tag(tag), j.Value)
+}
+
+func hexIE(field string, tag Tag, s string) (*IE, error) {
+	value, err := decodeHex(field, s)
+	if err != nil {
+		return nil, err
+	}
+	return NewIE(tag, value), nil
+}
+
+func decodeHex(field, s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, &InvalidNameError{Field: field, Name: s}
+	}
+	return b, nil
+}
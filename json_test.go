@@ -0,0 +1,311 @@
+package tcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func jsonCases(t *testing.T) []struct {
+	name string
+	tcap *TCAP
+} {
+	t.Helper()
+
+	cases := []struct {
+		name string
+		tcap *TCAP
+	}{
+		{
+			name: "End - AARE - ReturnResultLast",
+			tcap: NewEndReturnResultWithDialogue(
+				0x01020304, DialogueAsID, NetworkUnstructuredSsContext, 2, 1, 59, true,
+				[]byte{0x30, 0x06, 0x04, 0x01, 0x0f, 0x04, 0x01, 0xaa},
+			),
+		}, {
+			name: "Continue - ReturnError",
+			tcap: &TCAP{
+				Transaction: NewContinue(0x0a, 0x0b, []byte{}),
+				Components:  NewComponents(NewReturnError(1, 27, true, []byte{0x04, 0x01, 0x00})),
+			},
+		}, {
+			name: "End - Reject without Invoke ID",
+			tcap: &TCAP{
+				Transaction: NewEnd(0x0b, []byte{}),
+				Components: NewComponents(&Component{
+					Type:        NewContextSpecificConstructorTag(Reject),
+					InvokeID:    NewIE(NewUniversalPrimitiveTag(5), []byte{}),
+					ProblemCode: NewIE(NewContextSpecificPrimitiveTag(InvokeProblem), []byte{InvokeProblemUnrecognizedOperation}),
+				}),
+			},
+		}, {
+			name: "Begin - Invoke with global Operation Code and Linked ID",
+			tcap: &TCAP{
+				Transaction: NewBegin(0xc0ffee, []byte{}),
+				Components: NewComponents(&Component{
+					Type:          NewContextSpecificConstructorTag(Invoke),
+					InvokeID:      NewIE(NewUniversalPrimitiveTag(2), []byte{0x81}),
+					LinkedID:      NewIE(NewContextSpecificPrimitiveTag(0), []byte{0x05}),
+					OperationCode: NewIE(NewUniversalPrimitiveTag(6), []byte{0x2a, 0x86, 0x48, 0x01}),
+				}),
+			},
+		}, {
+			name: "Abort - P-Abort Cause",
+			tcap: &TCAP{Transaction: NewAbort(0x0b, ResourceLimitation, []byte{})},
+		}, {
+			name: "Abort - ABRT",
+			tcap: &TCAP{
+				Transaction: NewAbort(0x0b, 0, []byte{}),
+				Dialogue:    NewDialogue(DialogueAsID, 1, NewABRT(uint8(AbortDialogueServiceProvider)), []byte{}),
+			},
+		},
+	}
+	cases[0].tcap.Dialogue.DialoguePDU.ProtocolVersion = NewIE(NewContextSpecificPrimitiveTag(0), []byte{0x07, 0x80})
+	cases[4].tcap.Transaction.PAbortCause = NewIE(NewApplicationWidePrimitiveTag(10), []byte{ResourceLimitation})
+	cases[5].tcap.Transaction.PAbortCause = nil
+
+	for _, c := range appendBinaryCases {
+		b, err := hex.DecodeString(c.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases = append(cases, struct {
+			name string
+			tcap *TCAP
+		}{c.name, parsed})
+	}
+
+	for _, c := range []struct {
+		name string
+		hex  string
+	}{
+		{
+			name: "Begin - unknown elements in Transaction and Invoke",
+			hex:  "622e8301ff4804000000018401016c1da10e0201010201023003800105840107a10b0201020201033003800106850109",
+		}, {
+			name: "Begin - unknown elements in AARQ and EXTERNAL",
+			hex:  "622c4804000000016b242822060700118605010101a01460128a01ff80020780a1090607040000010013028101ff",
+		}, {
+			name: "Continue - 1-octet OTID and 2-octet DTID",
+			hex:  "65114801014902aabb6c08a106020101020102",
+		}, {
+			name: "Begin - 2-octet OTID",
+			hex:  "620e4802aabb6c08a106020101020102",
+		}, {
+			name: "End - 1-octet DTID",
+			hex:  "640d4901016c08a106020101020102",
+		}, {
+			name: "End - unknown element after result",
+			hex:  "641a4904000000026c12a210020101300802013b30030401018501ff",
+		},
+	} {
+		b, err := hex.DecodeString(c.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := parsed.MarshalBinary(); err != nil || !bytes.Equal(got, b) {
+			t.Fatalf("%s: got %x, %v, want %x", c.name, got, err, b)
+		}
+		cases = append(cases, struct {
+			name string
+			tcap *TCAP
+		}{c.name, parsed})
+	}
+	return cases
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, c := range jsonCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			want, err := c.tcap.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			j, err := json.Marshal(c.tcap)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := &TCAP{}
+			if err := json.Unmarshal(j, got); err != nil {
+				t.Fatalf("%v: %s", err, j)
+			}
+			b, err := got.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("got %x, want %x\nJSON: %s", b, want, j)
+			}
+
+			// The parsed one should also produce the same JSON.
+			parsed, err := Parse(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pj, err := json.Marshal(parsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(pj, j) {
+				t.Errorf("got %s, want %s", pj, j)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := hex.DecodeString(appendBinaryCases[0].hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"transaction":{"type":"Begin","otid":"00000002"},` +
+		`"dialogue":{"oid":"0.0.17.773.1.1.1","oidName":"dialogue-as-id",` +
+		`"pdu":{"type":"AARQ","protocolVersion":"0780",` +
+		`"applicationContextName":{"oid":"0.4.0.0.1.0.19.2","name":"networkUnstructuredSsContext","version":2},` +
+		`"userInformation":{"tag":"0xbe","value":"281d060704000001010101a012a01080069121436587f981069121436587f9",` +
+		`"ies":[{"tag":"0x28","value":"060704000001010101a012a01080069121436587f981069121436587f9",` +
+		`"ies":[{"tag":"0x06","value":"04000001010101"},{"tag":"0xa0","value":"a01080069121436587f981069121436587f9",` +
+		`"ies":[{"tag":"0xa0","value":"80069121436587f981069121436587f9",` +
+		`"ies":[{"tag":"0x80","value":"9121436587f9"},{"tag":"0x81","value":"9121436587f9"}]}]}]}]}}},` +
+		`"components":[{"type":"invoke","invokeID":0,"opCode":59,"parameter":{"tag":"0x30","value":"04010f0404f4f29c0e800891111111111111f1",` +
+		`"ies":[{"tag":"0x04","value":"0f"},{"tag":"0x04","value":"f4f29c0e"},{"tag":"0x80","value":"91111111111111f1"}]}}]}`
+	if string(j) != want {
+		t.Errorf("got %s\nwant %s", j, want)
+	}
+}
+
+func TestMarshalJSONMultiOctetTag(t *testing.T) {
+	// iMSI [50] and mscAddress [55] as in InitialDP of CAP.
+	param := []byte{0x30, 0x0e, 0x80, 0x01, 0x05, 0x9f, 0x32, 0x03, 0x12, 0x34, 0x56, 0x9f, 0x37, 0x02, 0xab, 0xcd}
+	j, err := json.Marshal(NewBeginInvoke(1, 0, 0, param))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `"parameter":{"tag":"0x30","value":"8001059f32031234569f3702abcd",` +
+		`"ies":[{"tag":"0x80","value":"05"},{"tag":"0x9f32","value":"123456"},{"tag":"0x9f37","value":"abcd"}]}`
+	if !strings.Contains(string(j), want) {
+		t.Errorf("got %s\nwant %s", j, want)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	j := `{
+		"transaction": {"type": "End", "dtid": "01020304"},
+		"dialogue": {
+			"oid": "0.0.17.773.1.1.1",
+			"pdu": {
+				"type": "AARE",
+				"protocolVersion": "0780",
+				"applicationContextName": {"oid": "0.4.0.0.1.0.19.2"},
+				"result": "accepted",
+				"resultSourceDiagnostic": {"source": "dialogue-service-user", "reason": 0}
+			}
+		},
+		"components": [
+			{"type": "returnResultLast", "invokeID": 1, "opCode": 59, "parameter": {"tag": "0x30", "value": "04010f"}}
+		]
+	}`
+
+	got := &TCAP{}
+	if err := json.Unmarshal([]byte(j), got); err != nil {
+		t.Fatal(err)
+	}
+	b, err := got.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "6443490401020304" +
+		"6b2a2828060700118605010101a01d611b80020780a109060704000001001302a203020100a305a103020100" +
+		"6c0fa20d020101300802013b300304010f"
+	if hex.EncodeToString(b) != want {
+		t.Errorf("got %x, want %s", b, want)
+	}
+
+	for _, invalid := range []string{
+		`{"transaction": {"type": "Start"}}`,
+		`{"transaction": {"type": "Begin", "otid": "xyz"}}`,
+		`{"components": [{"type": "invoke", "invokeID": 1, "opCode": "not.an.oid"}]}`,
+		`{"components": [{"type": "reject", "problem": {"type": "invokeProblem", "code": "unknownProblem"}}]}`,
+		`{"dialogue": {"oid": "0.0.17.773.1.1.1", "pdu": {"type": "AARE", "result": "maybe"}}}`,
+	} {
+		if err := json.Unmarshal([]byte(invalid), &TCAP{}); err == nil {
+			t.Errorf("expected error with %s", invalid)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	for _, c := range jsonCases(t) {
+		t.Run(c.name, func(t *testing.T) {
+			want, err := c.tcap.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := c.tcap.MarshalYAML()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Mimic the decoder of gopkg.in/yaml.v2, which gives maps with
+			// interface{} keys.
+			got := &TCAP{}
+			if err := got.UnmarshalYAML(func(out interface{}) error {
+				*(out.(*interface{})) = toYAMLv2(v)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := got.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("got %x, want %x", b, want)
+			}
+		})
+	}
+}
+
+func toYAMLv2(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, x := range v {
+			m[k] = toYAMLv2(x)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, x := range v {
+			s[i] = toYAMLv2(x)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+// The names of the enumerated values, used in the semantic representations.
+//
+// The ones with string methods (e.g. MessageTypeString) have the same names as
+// those, and the others have the identifiers in the ASN.1 definitions of Q.773.
+var (
+	messageTypeNames = map[int]string{
+		Unidirectional: "Unidirectional",
+		Begin:          "Begin",
+		End:            "End",
+		Continue:       "Continue",
+		Abort:          "Abort",
+	}
+
+	pAbortCauseNames = map[int]string{
+		int(UnrecognizedMessageType):          "UnrecognizedMessageType",
+		int(UnrecognizedTransactionID):        "UnrecognizedTransactionID",
+		int(BadlyFormattedTransactionPortion): "BadlyFormattedTransactionPortion",
+		int(IncorrectTransactionPortion):      "IncorrectTransactionPortion",
+		int(ResourceLimitation):               "ResourceLimitation",
+	}
+
+	dialogueOIDNames = map[string]string{
+		"0.0.17.773.1.1.1": "dialogue-as-id",
+		"0.0.17.773.1.2.1": "uniDialogue-as-id",
+	}
+
+	dialoguePDUTypeNames = map[int]string{
+		AARQ: "AARQ",
+		AARE: "AARE",
+		ABRT: "ABRT",
+	}
+
+	resultNames = map[int]string{
+		int(Accepted):   "accepted",
+		int(RejectPerm): "reject-permanent",
+	}
+
+	diagnosticSourceNames = map[int]string{
+		DialogueServiceUser:     "dialogue-service-user",
+		DialogueServiceProvider: "dialogue-service-provider",
+	}
+
+	diagnosticReasonNames = map[int]map[int]string{
+		DialogueServiceUser: {
+			0: "null",
+			1: "no-reason-given",
+			2: "application-context-name-not-supported",
+		},
+		DialogueServiceProvider: {
+			0: "null",
+			1: "no-reason-given",
+			2: "no-common-dialogue-portion",
+		},
+	}
+
+	abortSourceNames = map[int]string{
+		AbortDialogueServiceUser:     "dialogue-service-user",
+		AbortDialogueServiceProvider: "dialogue-service-provider",
+	}
+
+	componentTypeNames = map[int]string{
+		Invoke:              "invoke",
+		ReturnResultLast:    "returnResultLast",
+		ReturnError:         "returnError",
+		Reject:              "reject",
+		ReturnResultNotLast: "returnResultNotLast",
+	}
+
+	problemTypeNames = map[int]string{
+		GeneralProblem:      "generalProblem",
+		InvokeProblem:       "invokeProblem",
+		ReturnResultProblem: "returnResultProblem",
+		ReturnErrorProblem:  "returnErrorProblem",
+	}
+
+	problemCodeNames = map[int]map[int]string{
+		GeneralProblem: {
+			int(UnrecognizedComponent):    "unrecognizedComponent",
+			int(MistypedComponent):        "mistypedComponent",
+			int(BadlyStructuredComponent): "badlyStructuredComponent",
+		},
+		InvokeProblem: {
+			int(InvokeProblemDuplicateInvokeID):         "duplicateInvokeID",
+			int(InvokeProblemUnrecognizedOperation):     "unrecognizedOperation",
+			int(InvokeProblemMistypedParameter):         "mistypedParameter",
+			int(InvokeProblemResourceLimitation):        "resourceLimitation",
+			int(InvokeProblemInitiatingRelease):         "initiatingRelease",
+			int(InvokeProblemUnrecognizedLinkedID):      "unrecognizedLinkedID",
+			int(InvokeProblemLinkedResponseUnexpected):  "linkedResponseUnexpected",
+			int(InvokeProblemUnexpectedLinkedOperation): "unexpectedLinkedOperation",
+		},
+		ReturnResultProblem: {
+			int(ResultProblemUnrecognizedInvokeID):   "unrecognizedInvokeID",
+			int(ResultProblemReturnResultUnexpected): "returnResultUnexpected",
+			int(ResultProblemMistypedParameter):      "mistypedParameter",
+		},
+		ReturnErrorProblem: {
+			int(ErrorProblemUnrecognizedInvokeID):  "unrecognizedInvokeID",
+			int(ErrorProblemReturnErrorUnexpected): "returnErrorUnexpected",
+			int(ErrorProblemUnrecognizedError):     "unrecognizedError",
+			int(ErrorProblemUnexpectedError):       "unexpectedError",
+			int(ErrorProblemMistypedParameter):     "mistypedParameter",
+		},
+	}
+)
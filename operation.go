@@ -0,0 +1,295 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// OperationClass is the class of an operation in Q.771, which tells the
+// outcomes to be reported.
+type OperationClass uint8
+
+// OperationClass definitions.
+const (
+	// Class1 reports both success and failure.
+	Class1 OperationClass = iota + 1
+	// Class2 reports failure only.
+	Class2
+	// Class3 reports success only.
+	Class3
+	// Class4 reports neither success nor failure.
+	Class4
+)
+
+// TypedParameter is a typed argument, result or parameter of an error, which
+// is encoded as the whole element in Parameter of Component.
+//
+// The Parameter types in gsmmap and cap packages implement it.
+type TypedParameter interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Operation describes an operation in an Application Context.
+type Operation struct {
+	Name  string
+	Code  int
+	Class OperationClass
+	// Timer is the default invocation timer, or 0 if not specified.
+	Timer time.Duration
+	// Linked is the Operation Codes of the operations that can be invoked
+	// linked to this one.
+	Linked []int
+	// Errors is the Error Codes that can be returned.
+	Errors []int
+	// NewArgument and NewResult return the empty argument and result, which are
+	// nil if the operation does not have them or they are not known.
+	NewArgument func() TypedParameter
+	NewResult   func() TypedParameter
+}
+
+// Decode decodes the Parameter in c as the argument if c is Invoke, or as the
+// result if c is ReturnResult.
+//
+// It returns nil without error if c does not have the Parameter.
+func (o *Operation) Decode(c *Component) (TypedParameter, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+
+	var f func() TypedParameter
+	switch c.Type.Code() {
+	case Invoke:
+		f = o.NewArgument
+	case ReturnResultLast, ReturnResultNotLast:
+		f = o.NewResult
+	default:
+		return nil, &UnknownOperationError{Name: o.Name, Reason: "unexpected component type: " + c.ComponentTypeString()}
+	}
+	if f == nil {
+		return nil, &UnknownOperationError{Name: o.Name, Reason: "no codec of the parameter"}
+	}
+
+	b, err := c.Parameter.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	p := f()
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Error describes an error that can be returned by the operations in an
+// Application Context.
+type Error struct {
+	Name string
+	Code int
+	// NewParameter returns the empty parameter, which is nil if the error does
+	// not have it or it is not known.
+	NewParameter func() TypedParameter
+}
+
+// Decode decodes the Parameter in ReturnError c as the parameter of the error.
+//
+// It returns nil without error if c does not have the Parameter.
+func (e *Error) Decode(c *Component) (TypedParameter, error) {
+	if c.Parameter == nil {
+		return nil, nil
+	}
+	if c.Type.Code() != ReturnError {
+		return nil, &UnknownOperationError{Name: e.Name, Reason: "unexpected component type: " + c.ComponentTypeString()}
+	}
+	if e.NewParameter == nil {
+		return nil, &UnknownOperationError{Name: e.Name, Reason: "no codec of the parameter"}
+	}
+
+	b, err := c.Parameter.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	p := e.NewParameter()
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Registry is a set of Operations and Errors by the Application Context Name
+// and the Operation or Error Code. It is safe for concurrent use.
+//
+// The Application Context Names are in the dotted notation, e.g.
+// "0.4.0.0.1.0.1.3" for networkLocUpContext-v3. The empty one is used for the
+// Operations and Errors in any Application Context, which are looked up when not found
+// with the given one.
+type Registry struct {
+	mu   sync.RWMutex
+	ops  map[operationKey]*Operation
+	errs map[operationKey]*Error
+}
+
+type operationKey struct {
+	acn  string
+	code int
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		ops:  map[operationKey]*Operation{},
+		errs: map[operationKey]*Error{},
+	}
+}
+
+// DefaultRegistry is the Registry used by the package, e.g. to print the names
+// of the operations. The operations in gsmmap and cap packages are registered
+// in it when they are imported.
+var DefaultRegistry = NewRegistry()
+
+// Register adds op in the Application Context, replacing the one with the same
+// Operation Code if any.
+func (r *Registry) Register(acn string, op *Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ops[operationKey{acn, op.Code}] = op
+}
+
+// Lookup returns the Operation with the Operation Code in the Application
+// Context, or the one in any Application Context if not found. It returns nil
+// if neither is found.
+func (r *Registry) Lookup(acn string, code int) *Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if op, ok := r.ops[operationKey{acn, code}]; ok {
+		return op
+	}
+	return r.ops[operationKey{"", code}]
+}
+
+// LookupComponent returns the Operation of Invoke or ReturnResult c in t, with
+// the Application Context Name in the dialogue portion of t. It returns nil if
+// not found or c does not have the local Operation Code.
+func (r *Registry) LookupComponent(t *TCAP, c *Component) *Operation {
+	code, ok := localCode(c.OperationCode)
+	if !ok {
+		return nil
+	}
+	return r.Lookup(t.applicationContext(), code)
+}
+
+// Operations returns the Operations in the Application Context in the order of
+// the Operation Code.
+func (r *Registry) Operations(acn string) []*Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ops []*Operation
+	for key, op := range r.ops {
+		if key.acn == acn {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Code < ops[j].Code })
+	return ops
+}
+
+// RegisterError adds e in the Application Context, replacing the one with the
+// same Error Code if any.
+func (r *Registry) RegisterError(acn string, e *Error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs[operationKey{acn, e.Code}] = e
+}
+
+// LookupError returns the Error with the Error Code in the Application Context,
+// or the one in any Application Context if not found. It returns nil if neither
+// is found.
+func (r *Registry) LookupError(acn string, code int) *Error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if e, ok := r.errs[operationKey{acn, code}]; ok {
+		return e
+	}
+	return r.errs[operationKey{"", code}]
+}
+
+// LookupComponentError returns the Error of ReturnError c in t, with the
+// Application Context Name in the dialogue portion of t. It returns nil if not
+// found or c does not have the local Error Code.
+func (r *Registry) LookupComponentError(t *TCAP, c *Component) *Error {
+	code, ok := localCode(c.ErrorCode)
+	if !ok {
+		return nil
+	}
+	return r.LookupError(t.applicationContext(), code)
+}
+
+// RegisterOperation adds op in the Application Context in DefaultRegistry.
+func RegisterOperation(acn string, op *Operation) {
+	DefaultRegistry.Register(acn, op)
+}
+
+// LookupOperation returns the Operation with the Operation Code in the
+// Application Context in DefaultRegistry, or nil if not found.
+func LookupOperation(acn string, code int) *Operation {
+	return DefaultRegistry.Lookup(acn, code)
+}
+
+// RegisterError adds e in the Application Context in DefaultRegistry.
+func RegisterError(acn string, e *Error) {
+	DefaultRegistry.RegisterError(acn, e)
+}
+
+// LookupError returns the Error with the Error Code in the Application Context
+// in DefaultRegistry, or nil if not found.
+func LookupError(acn string, code int) *Error {
+	return DefaultRegistry.LookupError(acn, code)
+}
+
+// localCode returns the value of the local Operation or Error Code.
+func localCode(ie *IE) (int, bool) {
+	if ie == nil || ie.Tag != NewUniversalPrimitiveTag(2) {
+		return 0, false
+	}
+	return ber.DecodeInteger(ie.Value), true
+}
+
+// applicationContext returns the Application Context Name in the dialogue
+// portion in the dotted notation, or the empty string if not present.
+func (t *TCAP) applicationContext() string {
+	d := t.Dialogue
+	if d == nil || d.DialoguePDU == nil || d.DialoguePDU.ApplicationContextName == nil {
+		return ""
+	}
+	oid, _, _, err := d.DialoguePDU.applicationContext()
+	if err != nil {
+		return ""
+	}
+	return oid
+}
+
+// UnknownOperationError indicates that an operation or an error cannot be
+// handled as it is not described enough.
+type UnknownOperationError struct {
+	Name   string
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *UnknownOperationError) Error() string {
+	return fmt.Sprintf("tcap: cannot handle operation %s: %s", e.Name, e.Reason)
+}
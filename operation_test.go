@@ -0,0 +1,153 @@
+package tcap
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// octetString is an OCTET STRING as a TypedParameter for testing.
+type octetString []byte
+
+func (o octetString) MarshalBinary() ([]byte, error) {
+	return append([]byte{0x04, byte(len(o))}, o...), nil
+}
+
+func (o *octetString) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 || b[0] != 0x04 {
+		return &InvalidCodeError{Code: int(b[0])}
+	}
+	*o = append(octetString{}, b[2:]...)
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	const acn = "0.4.0.0.1.0.2.3"
+
+	r := NewRegistry()
+	cancel := &Operation{
+		Name: "cancelLocation", Code: 3, Class: Class1, Timer: 30 * time.Second,
+		NewArgument: func() TypedParameter { return &octetString{} },
+	}
+	vendor := &Operation{Name: "vendorOperation", Code: 100, Class: Class4}
+	r.Register(acn, cancel)
+	r.Register("", vendor)
+
+	for _, c := range []struct {
+		acn  string
+		code int
+		want *Operation
+	}{
+		{acn, 3, cancel},
+		{acn, 100, vendor},
+		{"0.4.0.0.1.0.2.2", 3, nil},
+		{"", 100, vendor},
+		{acn, 4, nil},
+	} {
+		if got := r.Lookup(c.acn, c.code); got != c.want {
+			t.Errorf("%s/%d: got %v, want %v", c.acn, c.code, got, c.want)
+		}
+	}
+
+	if ops := r.Operations(acn); len(ops) != 1 || ops[0] != cancel {
+		t.Errorf("got %v", ops)
+	}
+
+	msg := NewBeginInvokeWithDialogue(1, DialogueAsID, LocationCancellationContext, 3, 0, 3, []byte{0x04, 0x02, 0x01, 0x02})
+	c := msg.Components.Component[0]
+	op := r.LookupComponent(msg, c)
+	if op != cancel {
+		t.Fatalf("got %v", op)
+	}
+	p, err := op.Decode(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := *p.(*octetString); !bytes.Equal(got, []byte{0x01, 0x02}) {
+		t.Errorf("got %x", got)
+	}
+
+	if _, err := vendor.Decode(NewInvoke(0, -1, 100, true, []byte{0x04, 0x00})); err == nil {
+		t.Error("expected error with the operation without codec")
+	}
+	if op := r.LookupComponent(msg, NewInvoke(0, -1, 3, false, nil)); op != nil {
+		t.Errorf("got %v with global Operation Code", op)
+	}
+}
+
+func TestFormatTreeOperationName(t *testing.T) {
+	RegisterOperation("", &Operation{Name: "vendorOperation", Code: 120, Class: Class4})
+
+	got := fmt.Sprintf("%+v", NewBeginInvoke(1, 0, 120, nil))
+	if want := "opCode: localValue: vendorOperation (120)\n"; !strings.Contains(got, want) {
+		t.Errorf("missing %q in\n%s", want, got)
+	}
+}
+
+func TestErrorRegistry(t *testing.T) {
+	const acn = "0.4.0.0.1.0.50.1"
+
+	r := NewRegistry()
+	anyCtx := &Error{Name: "systemFailure", Code: 34, NewParameter: func() TypedParameter { return &octetString{} }}
+	inCtx := &Error{Name: "cancelFailed", Code: 1}
+	r.RegisterError("", anyCtx)
+	r.RegisterError(acn, inCtx)
+
+	if got := r.LookupError(acn, 1); got != inCtx {
+		t.Errorf("got %v", got)
+	}
+	if got := r.LookupError("0.4.0.0.1.0.2.3", 1); got != nil {
+		t.Errorf("got %v", got)
+	}
+	if got := r.LookupError(acn, 34); got != anyCtx {
+		t.Errorf("got %v", got)
+	}
+
+	msg := NewEndReturnError(1, 0, 34, true, nil)
+	c := msg.Components.Component[0]
+	c.Parameter = &IE{Tag: NewUniversalPrimitiveTag(4), Value: []byte{0x01}}
+	c.SetLength()
+	if e := r.LookupComponentError(msg, c); e != anyCtx {
+		t.Fatalf("got %v", e)
+	}
+	p, err := anyCtx.Decode(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := *p.(*octetString); !bytes.Equal(got, []byte{0x01}) {
+		t.Errorf("got %x", got)
+	}
+	if _, err := inCtx.Decode(c); err == nil {
+		t.Error("expected error with the error without codec")
+	}
+}
+
+func TestComponentErrorName(t *testing.T) {
+	acn := "0.4.0.0.1.0.20.3"
+	RegisterError("", &Error{Name: "vendorError", Code: 120})
+	RegisterError(acn, &Error{Name: "contextError", Code: 122})
+
+	msg := NewEndReturnError(1, 0, 120, true, nil)
+	if got := msg.Components.Component[0].ErrorName(acn); got != "vendorError" {
+		t.Errorf("got %q", got)
+	}
+	if got := NewReturnError(0, 122, true, nil).ErrorName(acn); got != "contextError" {
+		t.Errorf("got %q in the Application Context", got)
+	}
+	if got := NewReturnError(0, 122, true, nil).ErrorName(""); got != "" {
+		t.Errorf("got %q without the Application Context", got)
+	}
+	if got := NewReturnError(0, 121, true, nil).ErrorName(acn); got != "" {
+		t.Errorf("got %q with unknown code", got)
+	}
+	if got := NewInvoke(0, -1, 120, true, nil).ErrorName(acn); got != "" {
+		t.Errorf("got %q with Invoke", got)
+	}
+
+	got := fmt.Sprintf("%+v", msg)
+	if want := "errorCode: localValue: vendorError (120)\n"; !strings.Contains(got, want) {
+		t.Errorf("missing %q in\n%s", want, got)
+	}
+}
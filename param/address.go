@@ -0,0 +1,89 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+// Nature of Address Indicator definitions.
+const (
+	NatureUnknown uint8 = iota
+	NatureInternational
+	NatureNational
+	NatureNetworkSpecific
+	NatureSubscriber
+	_
+	NatureAbbreviated
+)
+
+// Numbering Plan Indicator definitions.
+const (
+	PlanUnknown    uint8 = 0
+	PlanISDN       uint8 = 1
+	PlanData       uint8 = 3
+	PlanTelex      uint8 = 4
+	PlanLandMobile uint8 = 6
+	PlanNational   uint8 = 8
+	PlanPrivate    uint8 = 9
+)
+
+// AddressString is an AddressString or ISDN-AddressString in MAP.
+type AddressString struct {
+	NatureOfAddress uint8
+	NumberingPlan   uint8
+	Digits          string
+}
+
+// NewISDNAddress creates an AddressString of the international number in ISDN
+// numbering plan, e.g. MSISDN and the number of the network nodes.
+func NewISDNAddress(digits string) *AddressString {
+	return &AddressString{
+		NatureOfAddress: NatureInternational,
+		NumberingPlan:   PlanISDN,
+		Digits:          digits,
+	}
+}
+
+// Encode returns the contents octets of an AddressString.
+func (a *AddressString) Encode() ([]byte, error) {
+	digits, err := EncodeTBCD(a.Digits)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{0x80 | (a.NatureOfAddress&0x07)<<4 | a.NumberingPlan&0x0f}, digits...), nil
+}
+
+// Decode sets the values retrieved from the contents octets in an AddressString.
+func (a *AddressString) Decode(b []byte) error {
+	if len(b) < 1 {
+		return &InvalidValueError{Reason: "empty address string"}
+	}
+	a.NatureOfAddress = (b[0] >> 4) & 0x07
+	a.NumberingPlan = b[0] & 0x0f
+	a.Digits = DecodeTBCD(b[1:])
+	return nil
+}
+
+// MarshalBinary returns the byte sequence generated from an AddressString as
+// an OCTET STRING.
+func (a *AddressString) MarshalBinary() ([]byte, error) {
+	return octetString(a.Encode())
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence of an OCTET
+// STRING in an AddressString.
+func (a *AddressString) UnmarshalBinary(b []byte) error {
+	v, err := readOctetString(b)
+	if err != nil {
+		return err
+	}
+	return a.Decode(v)
+}
+
+// String returns the digits of an AddressString, with "+" if it is an
+// international number.
+func (a *AddressString) String() string {
+	if a.NatureOfAddress == NatureInternational {
+		return "+" + a.Digits
+	}
+	return a.Digits
+}
@@ -0,0 +1,181 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+import (
+	"fmt"
+	"unicode/utf16"
+)
+
+// DefaultAlphabet is the data coding scheme of the USSD string in the GSM 7 bit
+// default alphabet with the language unspecified, in 3GPP TS 23.038.
+const DefaultAlphabet uint8 = 0x0f
+
+// The characters of the GSM 7 bit default alphabet, of which the index is the
+// code, and of the extension table after the escape (0x1b).
+var (
+	gsm7Basic = []rune("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞ\x1bÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+	gsm7Extension = map[byte]rune{
+		0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\',
+		0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x65: '€',
+	}
+)
+
+const (
+	gsm7Escape = 0x1b
+	gsm7CR     = 0x0d
+)
+
+// EncodeGSM7 encodes s in the septets of the GSM 7 bit default alphabet, with
+// the escape for the characters in the extension table. The septets are not
+// packed.
+func EncodeGSM7(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+loop:
+	for _, r := range s {
+		if r != gsm7Escape {
+			for i, c := range gsm7Basic {
+				if c == r {
+					b = append(b, byte(i))
+					continue loop
+				}
+			}
+		}
+		for code, c := range gsm7Extension {
+			if c == r {
+				b = append(b, gsm7Escape, code)
+				continue loop
+			}
+		}
+		return nil, &InvalidValueError{Reason: fmt.Sprintf("character not in GSM 7 bit default alphabet: %q", r)}
+	}
+	return b, nil
+}
+
+// DecodeGSM7 decodes the septets of the GSM 7 bit default alphabet, which are
+// not packed. The unknown ones in the extension table are decoded as the ones
+// in the default alphabet.
+func DecodeGSM7(septets []byte) string {
+	s := make([]rune, 0, len(septets))
+	for i := 0; i < len(septets); i++ {
+		v := septets[i] & 0x7f
+		if v == gsm7Escape && i+1 < len(septets) {
+			i++
+			if r, ok := gsm7Extension[septets[i]&0x7f]; ok {
+				s = append(s, r)
+				continue
+			}
+			v = septets[i] & 0x7f
+		}
+		s = append(s, gsm7Basic[v])
+	}
+	return string(s)
+}
+
+// Pack7Bit packs the septets into the octets, from the least significant bit.
+func Pack7Bit(septets []byte) []byte {
+	b := make([]byte, (len(septets)*7+7)/8)
+	for i, s := range septets {
+		bit := i * 7
+		b[bit/8] |= (s & 0x7f) << uint(bit%8)
+		if bit%8 > 1 {
+			b[bit/8+1] |= (s & 0x7f) >> uint(8-bit%8)
+		}
+	}
+	return b
+}
+
+// Unpack7Bit unpacks the octets into the septets. The bits left at the end,
+// which are less than a septet, are ignored as the padding.
+func Unpack7Bit(b []byte) []byte {
+	septets := make([]byte, len(b)*8/7)
+	for i := range septets {
+		bit := i * 7
+		s := b[bit/8] >> uint(bit%8)
+		if bit%8 > 1 {
+			s |= b[bit/8+1] << uint(8-bit%8)
+		}
+		septets[i] = s & 0x7f
+	}
+	return septets
+}
+
+// EncodeUSSD encodes s as the USSD string in the GSM 7 bit default alphabet,
+// to be used with DefaultAlphabet as the data coding scheme.
+//
+// As in 3GPP TS 23.038, <CR> is used as the padding if the last octet has the
+// 7 spare bits, so that it is not confused with "@".
+func EncodeUSSD(s string) ([]byte, error) {
+	septets, err := EncodeGSM7(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(septets)%8 == 7 {
+		septets = append(septets, gsm7CR)
+	}
+	return Pack7Bit(septets), nil
+}
+
+// DecodeUSSD decodes b as the USSD string encoded as indicated by the data
+// coding scheme, which is either in the GSM 7 bit default alphabet or in UCS2.
+//
+// The <CR> at the end of the string on the octet boundary is removed as the
+// padding. The language indication at the beginning, if any, is kept.
+func DecodeUSSD(dcs uint8, b []byte) (string, error) {
+	switch alphabet(dcs) {
+	case alphabetGSM7:
+		septets := Unpack7Bit(b)
+		if n := len(septets); len(b)%7 == 0 && n > 0 && septets[n-1] == gsm7CR {
+			septets = septets[:n-1]
+		}
+		return DecodeGSM7(septets), nil
+	case alphabetUCS2:
+		if len(b)%2 != 0 {
+			return "", &InvalidValueError{Reason: fmt.Sprintf("odd length of UCS2 string: %d", len(b))}
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+		return string(utf16.Decode(u)), nil
+	}
+	return "", &InvalidValueError{Reason: fmt.Sprintf("unsupported data coding scheme: %#02x", dcs)}
+}
+
+// The character sets indicated by the data coding scheme.
+const (
+	alphabetGSM7 = iota
+	alphabetUCS2
+	alphabetOther
+)
+
+// alphabet returns the character set indicated by the data coding scheme for
+// the Cell Broadcast Service, which is also used in USSD.
+func alphabet(dcs uint8) int {
+	switch dcs >> 4 {
+	case 0x0, 0x2, 0x3:
+		return alphabetGSM7
+	case 0x1:
+		switch dcs & 0x0f {
+		case 0x0:
+			return alphabetGSM7
+		case 0x1:
+			return alphabetUCS2
+		}
+	case 0x4, 0x5, 0x6, 0x7, 0x9:
+		switch (dcs >> 2) & 0x03 {
+		case 0:
+			return alphabetGSM7
+		case 2:
+			return alphabetUCS2
+		}
+	case 0xf:
+		if dcs&0x04 == 0 {
+			return alphabetGSM7
+		}
+	}
+	return alphabetOther
+}
@@ -0,0 +1,198 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Nature of Address Indicator definitions in the ISUP format, which differ from
+// the ones in AddressString.
+const (
+	ISUPSubscriberNumber    uint8 = 1
+	ISUPUnknown             uint8 = 2
+	ISUPNationalNumber      uint8 = 3
+	ISUPInternationalNumber uint8 = 4
+)
+
+// Address Presentation Restricted Indicator definitions.
+const (
+	PresentationAllowed uint8 = iota
+	PresentationRestricted
+	AddressNotAvailable
+)
+
+// Screening Indicator definitions.
+const (
+	UserProvidedNotVerified uint8 = iota
+	UserProvidedVerifiedAndPassed
+	UserProvidedVerifiedAndFailed
+	NetworkProvided
+)
+
+// CalledPartyNumber is the Called Party Number in ISUP (ITU-T Q.763), used in
+// CAP as it is encoded in ISUP.
+//
+// Digits are the address signals in hexadecimal, e.g. "b" and "c" for code 11
+// and code 12, and "f" for ST.
+type CalledPartyNumber struct {
+	NatureOfAddress       uint8
+	InternalNetworkNumber bool
+	NumberingPlan         uint8
+	Digits                string
+}
+
+// NewCalledPartyNumber creates a CalledPartyNumber of the international number
+// in ISDN numbering plan.
+func NewCalledPartyNumber(digits string) *CalledPartyNumber {
+	return &CalledPartyNumber{
+		NatureOfAddress: ISUPInternationalNumber,
+		NumberingPlan:   PlanISDN,
+		Digits:          digits,
+	}
+}
+
+// Encode returns the contents octets of a CalledPartyNumber.
+func (c *CalledPartyNumber) Encode() ([]byte, error) {
+	var inn uint8
+	if c.InternalNetworkNumber {
+		inn = 0x80
+	}
+	return encodeISUPNumber(c.NatureOfAddress, inn|(c.NumberingPlan&0x07)<<4, c.Digits)
+}
+
+// Decode sets the values retrieved from the contents octets in a CalledPartyNumber.
+func (c *CalledPartyNumber) Decode(b []byte) error {
+	if len(b) < 2 {
+		return &InvalidValueError{Reason: fmt.Sprintf("too short called party number: %x", b)}
+	}
+	*c = CalledPartyNumber{
+		NatureOfAddress:       b[0] & 0x7f,
+		InternalNetworkNumber: b[1]&0x80 != 0,
+		NumberingPlan:         (b[1] >> 4) & 0x07,
+		Digits:                decodeISUPDigits(b[2:], b[0]&0x80 != 0),
+	}
+	return nil
+}
+
+// MarshalBinary returns the byte sequence generated from a CalledPartyNumber
+// as an OCTET STRING.
+func (c *CalledPartyNumber) MarshalBinary() ([]byte, error) {
+	return octetString(c.Encode())
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence of an OCTET
+// STRING in a CalledPartyNumber.
+func (c *CalledPartyNumber) UnmarshalBinary(b []byte) error {
+	v, err := readOctetString(b)
+	if err != nil {
+		return err
+	}
+	return c.Decode(v)
+}
+
+// CallingPartyNumber is the Calling Party Number in ISUP (ITU-T Q.763), used
+// in CAP as it is encoded in ISUP.
+type CallingPartyNumber struct {
+	NatureOfAddress  uint8
+	NumberIncomplete bool
+	NumberingPlan    uint8
+	Presentation     uint8
+	Screening        uint8
+	Digits           string
+}
+
+// NewCallingPartyNumber creates a CallingPartyNumber of the international
+// number in ISDN numbering plan, provided by the network and allowed to be
+// presented.
+func NewCallingPartyNumber(digits string) *CallingPartyNumber {
+	return &CallingPartyNumber{
+		NatureOfAddress: ISUPInternationalNumber,
+		NumberingPlan:   PlanISDN,
+		Screening:       NetworkProvided,
+		Digits:          digits,
+	}
+}
+
+// Encode returns the contents octets of a CallingPartyNumber.
+func (c *CallingPartyNumber) Encode() ([]byte, error) {
+	var ni uint8
+	if c.NumberIncomplete {
+		ni = 0x80
+	}
+	return encodeISUPNumber(c.NatureOfAddress,
+		ni|(c.NumberingPlan&0x07)<<4|(c.Presentation&0x03)<<2|c.Screening&0x03, c.Digits)
+}
+
+// Decode sets the values retrieved from the contents octets in a CallingPartyNumber.
+func (c *CallingPartyNumber) Decode(b []byte) error {
+	if len(b) < 2 {
+		return &InvalidValueError{Reason: fmt.Sprintf("too short calling party number: %x", b)}
+	}
+	*c = CallingPartyNumber{
+		NatureOfAddress:  b[0] & 0x7f,
+		NumberIncomplete: b[1]&0x80 != 0,
+		NumberingPlan:    (b[1] >> 4) & 0x07,
+		Presentation:     (b[1] >> 2) & 0x03,
+		Screening:        b[1] & 0x03,
+		Digits:           decodeISUPDigits(b[2:], b[0]&0x80 != 0),
+	}
+	return nil
+}
+
+// MarshalBinary returns the byte sequence generated from a CallingPartyNumber
+// as an OCTET STRING.
+func (c *CallingPartyNumber) MarshalBinary() ([]byte, error) {
+	return octetString(c.Encode())
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence of an OCTET
+// STRING in a CallingPartyNumber.
+func (c *CallingPartyNumber) UnmarshalBinary(b []byte) error {
+	v, err := readOctetString(b)
+	if err != nil {
+		return err
+	}
+	return c.Decode(v)
+}
+
+// encodeISUPNumber encodes the number with the odd/even indicator and the
+// Nature of Address Indicator in the first octet and ind in the second.
+func encodeISUPNumber(nai, ind uint8, digits string) ([]byte, error) {
+	b := make([]byte, 2, 2+(len(digits)+1)/2)
+	b[0], b[1] = nai&0x7f, ind
+	if len(digits)%2 != 0 {
+		b[0] |= 0x80
+	}
+
+	for i := 0; i < len(digits); i++ {
+		v, err := strconv.ParseUint(digits[i:i+1], 16, 8)
+		if err != nil {
+			return nil, &InvalidValueError{Reason: fmt.Sprintf("invalid digit: %q", digits[i])}
+		}
+		if i%2 == 0 {
+			b = append(b, byte(v))
+		} else {
+			b[len(b)-1] |= byte(v) << 4
+		}
+	}
+	return b, nil
+}
+
+// decodeISUPDigits decodes the address signals, which has the first digit in
+// the lower nibble. The last upper nibble is a filler if odd is true.
+func decodeISUPDigits(b []byte, odd bool) string {
+	const digits = "0123456789abcdef"
+
+	s := make([]byte, 0, len(b)*2)
+	for _, x := range b {
+		s = append(s, digits[x&0x0f], digits[x>>4])
+	}
+	if odd && len(s) != 0 {
+		s = s[:len(s)-1]
+	}
+	return string(s)
+}
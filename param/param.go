@@ -0,0 +1,68 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package param provides the types of the telephony numbers and strings commonly
+used in the Parameters of MAP and CAP: TBCD-STRING such as IMSI and IMEI,
+AddressString and ISDN-AddressString, USSD strings in the GSM 7 bit default
+alphabet, and CalledPartyNumber and CallingPartyNumber in the ISUP format.
+
+Each type has Encode and Decode for the contents octets, which are used as the
+fields in the typed Parameters with the tag given by where they are used, and
+MarshalBinary and UnmarshalBinary for the whole OCTET STRING, which can be used
+as the Parameter in Component as it is.
+
+	imsi := param.IMSI("001010123456789")
+	b, err := imsi.MarshalBinary() // 040800010121436587f9
+	if err != nil {
+		// ...
+	}
+	invoke := tcap.NewInvoke(0, -1, 3, true, b)
+
+	msisdn := param.NewISDNAddress("819012345678")
+	v, err := msisdn.Encode() // 91180921436587
+*/
+package param
+
+import (
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// tagOctetString is the universal tag number of OCTET STRING.
+const tagOctetString = 4
+
+// octetString returns v as an OCTET STRING.
+func octetString(v []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return ber.AppendElement(nil, ber.ClassUniversal, false, tagOctetString, v), nil
+}
+
+// readOctetString reads b as an OCTET STRING and returns the contents.
+func readOctetString(b []byte) ([]byte, error) {
+	e, n, err := ber.ReadElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(b) {
+		return nil, &InvalidValueError{Reason: fmt.Sprintf("%d trailing byte(s)", len(b)-n)}
+	}
+	if !e.Is(ber.ClassUniversal, tagOctetString) || e.Constructed {
+		return nil, &InvalidValueError{Reason: fmt.Sprintf("not an octet string: %x", b)}
+	}
+	return e.Value, nil
+}
+
+// InvalidValueError indicates that a value cannot be encoded or decoded.
+type InvalidValueError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidValueError) Error() string {
+	return "param: invalid value: " + e.Reason
+}
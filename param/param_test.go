@@ -0,0 +1,214 @@
+package param_test
+
+import (
+	"encoding"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pascaldekloe/goe/verify"
+
+	"github.com/danievanzyl/go-ya-tcap/param"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type value interface {
+	Encode() ([]byte, error)
+	encoding.BinaryMarshaler
+}
+
+func imsi(s string) *param.IMSI {
+	i := param.IMSI(s)
+	return &i
+}
+
+func imei(s string) *param.IMEI {
+	i := param.IMEI(s)
+	return &i
+}
+
+var cases = []struct {
+	description string
+	structured  value
+	empty       func() encoding.BinaryUnmarshaler
+	serialized  string
+}{
+	{
+		"IMSI", imsi("001010123456789"),
+		func() encoding.BinaryUnmarshaler { return imsi("") },
+		"040800010121436587f9",
+	}, {
+		"IMEI", imei("3521510123456780"),
+		func() encoding.BinaryUnmarshaler { return imei("") },
+		"04085312151032547608",
+	}, {
+		"ISDN-AddressString", param.NewISDNAddress("819012345678"),
+		func() encoding.BinaryUnmarshaler { return &param.AddressString{} },
+		"040791180921436587",
+	}, {
+		"AddressString national", &param.AddressString{NatureOfAddress: param.NatureNational, NumberingPlan: param.PlanISDN, Digits: "9012345"},
+		func() encoding.BinaryUnmarshaler { return &param.AddressString{} },
+		"0405a1092143f5",
+	}, {
+		"CalledPartyNumber", param.NewCalledPartyNumber("819012345"),
+		func() encoding.BinaryUnmarshaler { return &param.CalledPartyNumber{} },
+		"040784101809214305",
+	}, {
+		"CalledPartyNumber with code 11", &param.CalledPartyNumber{NatureOfAddress: param.ISUPUnknown, NumberingPlan: param.PlanISDN, Digits: "b12"},
+		func() encoding.BinaryUnmarshaler { return &param.CalledPartyNumber{} },
+		"040482101b02",
+	}, {
+		"CallingPartyNumber", &param.CallingPartyNumber{
+			NatureOfAddress: param.ISUPInternationalNumber,
+			NumberingPlan:   param.PlanISDN,
+			Presentation:    param.PresentationRestricted,
+			Screening:       param.NetworkProvided,
+			Digits:          "81901234",
+		},
+		func() encoding.BinaryUnmarshaler { return &param.CallingPartyNumber{} },
+		"0406041718092143",
+	},
+}
+
+func TestValues(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+
+			got := c.empty()
+			if err := got.UnmarshalBinary(b); err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", got, c.structured) {
+				t.Error("decoded value differs")
+			}
+
+			encoded, err := c.structured.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+
+			contents, err := c.structured.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", contents, b[2:]) {
+				t.Error("contents differ")
+			}
+		})
+	}
+}
+
+func TestInvalidValues(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		v           value
+	}{
+		{"IMSI with non-decimal digit", imsi("00101012345678*")},
+		{"too long IMSI", imsi("0010101234567890")},
+		{"too short IMEI", imei("35215101234567")},
+		{"AddressString with invalid digit", param.NewISDNAddress("8190x")},
+		{"CalledPartyNumber with invalid digit", param.NewCalledPartyNumber("8190x")},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := c.v.MarshalBinary(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	for _, c := range []struct {
+		description string
+		v           encoding.BinaryUnmarshaler
+		serialized  string
+	}{
+		{"IMEI of 7 octets", imei(""), "040753121510325476"},
+		{"not an octet string", imsi(""), "800800010121436587f9"},
+		{"trailing bytes", &param.AddressString{}, "0403911809ff"},
+		{"empty AddressString", &param.AddressString{}, "0400"},
+		{"too short CallingPartyNumber", &param.CallingPartyNumber{}, "040104"},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			if err := c.v.UnmarshalBinary(mustHex(c.serialized)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestUSSD(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		text        string
+		serialized  string
+	}{
+		{"service code", "*100#", "aa180c3602"},
+		// 7 characters, of which the last octet has 7 spare bits filled with <CR>.
+		{"padding", "1234567", "31d98c56b3dd1a"},
+		{"extension table", "€5 ok", "9b720df45e03"},
+		{"at sign", "[@ä]", "1b1e60bff101"},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			b := mustHex(c.serialized)
+
+			got, err := param.DecodeUSSD(param.DefaultAlphabet, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.text {
+				t.Errorf("got %q, want %q", got, c.text)
+			}
+
+			encoded, err := param.EncodeUSSD(c.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !verify.Values(t, "", encoded, b) {
+				t.Error("encoded value differs")
+			}
+		})
+	}
+
+	got, err := param.DecodeUSSD(0x48, mustHex("0048006920ac"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Hi€" {
+		t.Errorf("got %q in UCS2", got)
+	}
+
+	if _, err := param.EncodeUSSD("日本"); err == nil {
+		t.Error("expected error with the characters not in the alphabet")
+	}
+	if _, err := param.DecodeUSSD(0x44, []byte{0x01}); err == nil {
+		t.Error("expected error with 8 bit data")
+	}
+}
+
+func TestGSM7(t *testing.T) {
+	// All the characters in the default alphabet except the escape, and the
+	// ones in the extension table.
+	s := "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà" +
+		"\f^{}\\[~]|€"
+
+	septets, err := param.EncodeGSM7(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(septets) != 127+2*10 {
+		t.Errorf("got %d septets", len(septets))
+	}
+	if got := param.DecodeGSM7(param.Unpack7Bit(param.Pack7Bit(septets))[:len(septets)]); got != s {
+		t.Errorf("got %q", got)
+	}
+}
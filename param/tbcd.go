@@ -0,0 +1,138 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package param
+
+import "fmt"
+
+// tbcdDigits are the digits in TBCD-STRING, of which the index is the value.
+// 0xf is the filler.
+const tbcdDigits = "0123456789*#abc"
+
+// EncodeTBCD encodes s in TBCD-STRING, with the filler if the number of the
+// digits is odd. The digits "a", "b" and "c" are accepted in either case.
+func EncodeTBCD(s string) ([]byte, error) {
+	b := make([]byte, (len(s)+1)/2)
+	for i := 0; i < len(s); i++ {
+		v := indexDigit(s[i])
+		if v < 0 {
+			return nil, &InvalidValueError{Reason: fmt.Sprintf("invalid digit: %q", s[i])}
+		}
+		if i%2 == 0 {
+			b[i/2] = 0xf0 | byte(v)
+		} else {
+			b[i/2] = b[i/2]&0x0f | byte(v)<<4
+		}
+	}
+	return b, nil
+}
+
+func indexDigit(c byte) int {
+	if 'A' <= c && c <= 'C' {
+		c += 'a' - 'A'
+	}
+	for i := 0; i < len(tbcdDigits); i++ {
+		if tbcdDigits[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// DecodeTBCD decodes b in TBCD-STRING, which ends at the first filler.
+func DecodeTBCD(b []byte) string {
+	s := make([]byte, 0, len(b)*2)
+	for _, x := range b {
+		for _, v := range []byte{x & 0x0f, x >> 4} {
+			if v == 0x0f {
+				return string(s)
+			}
+			s = append(s, tbcdDigits[v])
+		}
+	}
+	return string(s)
+}
+
+// decimal reports whether s consists only of the decimal digits.
+func decimal(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IMSI is the International Mobile Subscriber Identity of up to 15 digits,
+// encoded in TBCD-STRING.
+type IMSI string
+
+// Encode returns the contents octets of an IMSI.
+func (i IMSI) Encode() ([]byte, error) {
+	if len(i) < 5 || len(i) > 15 || !decimal(string(i)) {
+		return nil, &InvalidValueError{Reason: fmt.Sprintf("invalid IMSI: %q", string(i))}
+	}
+	return EncodeTBCD(string(i))
+}
+
+// Decode sets the values retrieved from the contents octets in an IMSI.
+func (i *IMSI) Decode(b []byte) error {
+	if len(b) < 3 || len(b) > 8 {
+		return &InvalidValueError{Reason: fmt.Sprintf("invalid length of IMSI: %d", len(b))}
+	}
+	*i = IMSI(DecodeTBCD(b))
+	return nil
+}
+
+// MarshalBinary returns the byte sequence generated from an IMSI as an OCTET STRING.
+func (i IMSI) MarshalBinary() ([]byte, error) {
+	return octetString(i.Encode())
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence of an OCTET
+// STRING in an IMSI.
+func (i *IMSI) UnmarshalBinary(b []byte) error {
+	v, err := readOctetString(b)
+	if err != nil {
+		return err
+	}
+	return i.Decode(v)
+}
+
+// IMEI is the International Mobile Equipment Identity of 15 digits, or 16
+// digits with the spare digit or the software version, encoded in TBCD-STRING
+// of 8 octets.
+type IMEI string
+
+// Encode returns the contents octets of an IMEI.
+func (i IMEI) Encode() ([]byte, error) {
+	if (len(i) != 15 && len(i) != 16) || !decimal(string(i)) {
+		return nil, &InvalidValueError{Reason: fmt.Sprintf("invalid IMEI: %q", string(i))}
+	}
+	return EncodeTBCD(string(i))
+}
+
+// Decode sets the values retrieved from the contents octets in an IMEI.
+func (i *IMEI) Decode(b []byte) error {
+	if len(b) != 8 {
+		return &InvalidValueError{Reason: fmt.Sprintf("invalid length of IMEI: %d", len(b))}
+	}
+	*i = IMEI(DecodeTBCD(b))
+	return nil
+}
+
+// MarshalBinary returns the byte sequence generated from an IMEI as an OCTET STRING.
+func (i IMEI) MarshalBinary() ([]byte, error) {
+	return octetString(i.Encode())
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence of an OCTET
+// STRING in an IMEI.
+func (i *IMEI) UnmarshalBinary(b []byte) error {
+	v, err := readOctetString(b)
+	if err != nil {
+		return err
+	}
+	return i.Decode(v)
+}
@@ -0,0 +1,222 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Routing Indicator definitions.
+const (
+	RouteOnGT  uint8 = 0
+	RouteOnSSN uint8 = 1
+)
+
+// Address is an SCCP Calling/Called Party Address of ITU-T Q.713.
+type Address struct {
+	RoutingIndicator     uint8
+	GlobalTitleIndicator uint8
+
+	HasPointCode bool
+	PointCode    uint16
+	// SSN is 0 if not present.
+	SSN uint8
+
+	// The fields of the Global Title, which are present or not depending on
+	// GlobalTitleIndicator.
+	TranslationType uint8
+	NumberingPlan   uint8
+	EncodingScheme  uint8
+	NatureOfAddress uint8
+	Digits          string
+}
+
+// ParseAddress parses given byte sequence as an SCCP Address.
+func ParseAddress(b []byte) (*Address, error) {
+	if len(b) < 1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	a := &Address{
+		RoutingIndicator:     (b[0] >> 6) & 0x01,
+		GlobalTitleIndicator: (b[0] >> 2) & 0x0f,
+	}
+	hasPC, hasSSN := b[0]&0x01 != 0, b[0]&0x02 != 0
+	b = b[1:]
+
+	if hasPC {
+		if len(b) < 2 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a.HasPointCode = true
+		a.PointCode = binary.LittleEndian.Uint16(b[0:2]) & 0x3fff
+		b = b[2:]
+	}
+	if hasSSN {
+		if len(b) < 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a.SSN = b[0]
+		b = b[1:]
+	}
+
+	// The length of the fields before the address signals.
+	var n int
+	odd := false
+	switch a.GlobalTitleIndicator {
+	case 0:
+		return a, nil
+	case 1:
+		if len(b) < 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		odd, a.NatureOfAddress = b[0]&0x80 != 0, b[0]&0x7f
+		n = 1
+	case 2:
+		if len(b) < 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a.TranslationType = b[0]
+		n = 1
+	case 3, 4:
+		n = 2
+		if a.GlobalTitleIndicator == 4 {
+			n = 3
+		}
+		if len(b) < n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a.TranslationType = b[0]
+		a.NumberingPlan, a.EncodingScheme = b[1]>>4, b[1]&0x0f
+		odd = a.EncodingScheme == 1
+		if n == 3 {
+			a.NatureOfAddress = b[2] & 0x7f
+		}
+	default:
+		// Reserved ones, of which the format is unknown.
+		return a, nil
+	}
+
+	a.Digits = decodeBCD(b[n:], odd)
+	return a, nil
+}
+
+// MarshalBinary returns the byte sequence generated from an Address.
+//
+// EncodingScheme and the odd/even indicator of GTI 1 are determined by the
+// number of Digits if EncodingScheme is 0.
+func (a *Address) MarshalBinary() ([]byte, error) {
+	if a.GlobalTitleIndicator > 4 {
+		return nil, &InvalidAddressError{Reason: fmt.Sprintf("unsupported GTI: %d", a.GlobalTitleIndicator)}
+	}
+	signals, err := encodeBCD(a.Digits)
+	if err != nil {
+		return nil, err
+	}
+
+	ind := (a.RoutingIndicator&0x01)<<6 | a.GlobalTitleIndicator<<2
+	if a.SSN != 0 {
+		ind |= 0x02
+	}
+	if a.HasPointCode {
+		ind |= 0x01
+	}
+
+	b := []byte{ind}
+	if a.HasPointCode {
+		b = append(b, byte(a.PointCode), byte(a.PointCode>>8)&0x3f)
+	}
+	if a.SSN != 0 {
+		b = append(b, a.SSN)
+	}
+
+	odd := len(a.Digits)%2 != 0
+	es := a.EncodingScheme
+	if es == 0 {
+		es = 2
+		if odd {
+			es = 1
+		}
+	}
+	switch a.GlobalTitleIndicator {
+	case 0:
+		return b, nil
+	case 1:
+		nai := a.NatureOfAddress & 0x7f
+		if odd {
+			nai |= 0x80
+		}
+		b = append(b, nai)
+	case 2:
+		b = append(b, a.TranslationType)
+	case 3:
+		b = append(b, a.TranslationType, a.NumberingPlan<<4|es&0x0f)
+	case 4:
+		b = append(b, a.TranslationType, a.NumberingPlan<<4|es&0x0f, a.NatureOfAddress&0x7f)
+	}
+	return append(b, signals...), nil
+}
+
+// encodeBCD encodes the address signals in BCD, with the filler 0 if the number
+// of the digits is odd.
+func encodeBCD(digits string) ([]byte, error) {
+	b := make([]byte, (len(digits)+1)/2)
+	for i := 0; i < len(digits); i++ {
+		v, err := strconv.ParseUint(digits[i:i+1], 16, 8)
+		if err != nil {
+			return nil, &InvalidAddressError{Reason: fmt.Sprintf("invalid digit: %q", digits[i])}
+		}
+		b[i/2] |= byte(v) << (4 * uint(i%2))
+	}
+	return b, nil
+}
+
+// decodeBCD decodes the address signals in BCD, which has the first digit in
+// the lower nibble. The last upper nibble is a filler if odd is true.
+func decodeBCD(b []byte, odd bool) string {
+	const digits = "0123456789abcdef"
+
+	s := make([]byte, 0, len(b)*2)
+	for _, x := range b {
+		s = append(s, digits[x&0x0f], digits[x>>4])
+	}
+	if odd && len(s) != 0 {
+		s = s[:len(s)-1]
+	}
+	return string(s)
+}
+
+// String returns the Address in a human readable form, e.g. "gt=819012345678 ssn=6".
+func (a *Address) String() string {
+	if a == nil {
+		return ""
+	}
+
+	var fields []string
+	if a.GlobalTitleIndicator != 0 {
+		fields = append(fields, "gt="+a.Digits)
+	}
+	if a.SSN != 0 {
+		fields = append(fields, "ssn="+strconv.Itoa(int(a.SSN)))
+	}
+	if a.HasPointCode {
+		fields = append(fields, "pc="+strconv.Itoa(int(a.PointCode)))
+	}
+	return strings.Join(fields, " ")
+}
+
+// InvalidAddressError indicates that an Address cannot be encoded.
+type InvalidAddressError struct {
+	Reason string
+}
+
+// Error returns error message with violating content.
+func (e *InvalidAddressError) Error() string {
+	return "pcap: got invalid address: " + e.Reason
+}
@@ -0,0 +1,425 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+// Message is a TCAP message found in a packet, with the information of the
+// lower layers.
+type Message struct {
+	// Packet is the number of the packet the message is found in.
+	Packet    int
+	Timestamp time.Time
+
+	// SrcIP and DstIP are nil with LinkTypeSCTP.
+	SrcIP, DstIP     net.IP
+	SrcPort, DstPort uint16
+	StreamID         uint16
+
+	// OPC and DPC are the point codes in M3UA Protocol Data.
+	OPC, DPC uint32
+	SLS      uint8
+
+	CallingParty *Address
+	CalledParty  *Address
+
+	// Data is the byte sequence of the TCAP message.
+	Data []byte
+	// TCAP is the parsed Data, which is nil if ParseErr is not nil.
+	TCAP     *tcap.TCAP
+	ParseErr error
+}
+
+// Protocol identifiers of the layers.
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88a8
+
+	ipProtoSCTP = 132
+
+	sctpChunkData = 0
+	ppidM3UA      = 3
+
+	m3uaClassTransfer = 1
+	m3uaTypeData      = 1
+	m3uaProtocolData  = 0x0210
+
+	siSCCP = 3
+
+	exportedPDUEndOfOptions  = 0
+	exportedPDUDissectorName = 12
+	// the name of the dissector for TCAP in Wireshark.
+	exportedPDUDissectorTCAP = "tcap"
+)
+
+// Messages decodes the layers in the packet and returns the TCAP messages in it.
+//
+// It returns nil if the packet does not have any, e.g. the packet is not the
+// one of SIGTRAN or the layers are malformed. The error is returned only when
+// the link type is not supported.
+func (p *Packet) Messages() ([]*Message, error) {
+	m := &Message{Packet: p.Number, Timestamp: p.Timestamp}
+
+	var sctp []byte
+	switch p.LinkType {
+	case LinkTypeExportedPDU:
+		if m.decodeExportedPDU(p.Data) {
+			return []*Message{m}, nil
+		}
+		return nil, nil
+	case LinkTypeSCTP:
+		sctp = p.Data
+	case LinkTypeNull:
+		if len(p.Data) < 4 {
+			return nil, nil
+		}
+		// The address family in the host byte order of the captured machine.
+		family := binary.LittleEndian.Uint32(p.Data[0:4])
+		if family > 0xffff {
+			family = binary.BigEndian.Uint32(p.Data[0:4])
+		}
+		switch family {
+		case 2:
+			sctp = m.decodeIPv4(p.Data[4:])
+		case 10, 24, 28, 30:
+			sctp = m.decodeIPv6(p.Data[4:])
+		}
+	default:
+		ethType, payload, err := decodeLinkLayer(p.LinkType, p.Data)
+		if err != nil {
+			return nil, err
+		}
+		switch ethType {
+		case etherTypeIPv4:
+			sctp = m.decodeIPv4(payload)
+		case etherTypeIPv6:
+			sctp = m.decodeIPv6(payload)
+		}
+	}
+	if sctp == nil {
+		return nil, nil
+	}
+
+	return m.decodeSCTP(sctp), nil
+}
+
+// decodeLinkLayer returns the EtherType and the payload of the link layer.
+func decodeLinkLayer(linkType LinkType, b []byte) (uint16, []byte, error) {
+	switch linkType {
+	case LinkTypeEthernet:
+		if len(b) < 14 {
+			return 0, nil, nil
+		}
+		ethType, b := binary.BigEndian.Uint16(b[12:14]), b[14:]
+		for (ethType == etherTypeVLAN || ethType == etherTypeQinQ) && len(b) >= 4 {
+			ethType, b = binary.BigEndian.Uint16(b[2:4]), b[4:]
+		}
+		return ethType, b, nil
+	case LinkTypeLinuxSLL:
+		if len(b) < 16 {
+			return 0, nil, nil
+		}
+		return binary.BigEndian.Uint16(b[14:16]), b[16:], nil
+	case LinkTypeLinuxSLL2:
+		if len(b) < 20 {
+			return 0, nil, nil
+		}
+		return binary.BigEndian.Uint16(b[0:2]), b[20:], nil
+	case LinkTypeRaw:
+		if len(b) == 0 {
+			return 0, nil, nil
+		}
+		if b[0]>>4 == 6 {
+			return etherTypeIPv6, b, nil
+		}
+		return etherTypeIPv4, b, nil
+	case LinkTypeIPv4:
+		return etherTypeIPv4, b, nil
+	case LinkTypeIPv6:
+		return etherTypeIPv6, b, nil
+	}
+	return 0, nil, &UnsupportedLinkTypeError{LinkType: linkType}
+}
+
+// decodeExportedPDU decodes the exported PDU, and reports whether it has a
+// TCAP message.
+func (m *Message) decodeExportedPDU(b []byte) bool {
+	tcapPDU := false
+	for {
+		if len(b) < 4 {
+			return false
+		}
+		tag, l := binary.BigEndian.Uint16(b[0:2]), int(binary.BigEndian.Uint16(b[2:4]))
+		if len(b) < 4+l {
+			return false
+		}
+
+		switch tag {
+		case exportedPDUEndOfOptions:
+			if !tcapPDU {
+				return false
+			}
+			m.setData(b[4+l:])
+			return true
+		case exportedPDUDissectorName:
+			// The name may be padded with zeros.
+			tcapPDU = string(bytes.TrimRight(b[4:4+l], "\x00")) == exportedPDUDissectorTCAP
+		}
+		b = b[4+l:]
+	}
+}
+
+// decodeIPv4 returns the SCTP packet in the IPv4 packet, or nil if it is not
+// the one of SCTP or a fragment.
+func (m *Message) decodeIPv4(b []byte) []byte {
+	if len(b) < 20 || b[0]>>4 != 4 {
+		return nil
+	}
+	ihl := int(b[0]&0x0f) * 4
+	total := int(binary.BigEndian.Uint16(b[2:4]))
+	if ihl < 20 || total < ihl || len(b) < ihl {
+		return nil
+	}
+	// MF flag or Fragment Offset.
+	if binary.BigEndian.Uint16(b[6:8])&0x3fff != 0 {
+		return nil
+	}
+	if b[9] != ipProtoSCTP {
+		return nil
+	}
+
+	m.SrcIP, m.DstIP = net.IP(b[12:16]), net.IP(b[16:20])
+	if total < len(b) {
+		// without the padding of the link layer.
+		b = b[:total]
+	}
+	return b[ihl:]
+}
+
+// decodeIPv6 returns the SCTP packet in the IPv6 packet, or nil if it is not
+// the one of SCTP or a fragment.
+func (m *Message) decodeIPv6(b []byte) []byte {
+	if len(b) < 40 || b[0]>>4 != 6 {
+		return nil
+	}
+	if l := 40 + int(binary.BigEndian.Uint16(b[4:6])); l < len(b) {
+		b = b[:l]
+	}
+	m.SrcIP, m.DstIP = net.IP(b[8:24]), net.IP(b[24:40])
+
+	next, payload := b[6], b[40:]
+	for {
+		switch next {
+		case ipProtoSCTP:
+			return payload
+		case 0, 43, 60: // Hop-by-Hop, Routing and Destination Options.
+			if len(payload) < 8 {
+				return nil
+			}
+			l := (int(payload[1]) + 1) * 8
+			if len(payload) < l {
+				return nil
+			}
+			next, payload = payload[0], payload[l:]
+		default: // including Fragment.
+			return nil
+		}
+	}
+}
+
+// decodeSCTP returns the TCAP messages in the DATA chunks of the SCTP packet.
+func (m *Message) decodeSCTP(b []byte) []*Message {
+	if len(b) < 12 {
+		return nil
+	}
+	m.SrcPort, m.DstPort = binary.BigEndian.Uint16(b[0:2]), binary.BigEndian.Uint16(b[2:4])
+
+	var msgs []*Message
+	for chunks := b[12:]; len(chunks) >= 4; {
+		typ, flags := chunks[0], chunks[1]
+		l := int(binary.BigEndian.Uint16(chunks[2:4]))
+		if l < 4 || len(chunks) < l {
+			break
+		}
+
+		// Only the unfragmented ones, with both B and E bits.
+		if typ == sctpChunkData && flags&0x03 == 0x03 && l >= 16 &&
+			binary.BigEndian.Uint32(chunks[12:16]) == ppidM3UA {
+			c := *m
+			c.StreamID = binary.BigEndian.Uint16(chunks[8:10])
+			if c.decodeM3UA(chunks[16:l]) {
+				msgs = append(msgs, &c)
+			}
+		}
+
+		padded := (l + 3) / 4 * 4
+		if padded > len(chunks) {
+			break
+		}
+		chunks = chunks[padded:]
+	}
+	return msgs
+}
+
+// decodeM3UA decodes the M3UA DATA, and reports whether it has a TCAP message.
+func (m *Message) decodeM3UA(b []byte) bool {
+	if len(b) < 8 || b[0] != 1 || b[2] != m3uaClassTransfer || b[3] != m3uaTypeData {
+		return false
+	}
+	if l := int(binary.BigEndian.Uint32(b[4:8])); l < len(b) {
+		b = b[:l]
+	}
+
+	for params := b[8:]; len(params) >= 4; {
+		tag, l := binary.BigEndian.Uint16(params[0:2]), int(binary.BigEndian.Uint16(params[2:4]))
+		if l < 4 || len(params) < l {
+			return false
+		}
+
+		if tag == m3uaProtocolData {
+			pd := params[4:l]
+			if len(pd) < 12 || pd[8] != siSCCP {
+				return false
+			}
+			m.OPC, m.DPC = binary.BigEndian.Uint32(pd[0:4]), binary.BigEndian.Uint32(pd[4:8])
+			m.SLS = pd[11]
+			return m.decodeSCCP(pd[12:])
+		}
+
+		padded := (l + 3) / 4 * 4
+		if padded > len(params) {
+			return false
+		}
+		params = params[padded:]
+	}
+	return false
+}
+
+// decodeSCCP decodes the SCCP UDT, XUDT or LUDT, and reports whether it has
+// a TCAP message.
+func (m *Message) decodeSCCP(b []byte) bool {
+	called, calling, data, ok := decodeSCCPPointers(b)
+	if !ok || len(data) == 0 {
+		return false
+	}
+
+	var err error
+	if m.CalledParty, err = ParseAddress(called); err != nil {
+		return false
+	}
+	if m.CallingParty, err = ParseAddress(calling); err != nil {
+		return false
+	}
+
+	m.setData(data)
+	return true
+}
+
+// setData sets the TCAP message and the parsed one.
+func (m *Message) setData(data []byte) {
+	// The capacity is limited not to let the parser read the following bytes.
+	m.Data = data[:len(data):len(data)]
+	var err error
+	if m.TCAP, err = tcap.Parse(m.Data); err != nil {
+		m.ParseErr = &ParseError{Reason: err}
+	}
+}
+
+// SCCP message types that carry the user data.
+const (
+	sccpUDT  = 0x09
+	sccpXUDT = 0x11
+	sccpLUDT = 0x13
+)
+
+// decodeSCCPPointers returns the variable parts of the SCCP message, located by
+// the pointers. XUDT with Segmentation is not supported.
+func decodeSCCPPointers(b []byte) (called, calling, data []byte, ok bool) {
+	if len(b) < 2 {
+		return nil, nil, nil, false
+	}
+
+	// The offset of the pointers, and the size of the pointers and the length
+	// indicators of the parts.
+	var offset, size int
+	switch b[0] {
+	case sccpUDT:
+		offset, size = 2, 1
+	case sccpXUDT:
+		offset, size = 3, 1
+		if seg, err := hasSegmentation(b); seg || err != nil {
+			return nil, nil, nil, false
+		}
+	case sccpLUDT:
+		offset, size = 3, 2
+	default:
+		return nil, nil, nil, false
+	}
+
+	parts := make([][]byte, 3)
+	for i := range parts {
+		p := offset + i*size
+		if len(b) < p+size {
+			return nil, nil, nil, false
+		}
+
+		// The pointer is relative to itself, and the last part (Data) has the
+		// length indicator in the same size as the pointers in LUDT.
+		ptr, lsize := int(b[p]), 1
+		if size == 2 {
+			ptr = int(binary.LittleEndian.Uint16(b[p : p+2]))
+			if i == 2 {
+				lsize = 2
+			}
+		}
+		start := p + ptr
+		if ptr == 0 || len(b) < start+lsize {
+			return nil, nil, nil, false
+		}
+
+		l := int(b[start])
+		if lsize == 2 {
+			l = int(binary.LittleEndian.Uint16(b[start : start+2]))
+		}
+		if len(b) < start+lsize+l {
+			return nil, nil, nil, false
+		}
+		parts[i] = b[start+lsize : start+lsize+l]
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// hasSegmentation reports whether the XUDT has the Segmentation parameter in
+// the optional part. It returns io.ErrUnexpectedEOF if the pointer to the
+// optional part points past the end.
+func hasSegmentation(b []byte) (bool, error) {
+	if len(b) < 7 || b[6] == 0 {
+		return false, nil
+	}
+	start := 6 + int(b[6])
+	if start > len(b) {
+		return false, io.ErrUnexpectedEOF
+	}
+	for opt := b[start:]; len(opt) >= 2 && opt[0] != 0; {
+		if opt[0] == 0x10 {
+			return true, nil
+		}
+		if len(opt) < 2+int(opt[1]) {
+			return false, nil
+		}
+		opt = opt[2+int(opt[1]):]
+	}
+	return false, nil
+}
@@ -0,0 +1,297 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package pcap reads the TCAP messages from the capture files of SIGTRAN traffic.
+
+It reads pcap and pcapng files without libpcap, and decodes Ethernet (or the
+other supported link layers), IPv4/IPv6, SCTP DATA chunks, M3UA DATA and SCCP
+UDT/XUDT/LUDT in each packet to get the TCAP messages with the timestamp of the
+packet and SCCP Calling/Called Party Addresses.
+
+	r, err := pcap.NewReader(f)
+	if err != nil {
+		// ...
+	}
+	for {
+		m, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// ...
+		}
+		fmt.Println(m.Timestamp, m.CallingParty, m.CalledParty, m.TCAP.MessageTypeString())
+	}
+
+The messages split across multiple SCTP DATA chunks, IP fragments or XUDT
+segments are not reassembled, and the packets with them are skipped as well as
+the ones of the other protocols.
+
+Writer writes the TCAP messages to a pcap file that can be opened in Wireshark,
+in the synthetic SCCP UDT, M3UA DATA, SCTP and IP headers, or in the "exported
+PDU" format of Wireshark with LinkTypeExportedPDU.
+
+	w, err := pcap.NewWriter(f, pcap.LinkTypeEthernet)
+	if err != nil {
+		// ...
+	}
+	if err := w.WriteTCAP(tcap.NewBeginInvokeWithDialogue(...)); err != nil {
+		// ...
+	}
+*/
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// LinkType is the type of the link layer header of the packets.
+type LinkType uint16
+
+// LinkType definitions that can be decoded.
+//
+// See https://www.tcpdump.org/linktypes.html for the others.
+const (
+	LinkTypeNull      LinkType = 0
+	LinkTypeEthernet  LinkType = 1
+	LinkTypeRaw       LinkType = 101
+	LinkTypeLinuxSLL  LinkType = 113
+	LinkTypeIPv4      LinkType = 228
+	LinkTypeIPv6      LinkType = 229
+	LinkTypeSCTP      LinkType = 248
+	LinkTypeLinuxSLL2 LinkType = 276
+
+	// LinkTypeExportedPDU is the one of the upper layer PDUs exported from
+	// Wireshark, which has the name of the dissector for the PDU. Only the
+	// ones for TCAP are decoded.
+	LinkTypeExportedPDU LinkType = 252
+)
+
+// Errors returned while reading or writing a capture file.
+var (
+	ErrUnknownFormat = errors.New("pcap: unknown file format")
+	ErrTruncated     = errors.New("pcap: truncated file")
+	ErrNoTCAP        = errors.New("pcap: no TCAP message to write")
+)
+
+// Packet is a packet in a capture file.
+type Packet struct {
+	// Number is the position of the packet in the capture file, starting at 1.
+	Number    int
+	Timestamp time.Time
+	LinkType  LinkType
+	// Data is the captured bytes from the link layer header, which may be
+	// shorter than the original packet.
+	Data []byte
+}
+
+// Reader reads packets and TCAP messages from a pcap or pcapng file.
+type Reader struct {
+	r      *bufio.Reader
+	ng     bool
+	number int
+
+	// for pcap.
+	order    binary.ByteOrder
+	nano     bool
+	linkType LinkType
+	snapLen  uint32
+
+	// for pcapng.
+	interfaces []iface
+
+	// the messages decoded from the last packet and not returned yet.
+	pending []*Message
+}
+
+// NewReader creates a Reader that reads from r, detecting the format from the
+// header of the file.
+func NewReader(r io.Reader) (*Reader, error) {
+	rd := &Reader{r: bufio.NewReader(r)}
+
+	magic, err := rd.r.Peek(4)
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrTruncated
+		}
+		return nil, err
+	}
+
+	switch {
+	case binary.BigEndian.Uint32(magic) == blockTypeSHB:
+		rd.ng = true
+		if err := rd.readSHB(); err != nil {
+			return nil, err
+		}
+		return rd, nil
+	case binary.LittleEndian.Uint32(magic) == magicMicroseconds:
+		rd.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic) == magicMicroseconds:
+		rd.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(magic) == magicNanoseconds:
+		rd.order, rd.nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(magic) == magicNanoseconds:
+		rd.order, rd.nano = binary.BigEndian, true
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	hdr := make([]byte, 24)
+	if _, err := io.ReadFull(rd.r, hdr); err != nil {
+		return nil, truncated(err)
+	}
+	rd.snapLen = rd.order.Uint32(hdr[16:20])
+	rd.linkType = LinkType(rd.order.Uint32(hdr[20:24]))
+	return rd, nil
+}
+
+const (
+	magicMicroseconds = 0xa1b2c3d4
+	magicNanoseconds  = 0xa1b23c4d
+
+	// maxSnapLen and maxBlockLen are the largest packet and pcapng block
+	// that are read, which are the same as Wireshark, so that the lengths in
+	// a corrupted file do not make it allocate gigabytes.
+	maxSnapLen  = 262144
+	maxBlockLen = 16 * 1024 * 1024
+)
+
+// ReadPacket reads the next packet. It returns io.EOF at the end of the file.
+func (r *Reader) ReadPacket() (*Packet, error) {
+	var p *Packet
+	var err error
+	if r.ng {
+		p, err = r.readNGPacket()
+	} else {
+		p, err = r.readPacket()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.number++
+	p.Number = r.number
+	return p, nil
+}
+
+func (r *Reader) readPacket() (*Packet, error) {
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(r.r, hdr); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, truncated(err)
+	}
+
+	sec, frac := r.order.Uint32(hdr[0:4]), r.order.Uint32(hdr[4:8])
+	if !r.nano {
+		frac *= 1000
+	}
+
+	l := r.order.Uint32(hdr[8:12])
+	if l > r.snapLen && l > maxSnapLen {
+		return nil, fmt.Errorf("pcap: got invalid packet length: %d", l)
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, truncated(err)
+	}
+	return &Packet{
+		Timestamp: time.Unix(int64(sec), int64(frac)),
+		LinkType:  r.linkType,
+		Data:      data,
+	}, nil
+}
+
+// Next returns the next TCAP message, skipping the packets without one. It
+// returns io.EOF at the end of the file.
+//
+// The error from Packet.Messages is returned as it is, and the reading can be
+// continued by calling Next again.
+func (r *Reader) Next() (*Message, error) {
+	for len(r.pending) == 0 {
+		p, err := r.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		if r.pending, err = p.Messages(); err != nil {
+			return nil, err
+		}
+	}
+
+	m := r.pending[0]
+	r.pending = r.pending[1:]
+	return m, nil
+}
+
+// ReadAll reads all the TCAP messages in r.
+func ReadAll(r io.Reader) ([]*Message, error) {
+	rd, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []*Message
+	for {
+		m, err := rd.Next()
+		if err == io.EOF {
+			return msgs, nil
+		}
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+// ReadFile reads all the TCAP messages in the named file.
+func ReadFile(name string) ([]*Message, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadAll(f)
+}
+
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
+
+// UnsupportedLinkTypeError indicates that the link layer of a packet cannot be decoded.
+type UnsupportedLinkTypeError struct {
+	LinkType LinkType
+}
+
+// Error returns error message with violating content.
+func (e *UnsupportedLinkTypeError) Error() string {
+	return fmt.Sprintf("pcap: got unsupported link type: %d", e.LinkType)
+}
+
+// ParseError indicates that the TCAP message in a packet cannot be parsed.
+// Reason is the error returned by the parser.
+type ParseError struct {
+	Reason error
+}
+
+// Error returns error message with violating content.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("pcap: failed to parse TCAP: %v", e.Reason)
+}
+
+// Unwrap returns Reason.
+func (e *ParseError) Unwrap() error {
+	return e.Reason
+}
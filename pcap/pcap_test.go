@@ -0,0 +1,440 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// Begin with Invoke of processUnstructuredSS-Request.
+const beginHex = "6281f248040000000a6b3f283d060700118605010101a032603080020780a109060704000001001302be1f281d060704000001010101a012a01080069121436587f981069121436587f96c81a8a181a502010002013b30819c04010f04818c5474d8bd06e5df7590f92d07d5e769f71944479741c7373bec3e83aad32911342fcbed65b90b94a683d27310b96c2fb3dff0321904afcbcbec3ce8ed069ddfecb0fb0c0abbc9a0341d549f97e7a0e9700865819ab36a90059a0ea95016283875b24043e194053a4e9b3750d84d0625a955d09c1e7693c372f2dc0542bee16550fe5d0795ddea771e4447bbf1800891111111111111f1"
+
+var (
+	// GTI 4, route on GT, SSN 8 (MSC), E.164 819012345678.
+	callingAddr = mustHex("1208001204180921436587")
+	// GTI 4, route on SSN, PC 100, SSN 6 (HLR), E.164 81901234567 (odd).
+	calledAddr = mustHex("53640006001104180921436507")
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func udt(called, calling, data []byte) []byte {
+	b := []byte{sccpUDT, 0x00, 3, byte(3 + len(called)), byte(3 + len(called) + len(calling))}
+	b = append(append(b, byte(len(called))), called...)
+	b = append(append(b, byte(len(calling))), calling...)
+	return append(append(b, byte(len(data))), data...)
+}
+
+func xudt(called, calling, data, optional []byte) []byte {
+	b := []byte{sccpXUDT, 0x00, 0x0f, 4, byte(4 + len(called)), byte(4 + len(called) + len(calling)), 0}
+	if optional != nil {
+		b[6] = byte(4 + len(called) + len(calling) + len(data))
+	}
+	b = append(append(b, byte(len(called))), called...)
+	b = append(append(b, byte(len(calling))), calling...)
+	b = append(append(b, byte(len(data))), data...)
+	return append(b, optional...)
+}
+
+// xudtOptionalPastEnd returns the XUDT with the pointer to the optional part
+// pointing past the end.
+func xudtOptionalPastEnd(data []byte) []byte {
+	b := xudt(calledAddr, callingAddr, data, nil)
+	b[6] = 0xff
+	return b
+}
+
+func ludt(called, calling, data []byte) []byte {
+	b := []byte{sccpLUDT, 0x00, 0x0f}
+	ptrs := []int{8, 7 + len(called), 6 + len(called) + len(calling), 0}
+	for _, p := range ptrs {
+		b = append(b, byte(p), byte(p>>8))
+	}
+	b = append(append(b, byte(len(called))), called...)
+	b = append(append(b, byte(len(calling))), calling...)
+	b = append(b, byte(len(data)), byte(len(data)>>8))
+	return append(b, data...)
+}
+
+func m3uaData(opc, dpc uint32, sccp []byte) []byte {
+	pd := make([]byte, 12, 12+len(sccp))
+	binary.BigEndian.PutUint32(pd[0:4], opc)
+	binary.BigEndian.PutUint32(pd[4:8], dpc)
+	pd[8], pd[9], pd[10], pd[11] = siSCCP, 2, 0, 5
+	pd = append(pd, sccp...)
+
+	param := []byte{0x02, 0x10, byte((4 + len(pd)) >> 8), byte(4 + len(pd))}
+	param = append(param, pd...)
+	for len(param)%4 != 0 {
+		param = append(param, 0)
+	}
+
+	b := []byte{1, 0, m3uaClassTransfer, m3uaTypeData, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[4:8], uint32(8+len(param)))
+	return append(b, param...)
+}
+
+func dataChunk(flags uint8, stream uint16, ppid uint32, data []byte) []byte {
+	b := make([]byte, 16, 16+len(data)+3)
+	b[0], b[1] = sctpChunkData, flags
+	binary.BigEndian.PutUint16(b[2:4], uint16(16+len(data)))
+	binary.BigEndian.PutUint32(b[4:8], 1)
+	binary.BigEndian.PutUint16(b[8:10], stream)
+	binary.BigEndian.PutUint32(b[12:16], ppid)
+	b = append(b, data...)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func sctpPacket(src, dst uint16, chunks ...[]byte) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint16(b[0:2], src)
+	binary.BigEndian.PutUint16(b[2:4], dst)
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	return b
+}
+
+func ipv4Packet(src, dst string, proto uint8, payload []byte) []byte {
+	b := make([]byte, 20)
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:4], uint16(20+len(payload)))
+	b[6] = 0x40 // DF
+	b[8], b[9] = 64, proto
+	copy(b[12:16], net.ParseIP(src).To4())
+	copy(b[16:20], net.ParseIP(dst).To4())
+	return append(b, payload...)
+}
+
+func ipv6Packet(src, dst string, payload []byte) []byte {
+	b := make([]byte, 40)
+	b[0] = 0x60
+	binary.BigEndian.PutUint16(b[4:6], uint16(len(payload)))
+	b[6], b[7] = ipProtoSCTP, 64
+	copy(b[8:24], net.ParseIP(src))
+	copy(b[24:40], net.ParseIP(dst))
+	return append(b, payload...)
+}
+
+func ethernetFrame(vlan bool, payload []byte) []byte {
+	b := mustHex("000000000002000000000001")
+	if vlan {
+		b = append(b, 0x81, 0x00, 0x00, 0x64)
+	}
+	b = append(b, 0x08, 0x00)
+	return append(b, payload...)
+}
+
+func pcapFile(order binary.ByteOrder, linkType LinkType, ts []time.Time, frames ...[]byte) []byte {
+	b := make([]byte, 24)
+	order.PutUint32(b[0:4], magicMicroseconds)
+	order.PutUint16(b[4:6], 2)
+	order.PutUint16(b[6:8], 4)
+	order.PutUint32(b[16:20], 65535)
+	order.PutUint32(b[20:24], uint32(linkType))
+	for i, f := range frames {
+		hdr := make([]byte, 16)
+		order.PutUint32(hdr[0:4], uint32(ts[i].Unix()))
+		order.PutUint32(hdr[4:8], uint32(ts[i].Nanosecond()/1000))
+		order.PutUint32(hdr[8:12], uint32(len(f)))
+		order.PutUint32(hdr[12:16], uint32(len(f)))
+		b = append(append(b, hdr...), f...)
+	}
+	return b
+}
+
+func ngBlock(order binary.ByteOrder, typ uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b := make([]byte, 8, 12+len(body))
+	order.PutUint32(b[0:4], typ)
+	order.PutUint32(b[4:8], uint32(12+len(body)))
+	b = append(b, body...)
+	return append(b, b[4:8]...)
+}
+
+func pcapngFile(order binary.ByteOrder, linkType LinkType, ts []time.Time, frames ...[]byte) []byte {
+	shb := make([]byte, 16)
+	order.PutUint32(shb[0:4], byteOrderMagic)
+	order.PutUint16(shb[4:6], 1)
+	binary.BigEndian.PutUint64(shb[8:16], 0xffffffffffffffff)
+	b := ngBlock(order, blockTypeSHB, shb)
+
+	idb := make([]byte, 8)
+	order.PutUint16(idb[0:2], uint16(linkType))
+	// if_tsresol = 9 (nanoseconds), then opt_endofopt.
+	opt := make([]byte, 4)
+	order.PutUint16(opt[0:2], optionTSResol)
+	order.PutUint16(opt[2:4], 1)
+	idb = append(append(idb, opt...), 9, 0, 0, 0, 0, 0, 0, 0)
+	b = append(b, ngBlock(order, blockTypeIDB, idb)...)
+
+	for i, f := range frames {
+		epb := make([]byte, 20)
+		ns := uint64(ts[i].UnixNano())
+		order.PutUint32(epb[4:8], uint32(ns>>32))
+		order.PutUint32(epb[8:12], uint32(ns))
+		order.PutUint32(epb[12:16], uint32(len(f)))
+		order.PutUint32(epb[16:20], uint32(len(f)))
+		b = append(b, ngBlock(order, blockTypeEPB, append(epb, f...))...)
+	}
+	return b
+}
+
+func TestReadAll(t *testing.T) {
+	begin := mustHex(beginHex)
+	ts := []time.Time{
+		time.Date(2020, 4, 1, 12, 0, 0, 123456000, time.UTC),
+		time.Date(2020, 4, 1, 12, 0, 1, 0, time.UTC),
+		time.Date(2020, 4, 1, 12, 0, 2, 0, time.UTC),
+	}
+
+	sigtran := ethernetFrame(true, ipv4Packet("192.0.2.1", "192.0.2.2", ipProtoSCTP, sctpPacket(2905, 2906,
+		// SACK, DATA of M3UA, and DATA of the other protocol.
+		[]byte{0x03, 0x00, 0x00, 0x10, 0, 0, 0, 1, 0, 0, 0xff, 0xff, 0, 0, 0, 0},
+		dataChunk(0x03, 1, ppidM3UA, m3uaData(200, 100, udt(calledAddr, callingAddr, begin))),
+		dataChunk(0x03, 1, 46, []byte{0x01, 0x02}),
+	)))
+	udp := ethernetFrame(false, ipv4Packet("192.0.2.1", "192.0.2.2", 17, make([]byte, 8)))
+	fragmented := ethernetFrame(false, ipv4Packet("192.0.2.1", "192.0.2.2", ipProtoSCTP, sctpPacket(2905, 2906,
+		dataChunk(0x02, 1, ppidM3UA, m3uaData(200, 100, udt(calledAddr, callingAddr, begin))),
+	)))
+
+	for _, c := range []struct {
+		name string
+		file []byte
+	}{
+		{"pcap little endian", pcapFile(binary.LittleEndian, LinkTypeEthernet, ts, udp, sigtran, fragmented)},
+		{"pcap big endian", pcapFile(binary.BigEndian, LinkTypeEthernet, ts, udp, sigtran, fragmented)},
+		{"pcapng little endian", pcapngFile(binary.LittleEndian, LinkTypeEthernet, ts, udp, sigtran, fragmented)},
+		{"pcapng big endian", pcapngFile(binary.BigEndian, LinkTypeEthernet, ts, udp, sigtran, fragmented)},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			msgs, err := ReadAll(bytes.NewReader(c.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("got %d messages, want 1", len(msgs))
+			}
+
+			m := msgs[0]
+			if m.Packet != 2 {
+				t.Errorf("got packet %d, want 2", m.Packet)
+			}
+			if !m.Timestamp.Equal(ts[1]) {
+				t.Errorf("got timestamp %v, want %v", m.Timestamp, ts[1])
+			}
+			if m.SrcIP.String() != "192.0.2.1" || m.DstIP.String() != "192.0.2.2" || m.SrcPort != 2905 || m.DstPort != 2906 {
+				t.Errorf("got %v:%d -> %v:%d", m.SrcIP, m.SrcPort, m.DstIP, m.DstPort)
+			}
+			if m.StreamID != 1 || m.OPC != 200 || m.DPC != 100 || m.SLS != 5 {
+				t.Errorf("got stream %d, OPC %d, DPC %d, SLS %d", m.StreamID, m.OPC, m.DPC, m.SLS)
+			}
+			if got, want := m.CallingParty.String(), "gt=819012345678 ssn=8"; got != want {
+				t.Errorf("got calling party %s, want %s", got, want)
+			}
+			if got, want := m.CalledParty.String(), "gt=81901234567 ssn=6 pc=100"; got != want {
+				t.Errorf("got called party %s, want %s", got, want)
+			}
+			if !bytes.Equal(m.Data, begin) {
+				t.Errorf("got %x, want %x", m.Data, begin)
+			}
+			if m.ParseErr != nil {
+				t.Fatal(m.ParseErr)
+			}
+			if m.TCAP.OTID() != 0x0a {
+				t.Errorf("got OTID %x, want %x", m.TCAP.OTID(), 0x0a)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	begin := mustHex(beginHex)
+	m3ua := func(sccp []byte) []byte {
+		return sctpPacket(2905, 2905, dataChunk(0x03, 3, ppidM3UA, m3uaData(1, 2, sccp)))
+	}
+	// SCCP Segmentation in the optional part.
+	segmentation := []byte{0x10, 0x04, 0x80, 0x00, 0x00, 0x01, 0x00}
+
+	for _, c := range []struct {
+		name     string
+		packet   *Packet
+		want     int
+		wantData []byte
+	}{
+		{
+			"raw IPv6 and XUDT",
+			&Packet{LinkType: LinkTypeRaw, Data: ipv6Packet("2001:db8::1", "2001:db8::2", m3ua(xudt(calledAddr, callingAddr, begin, nil)))},
+			1, begin,
+		}, {
+			"IPv4 and LUDT",
+			&Packet{LinkType: LinkTypeIPv4, Data: ipv4Packet("192.0.2.1", "192.0.2.2", ipProtoSCTP, m3ua(ludt(calledAddr, callingAddr, begin)))},
+			1, begin,
+		}, {
+			"Linux SLL",
+			&Packet{LinkType: LinkTypeLinuxSLL, Data: append(mustHex("0000000100060000000000010000"+"0800"),
+				ipv4Packet("192.0.2.1", "192.0.2.2", ipProtoSCTP, m3ua(udt(calledAddr, callingAddr, begin)))...)},
+			1, begin,
+		}, {
+			"SCTP",
+			&Packet{LinkType: LinkTypeSCTP, Data: m3ua(udt(calledAddr, callingAddr, begin))},
+			1, begin,
+		}, {
+			"segmented XUDT",
+			&Packet{LinkType: LinkTypeSCTP, Data: m3ua(xudt(calledAddr, callingAddr, begin[:100], segmentation))},
+			0, nil,
+		}, {
+			"XUDT with the optional part past the end",
+			&Packet{LinkType: LinkTypeSCTP, Data: m3ua(xudtOptionalPastEnd(begin[:100]))},
+			0, nil,
+		}, {
+			"truncated UDT",
+			&Packet{LinkType: LinkTypeSCTP, Data: m3ua(udt(calledAddr, callingAddr, begin)[:40])},
+			0, nil,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			msgs, err := c.packet.Messages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(msgs) != c.want {
+				t.Fatalf("got %d messages, want %d", len(msgs), c.want)
+			}
+			if c.want != 0 && !bytes.Equal(msgs[0].Data, c.wantData) {
+				t.Errorf("got %x, want %x", msgs[0].Data, c.wantData)
+			}
+		})
+	}
+
+	// A malformed TCAP is returned with ParseErr.
+	p := &Packet{LinkType: LinkTypeSCTP, Data: m3ua(udt(calledAddr, callingAddr, []byte{0x62, 0x05, 0x48, 0x01, 0x00}))}
+	msgs, err := p.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0].ParseErr == nil || msgs[0].TCAP != nil {
+		t.Errorf("got %+v, want a message with ParseErr", msgs)
+	}
+
+	p = &Packet{LinkType: 147, Data: []byte{0x00}}
+	var lerr *UnsupportedLinkTypeError
+	if _, err := p.Messages(); !errors.As(err, &lerr) {
+		t.Errorf("got %v, want UnsupportedLinkTypeError", err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	a, err := ParseAddress(calledAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Address{
+		RoutingIndicator:     RouteOnSSN,
+		GlobalTitleIndicator: 4,
+		HasPointCode:         true,
+		PointCode:            100,
+		SSN:                  6,
+		NumberingPlan:        1,
+		EncodingScheme:       1,
+		NatureOfAddress:      4,
+		Digits:               "81901234567",
+	}
+	if *a != *want {
+		t.Errorf("got %+v, want %+v", a, want)
+	}
+
+	// GTI 1 with odd number of digits, without SSN.
+	a, err = ParseAddress(mustHex("0484214305"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.NatureOfAddress != 4 || a.Digits != "12345" || a.SSN != 0 {
+		t.Errorf("got %+v", a)
+	}
+
+	for _, b := range []string{"", "43", "4364", "1200"} {
+		if _, err := ParseAddress(mustHex(b)); err == nil {
+			t.Errorf("expected error with %s", b)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("not a capture file"))); err != ErrUnknownFormat {
+		t.Errorf("got %v, want %v", err, ErrUnknownFormat)
+	}
+
+	file := pcapFile(binary.LittleEndian, LinkTypeSCTP, []time.Time{time.Unix(0, 0)}, []byte{0x01, 0x02})
+	r, err := NewReader(bytes.NewReader(file[:len(file)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadPacket(); err != ErrTruncated {
+		t.Errorf("got %v, want %v", err, ErrTruncated)
+	}
+
+	r, err = NewReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Number != 1 || p.LinkType != LinkTypeSCTP || !bytes.Equal(p.Data, []byte{0x01, 0x02}) {
+		t.Errorf("got %+v", p)
+	}
+	if _, err := r.ReadPacket(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReadInvalidLength(t *testing.T) {
+	ts := []time.Time{time.Unix(0, 0)}
+	for _, c := range []struct {
+		name string
+		file []byte
+		// the position of the 32-bit length or the octet to overwrite.
+		at    int
+		value uint32
+	}{
+		{"pcap packet longer than the snap length", pcapFile(binary.LittleEndian, LinkTypeSCTP, ts, []byte{0x01}), 24 + 8, 0xffffffff},
+		{"pcapng block longer than the maximum", pcapngFile(binary.LittleEndian, LinkTypeSCTP, ts, []byte{0x01}), 60 + 4, 0xfffffffc},
+		{"pcapng timestamp resolution 2^64", pcapngFile(binary.LittleEndian, LinkTypeSCTP, ts, []byte{0x01}), 48, 0xc0},
+		{"pcapng timestamp resolution 10^20", pcapngFile(binary.LittleEndian, LinkTypeSCTP, ts, []byte{0x01}), 48, 20},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if c.value > 0xff {
+				binary.LittleEndian.PutUint32(c.file[c.at:], c.value)
+			} else {
+				c.file[c.at] = uint8(c.value)
+			}
+
+			r, err := NewReader(bytes.NewReader(c.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.ReadPacket(); err == nil || err == ErrTruncated {
+				t.Errorf("got %v", err)
+			}
+		})
+	}
+}
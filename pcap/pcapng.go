@@ -0,0 +1,197 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Block types in pcapng.
+const (
+	blockTypeIDB = 0x00000001
+	blockTypeSPB = 0x00000003
+	blockTypeEPB = 0x00000006
+	blockTypeSHB = 0x0a0d0d0a
+
+	byteOrderMagic = 0x1a2b3c4d
+	optionTSResol  = 9
+)
+
+// iface is an interface described by an Interface Description Block.
+type iface struct {
+	linkType LinkType
+	// units is the number of the timestamp units in a second.
+	units uint64
+}
+
+// readBlock reads a block and returns the type and the body.
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	hdr := make([]byte, 8)
+	if _, err := io.ReadFull(r.r, hdr); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, truncated(err)
+	}
+
+	typ := r.order.Uint32(hdr[0:4])
+	l := r.order.Uint32(hdr[4:8])
+	if l < 12 || l%4 != 0 || l > maxBlockLen {
+		return 0, nil, fmt.Errorf("pcap: got invalid block length: %d", l)
+	}
+	body := make([]byte, l-8)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return 0, nil, truncated(err)
+	}
+	// without the trailing Block Total Length.
+	return typ, body[:len(body)-4], nil
+}
+
+// readSHB reads a Section Header Block, which starts a new section.
+func (r *Reader) readSHB() error {
+	hdr := make([]byte, 12)
+	if _, err := io.ReadFull(r.r, hdr); err != nil {
+		return truncated(err)
+	}
+
+	switch {
+	case binary.BigEndian.Uint32(hdr[8:12]) == byteOrderMagic:
+		r.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(hdr[8:12]) == byteOrderMagic:
+		r.order = binary.LittleEndian
+	default:
+		return ErrUnknownFormat
+	}
+
+	l := r.order.Uint32(hdr[4:8])
+	if l < 28 || l%4 != 0 {
+		return fmt.Errorf("pcap: got invalid block length: %d", l)
+	}
+	if _, err := io.CopyN(ioutil.Discard, r.r, int64(l-12)); err != nil {
+		return truncated(err)
+	}
+
+	r.interfaces = nil
+	return nil
+}
+
+// readNGPacket reads the blocks until a packet is found.
+func (r *Reader) readNGPacket() (*Packet, error) {
+	for {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			return nil, io.EOF
+		}
+		// The byte order may change in the new section.
+		if b, err := r.r.Peek(4); err == nil && binary.BigEndian.Uint32(b) == blockTypeSHB {
+			if err := r.readSHB(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		typ, body, err := r.readBlock()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case blockTypeIDB:
+			if err := r.addInterface(body); err != nil {
+				return nil, err
+			}
+		case blockTypeEPB:
+			if len(body) < 20 {
+				return nil, ErrTruncated
+			}
+			id := r.order.Uint32(body[0:4])
+			if int(id) >= len(r.interfaces) {
+				return nil, fmt.Errorf("pcap: got unknown interface ID: %d", id)
+			}
+			ifc := r.interfaces[id]
+
+			ts := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+			caplen := r.order.Uint32(body[12:16])
+			if int(caplen) > len(body)-20 {
+				return nil, ErrTruncated
+			}
+			return &Packet{
+				Timestamp: ifc.timestamp(ts),
+				LinkType:  ifc.linkType,
+				Data:      body[20 : 20+caplen],
+			}, nil
+		case blockTypeSPB:
+			if len(r.interfaces) == 0 {
+				return nil, fmt.Errorf("pcap: got unknown interface ID: %d", 0)
+			}
+			if len(body) < 4 {
+				return nil, ErrTruncated
+			}
+
+			// The captured length is the minimum of the original length and
+			// the snap length, which is not kept here.
+			data := body[4:]
+			if l := r.order.Uint32(body[0:4]); int(l) < len(data) {
+				data = data[:l]
+			}
+			return &Packet{
+				LinkType: r.interfaces[0].linkType,
+				Data:     data,
+			}, nil
+		}
+	}
+}
+
+// addInterface adds the interface described by an Interface Description Block.
+func (r *Reader) addInterface(body []byte) error {
+	if len(body) < 8 {
+		return ErrTruncated
+	}
+
+	ifc := iface{
+		linkType: LinkType(r.order.Uint16(body[0:2])),
+		units:    1000000,
+	}
+	for opts := body[8:]; len(opts) >= 4; {
+		code, l := r.order.Uint16(opts[0:2]), int(r.order.Uint16(opts[2:4]))
+		if code == 0 || len(opts) < 4+l {
+			break
+		}
+
+		if code == optionTSResol && l == 1 {
+			// The units in a second must fit in uint64, which are up to
+			// 10^19 or 2^63.
+			v := opts[4]
+			switch {
+			case v&0x80 == 0 && v <= 19:
+				ifc.units = uint64(math.Pow10(int(v)))
+			case v&0x80 != 0 && v&0x7f <= 63:
+				ifc.units = 1 << (v & 0x7f)
+			default:
+				return fmt.Errorf("pcap: got unsupported timestamp resolution: %#x", v)
+			}
+		}
+		if n := 4 + (l+3)/4*4; n < len(opts) {
+			opts = opts[n:]
+		} else {
+			break
+		}
+	}
+
+	r.interfaces = append(r.interfaces, ifc)
+	return nil
+}
+
+func (ifc iface) timestamp(ts uint64) time.Time {
+	sec, frac := ts/ifc.units, ts%ifc.units
+	hi, lo := bits.Mul64(frac, uint64(time.Second))
+	nsec, _ := bits.Div64(hi, lo, ifc.units)
+	return time.Unix(int64(sec), int64(nsec))
+}
@@ -0,0 +1,314 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+// The values used in the synthetic lower layers when not given in Message.
+var (
+	DefaultSrcIP = net.IPv4(192, 0, 2, 1)
+	DefaultDstIP = net.IPv4(192, 0, 2, 2)
+)
+
+// The values used in the synthetic lower layers when not given in Message.
+const (
+	DefaultPort = 2905
+	DefaultOPC  = 1
+	DefaultDPC  = 2
+)
+
+// Writer writes the packets and TCAP messages to a pcap file.
+type Writer struct {
+	w        io.Writer
+	linkType LinkType
+	// the TSN of the next DATA chunk.
+	tsn uint32
+}
+
+// NewWriter creates a Writer that writes to w with the given link type, and
+// writes the file header.
+//
+// The link type should be LinkTypeEthernet, LinkTypeRaw, LinkTypeIPv4,
+// LinkTypeIPv6 or LinkTypeSCTP to write the TCAP messages with the synthetic
+// lower layers, or LinkTypeExportedPDU to write them without, which Wireshark
+// decodes as TCAP directly. Any link type can be used with WritePacket.
+func NewWriter(w io.Writer, linkType LinkType) (*Writer, error) {
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], magicMicroseconds)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2)
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], 0xffff)
+	binary.LittleEndian.PutUint32(hdr[20:24], uint32(linkType))
+	if _, err := w.Write(hdr); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, linkType: linkType, tsn: 1}, nil
+}
+
+// WritePacket writes a packet. Number and LinkType in p are ignored.
+func (w *Writer) WritePacket(p *Packet) error {
+	ts := p.Timestamp
+	hdr := make([]byte, 16)
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(ts.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(hdr[8:12], uint32(len(p.Data)))
+	binary.LittleEndian.PutUint32(hdr[12:16], uint32(len(p.Data)))
+	if _, err := w.w.Write(hdr); err != nil {
+		return err
+	}
+	_, err := w.w.Write(p.Data)
+	return err
+}
+
+// WriteMessage writes a TCAP message in a packet.
+//
+// The TCAP message is Data in m, or TCAP in m encoded if Data is nil. The other
+// fields in m are used in the lower layers, and the defaults are used for the
+// ones not set. Packet, SLS and StreamID can be zero, and Timestamp is the
+// current time if zero. CallingParty and CalledParty are the Addresses routed
+// on SSN without SSN if nil.
+func (w *Writer) WriteMessage(m *Message) error {
+	data := m.Data
+	if data == nil {
+		if m.TCAP == nil {
+			return ErrNoTCAP
+		}
+
+		var err error
+		if data, err = m.TCAP.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	frame, err := w.frame(m, data)
+	if err != nil {
+		return err
+	}
+	return w.WritePacket(&Packet{Timestamp: ts, Data: frame})
+}
+
+// WriteTCAP writes a TCAP message with the current time and the default values
+// in the lower layers.
+func (w *Writer) WriteTCAP(t *tcap.TCAP) error {
+	return w.WriteMessage(&Message{TCAP: t})
+}
+
+// frame returns the packet from the link layer with data as the TCAP message.
+func (w *Writer) frame(m *Message, data []byte) ([]byte, error) {
+	if w.linkType == LinkTypeExportedPDU {
+		return appendExportedPDU(nil, data), nil
+	}
+
+	sccp, err := appendUDT(nil, m, data)
+	if err != nil {
+		return nil, err
+	}
+	sctp := w.appendSCTP(nil, m, appendM3UA(nil, m, sccp))
+	if w.linkType == LinkTypeSCTP {
+		return sctp, nil
+	}
+
+	src, dst := m.SrcIP, m.DstIP
+	if src == nil {
+		src = DefaultSrcIP
+	}
+	if dst == nil {
+		dst = DefaultDstIP
+	}
+	v4 := src.To4() != nil && dst.To4() != nil
+
+	var b []byte
+	switch w.linkType {
+	case LinkTypeEthernet:
+		b = []byte{0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 1, 0, 0}
+		if v4 {
+			binary.BigEndian.PutUint16(b[12:14], etherTypeIPv4)
+		} else {
+			binary.BigEndian.PutUint16(b[12:14], etherTypeIPv6)
+		}
+	case LinkTypeRaw:
+	case LinkTypeIPv4:
+		if !v4 {
+			return nil, &UnsupportedLinkTypeError{LinkType: w.linkType}
+		}
+	case LinkTypeIPv6:
+		v4 = false
+	default:
+		return nil, &UnsupportedLinkTypeError{LinkType: w.linkType}
+	}
+
+	if v4 {
+		return appendIPv4(b, src.To4(), dst.To4(), sctp), nil
+	}
+	return appendIPv6(b, src.To16(), dst.To16(), sctp), nil
+}
+
+func appendExportedPDU(b, data []byte) []byte {
+	name := make([]byte, (len(exportedPDUDissectorTCAP)+3)/4*4)
+	copy(name, exportedPDUDissectorTCAP)
+
+	b = appendUint16(b, exportedPDUDissectorName, uint16(len(name)))
+	b = append(b, name...)
+	b = appendUint16(b, exportedPDUEndOfOptions, 0)
+	return append(b, data...)
+}
+
+func appendIPv4(b, src, dst, payload []byte) []byte {
+	start := len(b)
+	b = append(b, 0x45, 0, 0, 0, 0, 0, 0x40, 0, 64, ipProtoSCTP, 0, 0)
+	b = append(append(b, src...), dst...)
+	hdr := b[start:]
+	binary.BigEndian.PutUint16(hdr[2:4], uint16(20+len(payload)))
+
+	var sum uint32
+	for i := 0; i < 20; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(hdr[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	binary.BigEndian.PutUint16(hdr[10:12], ^uint16(sum))
+
+	return append(b, payload...)
+}
+
+func appendIPv6(b, src, dst, payload []byte) []byte {
+	b = append(b, 0x60, 0, 0, 0)
+	b = appendUint16(b, uint16(len(payload)))
+	b = append(b, ipProtoSCTP, 64)
+	b = append(append(b, src...), dst...)
+	return append(b, payload...)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// appendSCTP appends an SCTP packet with a DATA chunk of M3UA.
+func (w *Writer) appendSCTP(b []byte, m *Message, m3ua []byte) []byte {
+	srcPort, dstPort := m.SrcPort, m.DstPort
+	if srcPort == 0 {
+		srcPort = DefaultPort
+	}
+	if dstPort == 0 {
+		dstPort = DefaultPort
+	}
+
+	start := len(b)
+	b = appendUint16(b, srcPort, dstPort)
+	// Verification Tag and Checksum.
+	b = append(b, 0, 0, 0, 1, 0, 0, 0, 0)
+
+	// DATA chunk with B and E bits, which is not fragmented.
+	b = append(b, sctpChunkData, 0x03)
+	b = appendUint16(b, uint16(16+len(m3ua)))
+	b = appendUint32(b, w.tsn)
+	b = appendUint16(b, m.StreamID, 0)
+	b = appendUint32(b, ppidM3UA)
+	b = append(b, m3ua...)
+	for (len(b)-start)%4 != 0 {
+		b = append(b, 0)
+	}
+	w.tsn++
+
+	binary.LittleEndian.PutUint32(b[start+8:start+12], crc32.Checksum(b[start:], castagnoli))
+	return b
+}
+
+// appendM3UA appends an M3UA DATA with the Protocol Data of SCCP.
+func appendM3UA(b []byte, m *Message, sccp []byte) []byte {
+	opc, dpc := m.OPC, m.DPC
+	if opc == 0 {
+		opc = DefaultOPC
+	}
+	if dpc == 0 {
+		dpc = DefaultDPC
+	}
+
+	pl := 4 + 12 + len(sccp)
+	padded := (pl + 3) / 4 * 4
+
+	b = append(b, 1, 0, m3uaClassTransfer, m3uaTypeData)
+	b = appendUint32(b, uint32(8+padded))
+	b = appendUint16(b, m3uaProtocolData, uint16(pl))
+	b = appendUint32(b, opc, dpc)
+	// SI, NI (National), MP and SLS.
+	b = append(b, siSCCP, 2, 0, m.SLS)
+	b = append(b, sccp...)
+	for i := pl; i < padded; i++ {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// appendUDT appends an SCCP UDT of Protocol Class 0, or LUDT if data is too
+// long for UDT.
+func appendUDT(b []byte, m *Message, data []byte) ([]byte, error) {
+	var called, calling []byte
+	var err error
+	if called, err = m.CalledParty.address(); err != nil {
+		return nil, err
+	}
+	if calling, err = m.CallingParty.address(); err != nil {
+		return nil, err
+	}
+
+	// The pointers are relative to themselves.
+	if len(data) > 0xff {
+		b = append(b, sccpLUDT, 0x00, 0x0f)
+		b = appendUint16LE(b, 8, uint16(7+len(called)), uint16(6+len(called)+len(calling)), 0)
+	} else {
+		b = append(b, sccpUDT, 0x00, 3, byte(3+len(called)), byte(3+len(called)+len(calling)))
+	}
+	b = append(append(b, byte(len(called))), called...)
+	b = append(append(b, byte(len(calling))), calling...)
+	if len(data) > 0xff {
+		b = appendUint16LE(b, uint16(len(data)))
+	} else {
+		b = append(b, byte(len(data)))
+	}
+	return append(b, data...), nil
+}
+
+// address returns the encoded Address, or the one routed on SSN without SSN if a is nil.
+func (a *Address) address() ([]byte, error) {
+	if a == nil {
+		return []byte{RouteOnSSN << 6}, nil
+	}
+	return a.MarshalBinary()
+}
+
+func appendUint16(b []byte, vs ...uint16) []byte {
+	for _, v := range vs {
+		b = append(b, byte(v>>8), byte(v))
+	}
+	return b
+}
+
+func appendUint16LE(b []byte, vs ...uint16) []byte {
+	for _, v := range vs {
+		b = append(b, byte(v), byte(v>>8))
+	}
+	return b
+}
+
+func appendUint32(b []byte, vs ...uint32) []byte {
+	for _, v := range vs {
+		b = append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return b
+}
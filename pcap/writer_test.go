@@ -0,0 +1,181 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/danievanzyl/go-ya-tcap"
+)
+
+func TestWriteMessage(t *testing.T) {
+	begin := mustHex(beginHex)
+	calling, err := ParseAddress(callingAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	called, err := ParseAddress(calledAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := []*Message{
+		{
+			Timestamp: time.Date(2020, 4, 1, 12, 0, 0, 123456000, time.UTC),
+			SrcIP:     net.ParseIP("198.51.100.1").To4(), DstIP: net.ParseIP("198.51.100.2").To4(),
+			SrcPort: 2905, DstPort: 2906, StreamID: 3,
+			OPC: 200, DPC: 100, SLS: 7,
+			CallingParty: calling, CalledParty: called,
+			Data: begin,
+		}, {
+			Timestamp: time.Date(2020, 4, 1, 12, 0, 1, 0, time.UTC),
+			SrcIP:     net.ParseIP("2001:db8::1"), DstIP: net.ParseIP("2001:db8::2"),
+			SrcPort: 2906, DstPort: 2905,
+			OPC: 100, DPC: 200,
+			CallingParty: called, CalledParty: calling,
+			TCAP: tcap.NewEndReturnResult(0x0a, 0, 59, true, []byte{0x30, 0x03, 0x04, 0x01, 0x0f}),
+		}, {
+			// LUDT, as it is too long for UDT. It does not have to be a valid TCAP.
+			Timestamp: time.Date(2020, 4, 1, 12, 0, 2, 0, time.UTC),
+			Data:      append([]byte{0x65, 0x82, 0x01, 0x2c}, make([]byte, 300)...),
+		},
+	}
+
+	for _, lt := range []LinkType{LinkTypeEthernet, LinkTypeRaw, LinkTypeIPv6, LinkTypeSCTP, LinkTypeExportedPDU} {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(buf, lt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range msgs {
+			if err := w.WriteMessage(m); err != nil {
+				t.Fatalf("%d: %v", lt, err)
+			}
+		}
+
+		got, err := ReadAll(buf)
+		if err != nil {
+			t.Fatalf("%d: %v", lt, err)
+		}
+		if len(got) != len(msgs) {
+			t.Fatalf("%d: got %d messages, want %d", lt, len(got), len(msgs))
+		}
+
+		for i, want := range msgs {
+			g := got[i]
+			data := want.Data
+			if data == nil {
+				data, _ = want.TCAP.MarshalBinary()
+			}
+			if !bytes.Equal(g.Data, data) {
+				t.Errorf("%d/%d: got %x, want %x", lt, i, g.Data, data)
+			}
+			if g.ParseErr != nil && i != 2 {
+				t.Errorf("%d/%d: %v", lt, i, g.ParseErr)
+			}
+			if !g.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("%d/%d: got timestamp %v, want %v", lt, i, g.Timestamp, want.Timestamp)
+			}
+			if lt == LinkTypeExportedPDU {
+				continue
+			}
+
+			if g.StreamID != want.StreamID || g.SLS != want.SLS {
+				t.Errorf("%d/%d: got stream %d, SLS %d", lt, i, g.StreamID, g.SLS)
+			}
+			if want.OPC != 0 && (g.OPC != want.OPC || g.DPC != want.DPC) {
+				t.Errorf("%d/%d: got OPC %d, DPC %d", lt, i, g.OPC, g.DPC)
+			}
+			if want.SrcPort != 0 && (g.SrcPort != want.SrcPort || g.DstPort != want.DstPort) {
+				t.Errorf("%d/%d: got ports %d -> %d", lt, i, g.SrcPort, g.DstPort)
+			}
+			if want.CallingParty != nil && (*g.CallingParty != *want.CallingParty || *g.CalledParty != *want.CalledParty) {
+				t.Errorf("%d/%d: got %v -> %v", lt, i, g.CallingParty, g.CalledParty)
+			}
+			if lt == LinkTypeSCTP || lt == LinkTypeIPv6 || want.SrcIP == nil {
+				continue
+			}
+			if !g.SrcIP.Equal(want.SrcIP) || !g.DstIP.Equal(want.DstIP) {
+				t.Errorf("%d/%d: got %v -> %v", lt, i, g.SrcIP, g.DstIP)
+			}
+		}
+	}
+}
+
+func TestWriteMessageChecksum(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, LinkTypeIPv4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteTCAP(tcap.NewBeginInvoke(1, 0, 2, []byte{0x04, 0x01, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sum uint32
+	for i := 0; i < 20; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(p.Data[i : i+2]))
+	}
+	if sum = sum>>16 + sum&0xffff; sum != 0xffff {
+		t.Errorf("got IPv4 header checksum sum %x, want ffff", sum)
+	}
+
+	sctp := append([]byte{}, p.Data[20:]...)
+	got := binary.LittleEndian.Uint32(sctp[8:12])
+	copy(sctp[8:12], []byte{0, 0, 0, 0})
+	if want := crc32.Checksum(sctp, crc32.MakeTable(crc32.Castagnoli)); got != want {
+		t.Errorf("got SCTP checksum %x, want %x", got, want)
+	}
+}
+
+func TestWriteExportedPDU(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, LinkTypeExportedPDU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteMessage(&Message{Timestamp: time.Unix(1, 0), Data: []byte{0x64, 0x00}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := mustHex("000c0004" + "74636170" + "00000000" + "6400")
+	if got := buf.Bytes()[24+16:]; !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	if err := w.WriteMessage(&Message{}); err != ErrNoTCAP {
+		t.Errorf("got %v, want %v", err, ErrNoTCAP)
+	}
+}
+
+func TestAddressMarshalBinary(t *testing.T) {
+	for _, b := range [][]byte{callingAddr, calledAddr, mustHex("0484214305"), mustHex("4208")} {
+		a, err := ParseAddress(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, b) {
+			t.Errorf("got %x, want %x", got, b)
+		}
+	}
+
+	if _, err := (&Address{GlobalTitleIndicator: 4, Digits: "12x"}).MarshalBinary(); err == nil {
+		t.Error("expected error with invalid digits")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import "bytes"
+
+// unknownAt records the position of an element with the tag not known, as the
+// known field it followed when parsed. The field is nil for the elements at the
+// beginning.
+type unknownAt map[*IE]*IE
+
+// add appends ie to unknown, remembering that it followed prev.
+func (u *unknownAt) add(unknown []*IE, ie, prev *IE) []*IE {
+	if *u == nil {
+		*u = unknownAt{}
+	}
+	(*u)[ie] = prev
+	return append(unknown, ie)
+}
+
+// unknownWriter appends the unknown elements at the positions where they were
+// parsed. The ones without the position, e.g. added to Unknown later, and the
+// ones whose preceding field has been removed or replaced are put at the end.
+type unknownWriter struct {
+	unknown []*IE
+	at      unknownAt
+	done    []bool
+}
+
+func newUnknownWriter(unknown []*IE, at unknownAt) *unknownWriter {
+	if len(unknown) == 0 {
+		return nil
+	}
+	return &unknownWriter{unknown: unknown, at: at, done: make([]bool, len(unknown))}
+}
+
+// appendAfter appends the unknown elements that followed field, or the ones at
+// the beginning if field is nil.
+func (w *unknownWriter) appendAfter(b []byte, field *IE) []byte {
+	if w == nil {
+		return b
+	}
+	for i, ie := range w.unknown {
+		if prev, ok := w.at[ie]; ok && !w.done[i] && prev == field {
+			b = w.append(b, i)
+		}
+	}
+	return b
+}
+
+// skip marks the unknown elements that followed the fields as appended.
+func (w *unknownWriter) skip(fields ...*IE) {
+	if w == nil {
+		return
+	}
+	for i, ie := range w.unknown {
+		prev, ok := w.at[ie]
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			if prev == field {
+				w.done[i] = true
+			}
+		}
+	}
+}
+
+// appendRest appends the unknown elements that have not been appended yet.
+func (w *unknownWriter) appendRest(b []byte) []byte {
+	if w == nil {
+		return b
+	}
+	for i := range w.unknown {
+		if !w.done[i] {
+			b = w.append(b, i)
+		}
+	}
+	return b
+}
+
+func (w *unknownWriter) append(b []byte, i int) []byte {
+	w.done[i] = true
+	b, _ = w.unknown[i].AppendBinary(b)
+	return b
+}
+
+// appendFields appends the fields given in order skipping the nil ones, each
+// followed by the unknown elements that followed it.
+func (w *unknownWriter) appendFields(b []byte, fields ...*IE) ([]byte, error) {
+	var err error
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		if b, err = field.AppendBinary(b); err != nil {
+			return nil, err
+		}
+		b = w.appendAfter(b, field)
+	}
+	return b, nil
+}
+
+// unknownLen returns the serial length of the unknown elements.
+func unknownLen(unknown []*IE) int {
+	l := 0
+	for _, ie := range unknown {
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// original is the byte sequence that a portion was parsed from with
+// WithOriginalBytes, kept when it is different from what the portion is
+// encoded into, e.g. with the long form of the length that could be short.
+type original struct {
+	raw []byte
+	// encoded is what the portion was encoded into right after parsed, which
+	// tells whether it has been modified since.
+	encoded []byte
+}
+
+// newOriginal returns the original of the portion parsed from raw, or nil if
+// it is encoded into raw as it is.
+func newOriginal(raw []byte, appendFn func([]byte) ([]byte, error)) (*original, error) {
+	encoded, err := appendFn(nil)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(raw, encoded) {
+		return nil, nil
+	}
+	return &original{raw: append([]byte(nil), raw...), encoded: encoded}, nil
+}
+
+// replace replaces the encoded portion in b[start:] with the original byte
+// sequence if the portion has not been modified since parsed.
+func (o *original) replace(b []byte, start int) []byte {
+	if o == nil || !bytes.Equal(b[start:], o.encoded) {
+		return b
+	}
+	return append(b[:start], o.raw...)
+}
+
+// marshalLen returns the serial length of the portion encoded by appendFn, or l
+// as it is if the portion has no original.
+func (o *original) marshalLen(l int, appendFn func([]byte) ([]byte, error)) int {
+	if o == nil {
+		return l
+	}
+	b, err := appendFn(nil)
+	if err != nil {
+		return l
+	}
+	return len(b)
+}
@@ -0,0 +1,121 @@
+package tcap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnknownElements(t *testing.T) {
+	// Begin with unknown elements before and after the OTID, after the
+	// Component Portion and after the Parameter of the first Invoke.
+	b := mustHexString("622e8301ff4804000000018401016c1da10e0201010201023003800105840107a10b0201020201033003800106850109")
+
+	tc, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tc.Transaction.Unknown); got != 3 {
+		t.Errorf("got %d unknown elements in Transaction, want 3", got)
+	}
+	if got := len(tc.Components.Component[0].Unknown); got != 1 {
+		t.Errorf("got %d unknown elements in Component, want 1", got)
+	}
+
+	got, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b) {
+		t.Errorf("got %x, want %x", got, b)
+	}
+	if l := tc.MarshalLen(); l != len(b) {
+		t.Errorf("MarshalLen: got %d, want %d", l, len(b))
+	}
+
+	// The ones added later are put at the end.
+	tc.Transaction.Unknown = append(tc.Transaction.Unknown, NewIE(NewContextSpecificPrimitiveTag(6), []byte{0x01}))
+	got, err = tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHexString("62318301ff4804000000018401016c1da10e0201010201023003800105840107a10b0201020201033003800106850109860101")
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	if l := tc.MarshalLen(); l != len(want) {
+		t.Errorf("MarshalLen: got %d, want %d", l, len(want))
+	}
+}
+
+func TestUnknownElementsBER(t *testing.T) {
+	// Continue with unknown elements before the OTID and after the Component
+	// Portion, and an Invoke with the Linked ID.
+	b := mustHexString("65258301ff480400000001490400000002" + "6c10a10e02010280010102013b3003040105" + "8f02abcd")
+
+	ts, err := ParseBER(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := ts[0]
+	if got := len(tc.Transaction.Unknown); got != 2 {
+		t.Errorf("got %d unknown elements in Transaction, want 2", got)
+	}
+	if tc.Components.Component[0].LinkedID == nil {
+		t.Error("got no LinkedID")
+	}
+
+	got, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b) {
+		t.Errorf("got %x, want %x", got, b)
+	}
+}
+
+func TestWithOriginalBytes(t *testing.T) {
+	// The Transaction and the first Invoke have the long form of the length.
+	b := mustHexString("6281234804000000016c1ba1810b0201010201023003800105a10b0201020201033003800106")
+
+	tc, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHexString("62224804000000016c1aa10b0201010201023003800105a10b0201020201033003800106")
+	if !bytes.Equal(got, want) {
+		t.Errorf("without WithOriginalBytes: got %x, want %x", got, want)
+	}
+
+	tc, err = Parse(b, WithOriginalBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b) {
+		t.Errorf("got %x, want %x", got, b)
+	}
+	if l := tc.MarshalLen(); l != len(b) {
+		t.Errorf("MarshalLen: got %d, want %d", l, len(b))
+	}
+
+	// Only the modified Component and the ones containing it are re-encoded.
+	tc.Components.Component[1].OperationCode = NewOperationCode(4, true)
+	got, err = tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = mustHexString("62234804000000016c1ba1810b0201010201023003800105a10b0201020201043003800106")
+	if !bytes.Equal(got, want) {
+		t.Errorf("modified: got %x, want %x", got, want)
+	}
+	if l := tc.MarshalLen(); l != len(want) {
+		t.Errorf("MarshalLen: got %d, want %d", l, len(want))
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// A result too large for a message is sent in ReturnResultNotLast Components,
+// possibly across several Continues, followed by a ReturnResultLast, each with
+// a part of the elements in the Parameter of the whole result. The segments are
+// reassembled by concatenating the contents of their Parameters in order, and a
+// result is segmented by splitting the contents of the Parameter at the borders
+// of the elements in it.
+
+// Reassembler collects the ReturnResultNotLast Components per dialogue and
+// Invoke ID, and reassembles them into the ReturnResultLast that ends them.
+//
+// The dialogue is any key that the caller tells the dialogues apart with, e.g.
+// the octets of the local Transaction ID, which AddTCAP uses as Transaction IDs
+// can be 1 to 4 octets long. Reassembler is not safe for concurrent use.
+type Reassembler struct {
+	pending map[segmentKey][]*Component
+}
+
+type segmentKey struct {
+	dialogue string
+	invokeID int
+}
+
+// NewReassembler creates a new Reassembler.
+func NewReassembler() *Reassembler {
+	return &Reassembler{pending: map[segmentKey][]*Component{}}
+}
+
+// Add adds the Component received in the dialogue.
+//
+// It returns nil for ReturnResultNotLast, which is kept until the ReturnResultLast
+// with the same Invoke ID is added. For ReturnResultLast, it returns the result
+// reassembled from the segments, or the Component as it is if there are none.
+// The other types of Component are returned as they are, and ReturnError and
+// Reject discard the segments of the Invoke ID as the result does not follow.
+func (r *Reassembler) Add(dialogue string, c *Component) (*Component, error) {
+	key := segmentKey{dialogue: dialogue, invokeID: invokeID(c)}
+	switch c.Type.Code() {
+	case ReturnResultNotLast:
+		r.pending[key] = append(r.pending[key], c)
+		return nil, nil
+	case ReturnResultLast:
+		segments, ok := r.pending[key]
+		if !ok {
+			return c, nil
+		}
+		delete(r.pending, key)
+		return reassemble(append(segments, c))
+	case ReturnError, Reject:
+		delete(r.pending, key)
+	}
+	return c, nil
+}
+
+// AddTCAP adds the Components in the message received, with the octets of the
+// Destination Transaction ID as the dialogue, and returns the ones that Add
+// returns other than nil. The segments left in the dialogue are discarded when
+// it is ended by End or Abort.
+func (r *Reassembler) AddTCAP(t *TCAP) ([]*Component, error) {
+	var dialogue string
+	if tx := t.Transaction; tx != nil && tx.DestTransactionID != nil {
+		dialogue = string(tx.DestTransactionID.Value)
+	}
+
+	var comps []*Component
+	if t.Components != nil {
+		for _, c := range t.Components.Component {
+			res, err := r.Add(dialogue, c)
+			if err != nil {
+				return nil, err
+			}
+			if res != nil {
+				comps = append(comps, res)
+			}
+		}
+	}
+
+	if tx := t.Transaction; tx != nil {
+		switch tx.Type.Code() {
+		case End, Abort:
+			r.Discard(dialogue)
+		}
+	}
+	return comps, nil
+}
+
+// Discard discards the segments kept in the dialogue, e.g. when it is timed out.
+func (r *Reassembler) Discard(dialogue string) {
+	for key := range r.pending {
+		if key.dialogue == dialogue {
+			delete(r.pending, key)
+		}
+	}
+}
+
+// Len returns the number of the results that have segments kept.
+func (r *Reassembler) Len() int {
+	return len(r.pending)
+}
+
+// reassemble returns the ReturnResultLast with the contents of the Parameters
+// in segments concatenated.
+func reassemble(segments []*Component) (*Component, error) {
+	last := segments[len(segments)-1]
+	res := &Component{
+		Type:         NewContextSpecificConstructorTag(ReturnResultLast),
+		InvokeID:     last.InvokeID,
+		ResultRetres: &IE{Tag: NewUniversalConstructorTag(0x10)},
+	}
+
+	var value []byte
+	for i, c := range segments {
+		if code := c.OperationCode; code != nil {
+			switch {
+			case res.OperationCode == nil:
+				res.OperationCode = code
+			case code.Tag != res.OperationCode.Tag || !bytes.Equal(code.Value, res.OperationCode.Value):
+				return nil, &SegmentError{Reason: fmt.Sprintf("segment %d has a different operation code", i)}
+			}
+		}
+
+		param := c.Parameter
+		if param == nil {
+			continue
+		}
+		if res.Parameter == nil {
+			res.Parameter = &IE{Tag: param.Tag}
+		} else if param.Tag != res.Parameter.Tag {
+			return nil, &SegmentError{Reason: fmt.Sprintf("segment %d has a Parameter with a different tag", i)}
+		}
+		value = append(value, param.Value...)
+	}
+
+	if res.OperationCode == nil {
+		// None of the segments has the result sequence.
+		res.ResultRetres = nil
+	}
+	if res.Parameter != nil {
+		res.Parameter.Value = value
+		res.Parameter.IE, _ = ParseMultiIEs(value)
+	}
+	res.SetLength()
+	return res, nil
+}
+
+// SegmentResult splits the ReturnResultLast c into ReturnResultNotLast Components
+// followed by a ReturnResultLast, each of which is no longer than size octets
+// when encoded. The contents of the Parameter are split at the borders of the
+// elements in it, in the order they are in.
+//
+// To fit the messages of at most max octets, give the size as max minus the
+// length of the message without the Component, and minus the header of the
+// Component Portion, which is 4 octets at most.
+//
+// It returns c as it is if it fits in size, or SegmentError if it cannot be
+// split, e.g. an element in the Parameter is longer than what fits in size.
+func SegmentResult(c *Component, size int) ([]*Component, error) {
+	if c.MarshalLen() <= size {
+		return []*Component{c}, nil
+	}
+	if c.Type.Code() != ReturnResultLast {
+		return nil, &SegmentError{Reason: fmt.Sprintf("cannot split %s", c.ComponentTypeString())}
+	}
+	if c.Parameter == nil {
+		return nil, &SegmentError{Reason: "no Parameter to split"}
+	}
+
+	// The elements can have the tags of any number, e.g. [50].
+	var elems [][]byte
+	for b := c.Parameter.Value; len(b) != 0; {
+		_, n, err := ber.ReadElement(b)
+		if err != nil {
+			return nil, err
+		}
+		elems, b = append(elems, b[:n]), b[n:]
+	}
+
+	var (
+		segments []*Component
+		value    []byte
+	)
+	for i, elem := range elems {
+		if len(value) != 0 && newSegment(c, append(value, elem...)).MarshalLen() > size {
+			segments = append(segments, newSegment(c, value))
+			value = nil
+		}
+		value = append(value, elem...)
+		if newSegment(c, value).MarshalLen() > size {
+			return nil, &SegmentError{Reason: fmt.Sprintf("element %d in the Parameter does not fit in %d octets", i, size)}
+		}
+	}
+
+	last := newSegment(c, value)
+	last.Type = NewContextSpecificConstructorTag(ReturnResultLast)
+	last.SetLength()
+	return append(segments, last), nil
+}
+
+// newSegment returns the ReturnResultNotLast with the Invoke ID and Operation
+// Code of c, and value as the contents of the Parameter.
+func newSegment(c *Component, value []byte) *Component {
+	param := NewIE(c.Parameter.Tag, value)
+	param.IE, _ = ParseMultiIEs(value)
+	seg := &Component{
+		Type:          NewContextSpecificConstructorTag(ReturnResultNotLast),
+		InvokeID:      c.InvokeID,
+		ResultRetres:  &IE{Tag: NewUniversalConstructorTag(0x10)},
+		OperationCode: c.OperationCode,
+		Parameter:     param,
+	}
+	seg.SetLength()
+	return seg
+}
+
+// invokeID returns the value of the Invoke ID of c, or -1 for NULL.
+func invokeID(c *Component) int {
+	id := c.InvokeID
+	if id == nil || id.Tag == NewUniversalPrimitiveTag(5) {
+		return -1
+	}
+	return ber.DecodeInteger(id.Value)
+}
@@ -0,0 +1,196 @@
+package tcap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// sequenceOf returns a SEQUENCE of n OCTET STRINGs of 5 octets each.
+func sequenceOf(n int) []byte {
+	return sequenceOfTag(n, 0x04)
+}
+
+// sequenceOfTag returns the SEQUENCE of n elements with the tag octets given.
+func sequenceOfTag(n int, tag ...byte) []byte {
+	b, start := beginTLV(nil, NewUniversalConstructorTag(0x10))
+	for i := 0; i < n; i++ {
+		b = append(append(b, tag...), 0x05, byte(i), byte(i), byte(i), byte(i), byte(i))
+	}
+	return endTLV(b, start)
+}
+
+func TestSegmentResult(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		param       []byte
+		size        int
+	}{
+		{"short form length", sequenceOf(10), 30},
+		{"longer than 127 octets", sequenceOf(20), 60},
+		{"longer than 255 octets", sequenceOf(40), 100},
+		{"multi-octet tags", sequenceOfTag(10, 0x9f, 0x32), 30},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			res := NewReturnResult(1, 56, true, true, c.param)
+			if got := res.Parameter.MarshalLen(); got != len(c.param) {
+				t.Fatalf("got Parameter of %d octets, want %d", got, len(c.param))
+			}
+			want, err := res.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			segments, err := SegmentResult(res, c.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(segments) < 2 {
+				t.Fatalf("got %d segments, want more than 1", len(segments))
+			}
+			for i, seg := range segments {
+				if l := seg.MarshalLen(); l > c.size {
+					t.Errorf("segment %d: got %d octets, want %d at most", i, l, c.size)
+				}
+				wantType := ReturnResultNotLast
+				if i == len(segments)-1 {
+					wantType = ReturnResultLast
+				}
+				if got := seg.Type.Code(); got != wantType {
+					t.Errorf("segment %d: got type %d, want %d", i, got, wantType)
+				}
+			}
+
+			// The segments sent in Continues followed by End are reassembled,
+			// with the Transaction ID of 2 octets.
+			r := NewReassembler()
+			var msgs []*TCAP
+			for _, seg := range segments[:len(segments)-1] {
+				msgs = append(msgs, NewTCAP(NewContinue(0x10, 0x20, nil), nil, seg))
+			}
+			msgs = append(msgs, NewTCAP(NewEnd(0x20, nil), nil, segments[len(segments)-1]))
+
+			var got []*Component
+			for _, m := range msgs {
+				m.Transaction.DestTransactionID = NewIE(NewApplicationWidePrimitiveTag(9), []byte{0xaa, 0xbb})
+				b, err := m.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				parsed, err := Parse(b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				comps, err := r.AddTCAP(parsed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, comps...)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d components, want 1", len(got))
+			}
+			b, err := got[0].MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("got %x, want %x", b, want)
+			}
+			if n := r.Len(); n != 0 {
+				t.Errorf("got %d results left, want 0", n)
+			}
+
+			// It fits as it is.
+			if segments, err := SegmentResult(res, len(want)); err != nil || len(segments) != 1 || segments[0] != res {
+				t.Errorf("got %v, %v, want the result as it is", segments, err)
+			}
+		})
+	}
+}
+
+func TestReassemblerShortTID(t *testing.T) {
+	// Continue with the DTID of 2 octets.
+	tc, err := Parse(mustHexString("65114801014902aabb6c08a106020101020102"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReassembler()
+	comps, err := r.AddTCAP(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 1 || comps[0] != tc.Components.Component[0] {
+		t.Errorf("got %v, want the Invoke as it is", comps)
+	}
+}
+
+func TestSegmentResultError(t *testing.T) {
+	for _, c := range []struct {
+		description string
+		comp        *Component
+		size        int
+	}{
+		{"element too large", NewReturnResult(1, 56, true, true, sequenceOf(10)), 10},
+		{"Invoke", NewInvoke(1, -1, 56, true, sequenceOf(10)), 30},
+		{"without Parameter", NewReturnResult(1, -1, true, true, nil), 2},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			var serr *SegmentError
+			if _, err := SegmentResult(c.comp, c.size); !errors.As(err, &serr) {
+				t.Errorf("got %v, want SegmentError", err)
+			}
+		})
+	}
+}
+
+func TestReassembler(t *testing.T) {
+	r := NewReassembler()
+
+	// The segments are kept per dialogue and Invoke ID.
+	for _, c := range []struct {
+		dialogue string
+		comp     *Component
+	}{
+		{"\x01", NewReturnResult(1, 56, true, false, sequenceOf(1))},
+		{"\x01", NewReturnResult(2, 56, true, false, sequenceOf(2))},
+		{"\x02", NewReturnResult(1, 56, true, false, sequenceOf(3))},
+	} {
+		if got, err := r.Add(c.dialogue, c.comp); err != nil || got != nil {
+			t.Fatalf("got %v, %v, want nil", got, err)
+		}
+	}
+	if n := r.Len(); n != 3 {
+		t.Errorf("got %d results, want 3", n)
+	}
+
+	// ReturnError discards the segments of the Invoke ID.
+	rerr := NewReturnError(2, 1, true, nil)
+	if got, err := r.Add("\x01", rerr); err != nil || got != rerr {
+		t.Errorf("got %v, %v, want the ReturnError as it is", got, err)
+	}
+
+	got, err := r.Add("\x01", NewReturnResult(1, 56, true, true, sequenceOf(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := append(sequenceOf(1)[2:], sequenceOf(1)[2:]...)
+	if !bytes.Equal(got.Parameter.Value, param) {
+		t.Errorf("got %x, want %x", got.Parameter.Value, param)
+	}
+
+	r.Discard("\x02")
+	if n := r.Len(); n != 0 {
+		t.Errorf("got %d results left, want 0", n)
+	}
+
+	// The operation codes must be the same.
+	if _, err := r.Add("\x01", NewReturnResult(1, 56, true, false, sequenceOf(1))); err != nil {
+		t.Fatal(err)
+	}
+	var serr *SegmentError
+	if _, err := r.Add("\x01", NewReturnResult(1, 57, true, true, sequenceOf(1))); !errors.As(err, &serr) {
+		t.Errorf("got %v, want SegmentError", err)
+	}
+}
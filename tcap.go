@@ -20,194 +20,321 @@ type TCAP struct {
 	Transaction *Transaction
 	Dialogue    *Dialogue
 	Components  *Components
+
+	orig *original
 }
 
-// NewBeginInvoke creates a new TCAP of type Transaction=Begin, Component=Invoke.
-func NewBeginInvoke(otid uint32, invID, opCode int, payload []byte) *TCAP {
+// NewTCAP creates a new TCAP from the Transaction Portion, the Dialogue Portion
+// and the Components given, which can be built with e.g. NewBegin, NewDialogue
+// and NewInvoke respectively. The Dialogue Portion is omitted if dialogue is nil,
+// and the Component Portion is omitted if no Component is given.
+//
+// The Payload of the Transaction Portion should be empty, as the portions are
+// put after it.
+func NewTCAP(tx *Transaction, dialogue *Dialogue, comps ...*Component) *TCAP {
 	t := &TCAP{
-		Transaction: NewBegin(otid, []byte{}),
-		Components:  NewComponents(NewInvoke(invID, -1, opCode, true, payload)),
+		Transaction: tx,
+		Dialogue:    dialogue,
+	}
+	if len(comps) != 0 {
+		t.Components = NewComponents(comps...)
 	}
 	t.SetLength()
 
-	fmt.Println("NewBeginInvoke:len", t.MarshalLen())
 	return t
 }
 
+// NewUnidirectionalInvoke creates a new TCAP of type Transaction=Unidirectional, Component=Invoke.
+func NewUnidirectionalInvoke(invID, opCode int, payload []byte) *TCAP {
+	return NewTCAP(
+		NewUnidirectional([]byte{}),
+		nil,
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
+}
+
+// NewUnidirectionalInvokeWithDialogue creates a new TCAP of type Transaction=Unidirectional, Component=Invoke with
+// the unstructured Dialogue Portion, which has AUDT in the Dialogue with UnidialogueAsID.
+func NewUnidirectionalInvokeWithDialogue(ctx, ctxver uint8, invID, opCode int, payload []byte) *TCAP {
+	return NewTCAP(
+		NewUnidirectional([]byte{}),
+		NewDialogue(UnidialogueAsID, 1, NewAUDT(1, ctx, ctxver), []byte{}),
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
+}
+
+// NewBeginInvoke creates a new TCAP of type Transaction=Begin, Component=Invoke.
+func NewBeginInvoke(otid uint32, invID, opCode int, payload []byte) *TCAP {
+	return NewTCAP(
+		NewBegin(otid, []byte{}),
+		nil,
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
+}
+
 // NewBeginInvokeWithDialogue creates a new TCAP of type Transaction=Begin, Component=Invoke with Dialogue Portion.
 func NewBeginInvokeWithDialogue(otid uint32, dlgType, ctx, ctxver uint8, invID, opCode int, payload []byte) *TCAP {
-	t := NewBeginInvoke(otid, invID, opCode, payload)
-	t.Dialogue = NewDialogue(dlgType, 1, NewAARQ(1, ctx, ctxver), []byte{})
-	t.SetLength()
-
-	fmt.Println("NewBeginInvokeWithDialogue:len", t.MarshalLen())
-	return t
+	return NewTCAP(
+		NewBegin(otid, []byte{}),
+		NewDialogue(dlgType, 1, NewAARQ(1, ctx, ctxver), []byte{}),
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
 }
 
 // NewContinueInvoke creates a new TCAP of type Transaction=Continue, Component=Invoke.
 func NewContinueInvoke(otid, dtid uint32, invID, opCode int, payload []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewContinue(otid, dtid, []byte{}),
-		Components:  NewComponents(NewInvoke(invID, -1, opCode, true, payload)),
-	}
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewContinue(otid, dtid, []byte{}),
+		nil,
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
 }
 
+// NewContinueInvokeWithDialogue creates a new TCAP of type Transaction=Continue, Component=Invoke with Dialogue Portion.
 func NewContinueInvokeWithDialogue(otid, dtid uint32, invID, opCode int, dlgType, ctx, ctxver uint8, payload []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewContinue(otid, dtid, []byte{}),
-		Components:  NewComponents(NewInvoke(invID, -1, opCode, true, payload)),
-	}
-	t.Dialogue = NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, 0, 1, 0), []byte{})
-
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewContinue(otid, dtid, []byte{}),
+		NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, 0, 1, 0), []byte{}),
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
 }
 
 // NewEndInvokeWithDialogue create a new TCAP of type Transaction=End, Component=Invoke
 func NewEndInvokeWithDialogue(dtid uint32, invID, opCode int, dlgType, ctx, ctxver uint8, payload []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewEnd(dtid, []byte{}),
-		Components:  NewComponents(NewInvoke(invID, -1, opCode, true, payload)),
-	}
-	t.Dialogue = NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, 0, 1, 0), []byte{})
-
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewEnd(dtid, []byte{}),
+		NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, 0, 1, 0), []byte{}),
+		NewInvoke(invID, -1, opCode, true, payload),
+	)
 }
 
 // NewEndReturnResult creates a new TCAP of type Transaction=End, Component=ReturnResult.
 func NewEndReturnResult(dtid uint32, invID, opCode int, isLast bool, payload []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewEnd(dtid, []byte{}),
-		Components:  NewComponents(NewReturnResult(invID, opCode, true, isLast, payload)),
-	}
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewEnd(dtid, []byte{}),
+		nil,
+		NewReturnResult(invID, opCode, true, isLast, payload),
+	)
 }
 
+// NewEndReturnError creates a new TCAP of type Transaction=End, Component=ReturnError.
 func NewEndReturnError(dtid uint32, invId, errCode int, isLocal bool, param []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewEnd(dtid, []byte{}),
-		Components:  NewComponents(NewReturnError(invId, errCode, isLocal, param)),
-	}
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewEnd(dtid, []byte{}),
+		nil,
+		NewReturnError(invId, errCode, isLocal, param),
+	)
 }
 
+// NewEndReturnErrorWithDialogue creates a new TCAP of type Transaction=End, Component=ReturnError with Dialogue Portion.
 func NewEndReturnErrorWithDialogue(dtid uint32, dlgType, ctx, ctxver uint8, invId, errCode int, isLocal bool, param []byte) *TCAP {
-	t := NewEndReturnError(dtid, invId, errCode, isLocal, param)
-	t.Dialogue = NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, Accepted, DialogueServiceUser, Null), []byte{})
-	t.SetLength()
-	return t
+	return NewTCAP(
+		NewEnd(dtid, []byte{}),
+		NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, Accepted, DialogueServiceUser, Null), []byte{}),
+		NewReturnError(invId, errCode, isLocal, param),
+	)
 }
 
 // NewEndReturnResultWithDialogue creates a new TCAP of type Transaction=End, Component=ReturnResult with Dialogue Portion.
 func NewEndReturnResultWithDialogue(dtid uint32, dlgType, ctx, ctxver uint8, invID, opCode int, isLast bool, payload []byte) *TCAP {
-	t := NewEndReturnResult(dtid, invID, opCode, isLast, payload)
-	t.Dialogue = NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, Accepted, DialogueServiceUser, Null), []byte{})
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewEnd(dtid, []byte{}),
+		NewDialogue(dlgType, 1, NewAARE(1, ctx, ctxver, Accepted, DialogueServiceUser, Null), []byte{}),
+		NewReturnResult(invID, opCode, true, isLast, payload),
+	)
 }
 
 // MarshalBinary returns the byte sequence generated from a TCAP instance.
 func (t *TCAP) MarshalBinary() ([]byte, error) {
-	fmt.Println("tcap:marshalbinary:len", t.MarshalLen())
-	b := make([]byte, t.MarshalLen())
-	if err := t.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return t.AppendBinary(make([]byte, 0, t.MarshalLen()))
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *TCAP) MarshalTo(b []byte) error {
-	offset := 0
-	fmt.Println("offset", offset)
-	if portion := t.Transaction; portion != nil {
-		fmt.Println("tcap:marshalto:Transaction:len", portion.MarshalLen())
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+	return marshalTo(b, t.AppendBinary)
+}
+
+// AppendBinary appends the byte sequence generated from a TCAP instance to b.
+//
+// Unlike MarshalTo, it computes each length only once from the contents, and
+// does not allocate if b has enough capacity. Use it with GetBuffer to encode
+// messages on the hot path:
+//
+//	buf := tcap.GetBuffer()
+//	buf.B, err = t.AppendBinary(buf.B)
+//	// ... write buf.B somewhere ...
+//	tcap.PutBuffer(buf)
+//
+// The Dialogue and Components are put in the Transaction, and the Payload of
+// the portions they are carried in is used only when there is nothing after it.
+//
+// The unknown elements in the Transaction are put where they were parsed, and
+// the message parsed with WithOriginalBytes is put as the original byte sequence
+// as long as it is not modified.
+func (t *TCAP) AppendBinary(b []byte) ([]byte, error) {
+	var err error
+	message := len(b)
+	start := -1
+	var w *unknownWriter
+	if tx := t.Transaction; tx != nil {
+		b, start = beginTLV(b, tx.Type)
+		w = newUnknownWriter(tx.Unknown, tx.unknownAt)
+		if b, err = tx.appendFields(b, w); err != nil {
+			return nil, err
+		}
+		if t.Dialogue == nil && t.Components == nil && len(tx.Unknown) == 0 {
+			b = append(b, tx.Payload...)
 		}
-		offset += portion.MarshalLen()
 	}
 
-	fmt.Printf("bytes after Transaction:\n%x\n", b)
-	fmt.Println("offset", offset)
 	if portion := t.Dialogue; portion != nil {
-		fmt.Println("tcap:marshalto:Dialogue:len", portion.MarshalLen())
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+		if b, err = portion.appendBinary(b, t.Components == nil && w == nil); err != nil {
+			return nil, err
 		}
-		offset += portion.MarshalLen()
+		b = w.appendAfter(b, afterDialogue)
 	}
-	fmt.Printf("bytes after Dialogue:\n%x\n", b)
-	fmt.Println("offset", offset)
 
 	if portion := t.Components; portion != nil {
-		fmt.Println("tcap:marshalto:Components:len", portion.MarshalLen())
-		if err := portion.MarshalTo(b[offset : offset+portion.MarshalLen()]); err != nil {
-			return err
+		if b, err = portion.AppendBinary(b); err != nil {
+			return nil, err
 		}
+		b = w.appendAfter(b, afterComponents)
 	}
-	fmt.Printf("bytes after Components:\n%x\n", b)
-	fmt.Println("offset", offset)
 
-	fmt.Println("tcap:marshalto:total length: ", len(b))
-	return nil
+	if start >= 0 {
+		b = w.appendRest(b)
+		b = endTLV(b, start)
+	}
+	return t.orig.replace(b, message), nil
+}
+
+// ParseOption configures the behavior of Parse and ParseBER.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	validate bool
+	original bool
+}
+
+func newParseConfig(opts []ParseOption) *parseConfig {
+	c := &parseConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithValidation makes Parse and ParseBER validate the parsed messages with
+// Validate, and reject the invalid ones with *ValidationError.
+func WithValidation() ParseOption {
+	return func(c *parseConfig) {
+		c.validate = true
+	}
+}
+
+// WithOriginalBytes makes Parse keep the byte sequence that the message, the
+// Dialogue Portion and each Component are parsed from, and put it as it is when
+// encoding them unless they are modified. It reproduces the message exactly,
+// e.g. with the long form of the lengths that could be short, when forwarding
+// the message after inspecting it.
+//
+// It has no effect on ParseBER.
+func WithOriginalBytes() ParseOption {
+	return func(c *parseConfig) {
+		c.original = true
+	}
 }
 
 // Parse parses given byte sequence as a TCAP.
-func Parse(b []byte) (*TCAP, error) {
+func Parse(b []byte, opts ...ParseOption) (*TCAP, error) {
 	t := &TCAP{}
 	if err := t.UnmarshalBinary(b); err != nil {
 		return nil, err
 	}
+
+	cfg := newParseConfig(opts)
+	if cfg.original {
+		if err := t.keepOriginal(b); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.validate {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	return t, nil
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in a TCAP.
+//
+// The elements other than the Dialogue Portion and the Component Portion in the
+// Transaction are kept in Unknown of the Transaction.
 func (t *TCAP) UnmarshalBinary(b []byte) error {
 	var err error
-	offset := 0
-
-	t.Transaction, err = ParseTransaction(b[offset:])
+	t.Transaction, err = ParseTransaction(b)
 	if err != nil {
 		return err
 	}
-	if len(t.Transaction.Payload) == 0 {
-		return nil
-	}
+	t.Dialogue, t.Components, t.orig = nil, nil, nil
 
-	switch t.Transaction.Payload[0] {
-	case 0x6b:
-		t.Dialogue, err = ParseDialogue(t.Transaction.Payload)
+	tx := t.Transaction
+	prev := tx.lastField()
+	for payload := tx.Payload; len(payload) != 0; {
+		_, _, n, err := readTLV(payload)
 		if err != nil {
 			return err
 		}
-		if len(t.Dialogue.Payload) == 0 {
-			return nil
+
+		switch {
+		case payload[0] == 0x6b && t.Dialogue == nil && t.Components == nil:
+			if t.Dialogue, err = ParseDialogue(payload); err != nil {
+				return err
+			}
+			prev = afterDialogue
+		case payload[0] == 0x6c && t.Components == nil:
+			if t.Components, err = ParseComponents(payload[:n]); err != nil {
+				return err
+			}
+			prev = afterComponents
+		default:
+			ie, err := ParseIE(payload[:n])
+			if err != nil {
+				return err
+			}
+			tx.Unknown = tx.unknownAt.add(tx.Unknown, ie, prev)
 		}
+		payload = payload[n:]
+	}
 
-		t.Components, err = ParseComponents(t.Dialogue.Payload)
-		if err != nil {
-			return err
+	return nil
+}
+
+// keepOriginal keeps the byte sequence b that t is parsed from, and the ones of
+// the Dialogue Portion and the Components in it.
+func (t *TCAP) keepOriginal(b []byte) error {
+	var err error
+	if c := t.Components; c != nil {
+		for _, comp := range c.Component {
+			if comp.orig, err = newOriginal(comp.raw, comp.AppendBinary); err != nil {
+				return err
+			}
 		}
-	case 0x6c:
-		t.Components, err = ParseComponents(t.Transaction.Payload)
-		if err != nil {
+	}
+	if d := t.Dialogue; d != nil {
+		if d.orig, err = newOriginal(d.raw, func(b []byte) ([]byte, error) {
+			return d.appendBinary(b, false)
+		}); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	_, _, n, err := readTLV(b)
+	if err != nil {
+		return err
+	}
+	t.orig, err = newOriginal(b[:n], t.AppendBinary)
+	return err
 }
 
 // ParseBer parses given byte sequence as a TCAP.
@@ -218,7 +345,8 @@ func ParseBer(b []byte) ([]*TCAP, error) {
 }
 
 // ParseBER parses given byte sequence as a TCAP.
-func ParseBER(b []byte) ([]*TCAP, error) {
+func ParseBER(b []byte, opts ...ParseOption) ([]*TCAP, error) {
+	cfg := newParseConfig(opts)
 	parsed, err := ParseAsBER(b)
 	if err != nil {
 		return nil, err
@@ -235,6 +363,9 @@ func ParseBER(b []byte) ([]*TCAP, error) {
 		}
 
 		for _, dx := range tx.IE {
+			if t.Transaction.isUnknown(dx) {
+				continue
+			}
 			switch dx.Tag {
 			case 0x6b:
 				t.Dialogue = &Dialogue{}
@@ -249,25 +380,38 @@ func ParseBER(b []byte) ([]*TCAP, error) {
 			}
 		}
 
+		if cfg.validate {
+			if err := t.Validate(); err != nil {
+				return nil, err
+			}
+		}
 		tcaps[i] = t
 	}
 
 	return tcaps, nil
 }
 
-// MarshalLen returns the serial length of TCAP.
+// MarshalLen returns the serial length of TCAP, in the same way as AppendBinary
+// puts the portions.
 func (t *TCAP) MarshalLen() int {
+	tx := t.Transaction
+	unknown := tx != nil && len(tx.Unknown) != 0
+
 	l := 0
 	if portion := t.Components; portion != nil {
 		l += portion.MarshalLen()
 	}
 	if portion := t.Dialogue; portion != nil {
-		l += portion.MarshalLen()
+		l += portion.marshalLen(t.Components == nil && tx == nil)
 	}
-	if portion := t.Transaction; portion != nil {
-		l += portion.MarshalLen()
+	if tx != nil {
+		l += tx.fieldsLen() + unknownLen(tx.Unknown)
+		if t.Dialogue == nil && t.Components == nil && !unknown {
+			l += len(tx.Payload)
+		}
+		l += 1 + lengthLen(l)
 	}
-	return l
+	return t.orig.marshalLen(l, t.AppendBinary)
 }
 
 // SetLength sets the length in Length field.
@@ -352,6 +496,35 @@ func (t *TCAP) AppContextNameOid() string {
 	return ""
 }
 
+// AssociateResult returns Result of AARE in Dialogue Portion, or false if not present.
+func (t *TCAP) AssociateResult() (uint8, bool) {
+	if d := t.Dialogue; d != nil {
+		return d.AssociateResult()
+	}
+
+	return 0, false
+}
+
+// SourceDiagnostic returns Result Source Diagnostic of AARE in Dialogue Portion,
+// or false if not present.
+func (t *TCAP) SourceDiagnostic() (*Diagnostic, bool) {
+	if d := t.Dialogue; d != nil {
+		return d.SourceDiagnostic()
+	}
+
+	return nil, false
+}
+
+// AbortSourceCode returns Abort Source of ABRT in Dialogue Portion, or false if
+// not present.
+func (t *TCAP) AbortSourceCode() (int, bool) {
+	if d := t.Dialogue; d != nil {
+		return d.AbortSourceCode()
+	}
+
+	return 0, false
+}
+
 // ComponentType returns the ComponentType in Component Portion in the list of string.
 //
 // The returned value is of type []string, as it may have multiple Components.
@@ -386,6 +559,7 @@ func (t *TCAP) InvokeID() []uint8 {
 // OpCode returns the OpCode in Component Portion in the list of string.
 //
 // The returned value is of type []string, as it may have multiple Components.
+// It has 0 for the Components that do not have Operation Code.
 func (t *TCAP) OpCode() []uint8 {
 	if c := t.Components; c != nil {
 		var ops []uint8
@@ -402,10 +576,15 @@ func (t *TCAP) OpCode() []uint8 {
 // LayerPayload returns the upper layer as byte slice.
 //
 // The returned value is of type [][]byte, as it may have multiple Components.
+// It has nil for the Components that do not have Parameter.
 func (t *TCAP) LayerPayload() [][]byte {
 	if c := t.Components; c != nil {
 		var ret [][]byte
 		for _, cm := range c.Component {
+			if cm.Parameter == nil {
+				ret = append(ret, nil)
+				continue
+			}
 			ret = append(ret, cm.Parameter.Value)
 		}
 
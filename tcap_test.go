@@ -1,6 +1,7 @@
 package tcap
 
 import (
+	"bytes"
 	"encoding/hex"
 	"log"
 	"math/rand"
@@ -63,6 +64,50 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestNewTCAP(t *testing.T) {
+	tc := NewTCAP(
+		NewContinue(1, 2, nil),
+		nil,
+		NewInvoke(1, -1, 23, true, []byte{0x30, 0x03, 0x80, 0x01, 0x05}),
+		NewReturnResult(2, 3, true, false, []byte{0x30, 0x03, 0x80, 0x01, 0x06}),
+		NewReturnResult(2, 3, true, true, []byte{0x30, 0x03, 0x80, 0x01, 0x07}),
+		NewReturnError(3, 1, true, nil),
+		NewReject(4, 1, 2, nil),
+	)
+
+	got, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := hex.DecodeString("65494804000000014904000000026c3ba10b0201010201173003800105a70d02010230080201033003800106a20d02010230080201033003800107a306020103020101a406020104810102")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	if l := tc.MarshalLen(); l != len(want) {
+		t.Errorf("MarshalLen: got %d, want %d", l, len(want))
+	}
+
+	// The constructors of a single Component are the same as NewTCAP.
+	old, err := NewEndReturnResultWithDialogue(2, DialogueAsID, 0x13, 2, 1, 59, true, []byte{0x30, 0x03, 0x80, 0x01, 0x05}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = NewTCAP(
+		NewEnd(2, nil),
+		NewDialogue(DialogueAsID, 1, NewAARE(1, 0x13, 2, Accepted, DialogueServiceUser, Null), nil),
+		NewReturnResult(1, 59, true, true, []byte{0x30, 0x03, 0x80, 0x01, 0x05}),
+	).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, old) {
+		t.Errorf("got %x, want %x", got, old)
+	}
+}
+
 func GenerateNewEndReturnResultWithDialogue(paramlength int) []byte{
 	tc2 := NewIE(0x04, []byte(RandStringBytes(paramlength)))
 	tc := NewIE(0x04, []byte{0x0f})
@@ -36,9 +36,20 @@ type Transaction struct {
 	OrigTransactionID *IE
 	DestTransactionID *IE
 	PAbortCause       *IE
-	Payload           []byte
+	// Unknown is the elements not known in the Transaction Portion, which are
+	// kept as they are and put where they were parsed.
+	Unknown   []*IE
+	unknownAt unknownAt
+	Payload   []byte
 }
 
+// afterDialogue and afterComponents are the positions of the unknown elements
+// in Transaction that follow the Dialogue Portion and the Component Portion.
+var (
+	afterDialogue   = &IE{}
+	afterComponents = &IE{}
+)
+
 // NewTransaction returns a new Transaction Portion.
 func NewTransaction(mtype int, otid, dtid uint32, cause uint8, payload []byte) *Transaction {
 	t := &Transaction{
@@ -139,122 +150,81 @@ func NewAbort(dtid uint32, cause uint8, payload []byte) *Transaction {
 
 // NewContinueReturnResult creates a new TCAP of type Transaction=Continue, Component=ReturnResult.
 func NewContinueReturnResult(otid, dtid uint32, invID, opCode int, payload []byte) *TCAP {
-	t := &TCAP{
-		Transaction: NewContinue(otid, dtid, []byte{}),
-		Components:  NewComponents(NewReturnResult(invID, opCode, true, true, payload)),
-	}
-	t.SetLength()
-
-	return t
+	return NewTCAP(
+		NewContinue(otid, dtid, []byte{}),
+		nil,
+		NewReturnResult(invID, opCode, true, true, payload),
+	)
 }
 
 // MarshalBinary returns the byte sequence generated from a Transaction instance.
 func (t *Transaction) MarshalBinary() ([]byte, error) {
-	fmt.Println("t.Marshalllen()", t.MarshalLen())
-	b := make([]byte, t.MarshalLen())
-	if err := t.MarshalTo(b); err != nil {
-		return nil, err
-	}
-	return b, nil
+	return t.AppendBinary(make([]byte, 0, t.MarshalLen()))
 }
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *Transaction) MarshalTo(b []byte) error {
-	var offset int = 2
-	b[0] = uint8(t.Type)
-	// if t.Length > 127 {
-	// 	buf := make([]byte, 4)
-	// 	t.Length = t.Length - 1
-	// 	var count int
-	// 	if (int64(t.Length) & int64(-16777216)) > 0 {
-	// 		buf[0] = byte(t.Length >> 24 & 255)
-	// 		buf[1] = byte(t.Length >> 16 & 255)
-	// 		buf[2] = byte(t.Length >> 8 & 255)
-	// 		buf[3] = byte(t.Length & 255)
-	// 		count = 4
-	// 	} else if (int64(t.Length) & 16711680) > 0 {
-	// 		buf[0] = byte(t.Length >> 16 & 255)
-	// 		buf[1] = byte(t.Length >> 8 & 255)
-	// 		buf[2] = byte(t.Length & 255)
-	// 		count = 3
-	//
-	// 	} else if (int64(t.Length) & 65280) > 0 {
-	// 		buf[0] = byte(t.Length >> 8 & 255)
-	// 		buf[1] = byte(t.Length & 255)
-	// 		count = 2
-	// 	} else {
-	// 		buf[0] = byte(t.Length & 255)
-	// 		count = 1
-	// 	}
-	//
-	// 	b[offset-1] = byte(128 | count)
-	// 	for i := 0; i < count; i++ {
-	// 		b[offset+i] = buf[i]
-	// 	}
-	// 	offset = offset + count
-	//
-	// } else {
-	// 	b[1] = t.Length
-	// 	offset = 2
-	// }
-
-	// b[1] = t.Length
-	// offset = 2
-	//
-	//
-	//
-
-	fmt.Printf("%+v\n", t)
-	offset = writeLength(b, t.Length)
-	fmt.Println("transaction:marshalto:len", b[0], b[1], b[2], offset, "marshal:len", t.MarshalLen())
+	return marshalTo(b, t.AppendBinary)
+}
 
+// AppendBinary appends the byte sequence generated from a Transaction instance to b.
+//
+// The length is computed from the contents, not taken from the Length field.
+//
+// The unknown elements that followed the portions in the Payload are not put
+// again if the Payload is not empty.
+func (t *Transaction) AppendBinary(b []byte) ([]byte, error) {
+	b, start := beginTLV(b, t.Type)
+
+	w := newUnknownWriter(t.Unknown, t.unknownAt)
+	b, err := t.appendFields(b, w)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, t.Payload...)
+	if len(t.Payload) != 0 {
+		w.skip(afterDialogue, afterComponents)
+	}
+	b = w.appendRest(b)
+	return endTLV(b, start), nil
+}
+
+// appendFields appends the fields that the message type can have in order, with
+// the unknown elements before and between them.
+func (t *Transaction) appendFields(b []byte, w *unknownWriter) ([]byte, error) {
+	b = w.appendAfter(b, nil)
 	switch t.Type.Code() {
-	case Unidirectional:
-		break
 	case Begin:
-		if field := t.OrigTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		return w.appendFields(b, t.OrigTransactionID)
 	case End:
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		return w.appendFields(b, t.DestTransactionID)
 	case Continue:
-		if field := t.OrigTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
-
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		return w.appendFields(b, t.OrigTransactionID, t.DestTransactionID)
 	case Abort:
-		if field := t.DestTransactionID; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
-		}
+		return w.appendFields(b, t.DestTransactionID, t.PAbortCause)
+	}
+	return b, nil
+}
 
-		if field := t.PAbortCause; field != nil {
-			if err := field.MarshalTo(b[offset : offset+field.MarshalLen()]); err != nil {
-				return err
-			}
-			offset += field.MarshalLen()
+// lastField returns the last of the fields that the message type can have, or
+// nil if it has none.
+func (t *Transaction) lastField() *IE {
+	var fields []*IE
+	switch t.Type.Code() {
+	case Begin:
+		fields = []*IE{t.OrigTransactionID}
+	case End:
+		fields = []*IE{t.DestTransactionID}
+	case Continue:
+		fields = []*IE{t.OrigTransactionID, t.DestTransactionID}
+	case Abort:
+		fields = []*IE{t.DestTransactionID, t.PAbortCause}
+	}
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i] != nil {
+			return fields[i]
 		}
 	}
-	copy(b[offset:t.MarshalLen()], t.Payload)
 	return nil
 }
 
@@ -289,81 +259,110 @@ func ParseTransaction(b []byte) (*Transaction, error) {
 
 // UnmarshalBinary sets the values retrieved from byte sequence in an Transaction.
 func (t *Transaction) UnmarshalBinary(b []byte) error {
-	t.Type = Tag(b[0])
+	tag, v, _, err := readTLV(b)
+	if err != nil {
+		return err
+	}
+	t.Type = tag
+	t.Length = uint8(len(v))
 
-	u, _ := readLength(b)
-	t.Length = u
+	t.Unknown, t.unknownAt = nil, nil
 
-	fmt.Println("transaction:readLength::", u)
-	var err error
-	offset := 2
-	if t.Length > 127 {
-		offset = 3
-	}
+	fields := t.fields()
 
-	switch t.Type.Code() {
-	case Unidirectional:
-		break
-	case Begin:
-		t.OrigTransactionID, err = ParseIE(b[offset : offset+6])
+	// The fields are followed by the Dialogue Portion and the Component
+	// Portion, which are left in the Payload.
+	var prev *IE
+	for len(v) != 0 && v[0] != 0x6b && v[0] != 0x6c {
+		_, _, n, err := readTLV(v)
 		if err != nil {
 			return err
 		}
-		offset += t.OrigTransactionID.MarshalLen()
-	case End:
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
+		ie, err := ParseIE(v[:n])
 		if err != nil {
 			return err
 		}
-		offset += t.DestTransactionID.MarshalLen()
-	case Continue:
-		t.OrigTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
-			return err
-		}
-		offset += t.OrigTransactionID.MarshalLen()
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
-			return err
-		}
-		offset += t.DestTransactionID.MarshalLen()
-	case Abort:
-		t.DestTransactionID, err = ParseIE(b[offset : offset+6])
-		if err != nil {
-			return err
+		v = v[n:]
+
+		if field, ok := fields[ie.Tag]; ok {
+			*field = ie
+			prev = ie
+			continue
 		}
-		offset += t.DestTransactionID.MarshalLen()
-
-		//t.PAbortCause, err = ParseIE(b[offset : ])
-		//if err != nil {
-		//	return err
-		//}
-		//t.PAbortCause.IE, _ = ParseAsBER(t.PAbortCause.Value)
-		//offset += t.PAbortCause.MarshalLen()
+		t.Unknown = t.unknownAt.add(t.Unknown, ie, prev)
 	}
-	t.Payload = b[offset:]
+	t.Payload = v
 	return nil
 }
 
+// fields returns the fields that the message type can have, by their tags.
+func (t *Transaction) fields() map[Tag]**IE {
+	fields := map[Tag]**IE{}
+	switch t.Type.Code() {
+	case Begin:
+		fields[NewApplicationWidePrimitiveTag(8)] = &t.OrigTransactionID
+	case End:
+		fields[NewApplicationWidePrimitiveTag(9)] = &t.DestTransactionID
+	case Continue:
+		fields[NewApplicationWidePrimitiveTag(8)] = &t.OrigTransactionID
+		fields[NewApplicationWidePrimitiveTag(9)] = &t.DestTransactionID
+	case Abort:
+		fields[NewApplicationWidePrimitiveTag(9)] = &t.DestTransactionID
+		fields[NewApplicationWidePrimitiveTag(10)] = &t.PAbortCause
+	}
+	return fields
+}
+
 // SetValsFrom sets the values from IE parsed by ParseBER.
+//
+// The elements other than the fields of the message type, the Dialogue Portion
+// and the Component Portion are kept in Unknown, in the same way as Parse does.
 func (t *Transaction) SetValsFrom(berParsed *IE) error {
 	t.Type = berParsed.Tag
 	t.Length = berParsed.Length
+	t.Unknown, t.unknownAt = nil, nil
+
+	fields := t.fields()
+	var prev *IE
+	dialogue, components := false, false
 	for _, ie := range berParsed.IE {
-		switch ie.Tag {
-		case 0x48:
-			t.OrigTransactionID = ie
-		case 0x49:
-			t.DestTransactionID = ie
-		case 0x4a:
-			t.PAbortCause = ie
+		switch {
+		case ie.Tag == 0x6b && !dialogue && !components:
+			dialogue, prev = true, afterDialogue
+			continue
+		case ie.Tag == 0x6c && !components:
+			components, prev = true, afterComponents
+			continue
 		}
+		if field, ok := fields[ie.Tag]; ok && !dialogue && !components {
+			*field = ie
+			prev = ie
+			continue
+		}
+		t.Unknown = t.unknownAt.add(t.Unknown, ie, prev)
 	}
 	return nil
 }
 
+// isUnknown reports whether ie is kept in Unknown.
+func (t *Transaction) isUnknown(ie *IE) bool {
+	_, ok := t.unknownAt[ie]
+	return ok
+}
+
 // MarshalLen returns the serial length of Transaction.
 func (t *Transaction) MarshalLen() int {
+	l := t.valueLen()
+	return 1 + lengthLen(l) + l
+}
+
+// valueLen returns the length of the contents of Transaction.
+func (t *Transaction) valueLen() int {
+	return t.fieldsLen() + len(t.Payload) + unknownLen(t.Unknown)
+}
+
+// fieldsLen returns the length of the fields that appendFields puts.
+func (t *Transaction) fieldsLen() int {
 	l := 0
 	switch t.Type.Code() {
 	case Unidirectional:
@@ -391,13 +390,7 @@ func (t *Transaction) MarshalLen() int {
 			l += field.MarshalLen()
 		}
 	}
-	l += len(t.Payload)
-	fmt.Println("$$$$$$$$$$4marshalllen", l, t.Length)
-	if t.Length > 127 {
-		return l + 3
-	} else {
-		return l + 2
-	}
+	return l
 }
 
 // SetLength sets the length in Length field.
@@ -411,8 +404,10 @@ func (t *Transaction) SetLength() {
 	if field := t.PAbortCause; field != nil {
 		field.SetLength()
 	}
-	t.Length = uint8(t.MarshalLen() - 2)
-	fmt.Println("SetLength---->", t.Length)
+	for _, ie := range t.Unknown {
+		ie.SetLength()
+	}
+	t.Length = uint8(t.valueLen())
 }
 
 // MessageTypeString returns the name of Message Type in string.
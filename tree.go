@@ -0,0 +1,433 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/danievanzyl/go-ya-tcap/internal/ber"
+)
+
+// Format implements fmt.Formatter.
+//
+// With %+v, TCAP is printed as an indented tree of the labeled fields, like the
+// packet details in Wireshark:
+//
+//	Transaction Capabilities Application Part
+//	    begin
+//	        Source Transaction ID
+//	            otid: 00000002
+//	        dialoguePortion
+//	            oid: 0.0.17.773.1.1.1 (dialogue-as-id)
+//	            dialogueRequest (AARQ)
+//	    ...
+//
+// The Parameter and User Information are printed as the trees of IEs with the
+// class, form and tag of each. The other verbs print the same as String.
+func (t *TCAP) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		w := &treeWriter{w: s, acn: t.applicationContext()}
+		w.tcap(t)
+		return
+	}
+	_, _ = io.WriteString(s, t.String())
+}
+
+// treeWriter writes the lines of a tree with the indentation of the current depth.
+type treeWriter struct {
+	w     io.Writer
+	depth int
+	// acn is the Application Context Name of the message to look up the names
+	// of the operations and errors in DefaultRegistry.
+	acn string
+}
+
+func (w *treeWriter) linef(format string, a ...interface{}) {
+	_, _ = io.WriteString(w.w, strings.Repeat("    ", w.depth))
+	_, _ = fmt.Fprintf(w.w, format, a...)
+	_, _ = io.WriteString(w.w, "\n")
+}
+
+// nest writes the lines by f one level deeper.
+func (w *treeWriter) nest(f func()) {
+	w.depth++
+	f()
+	w.depth--
+}
+
+func (w *treeWriter) tcap(t *TCAP) {
+	w.linef("Transaction Capabilities Application Part")
+	w.nest(func() {
+		tx := t.Transaction
+		if tx == nil {
+			w.dialogue(t.Dialogue)
+			w.components(t.Components)
+			return
+		}
+
+		w.linef("%s", strings.ToLower(enumName(tx.Type.Code(), messageTypeNames)))
+		w.nest(func() {
+			w.transaction(tx)
+			if t.Dialogue == nil && t.Components == nil && len(tx.Payload) != 0 {
+				w.linef("payload: %x", tx.Payload)
+			}
+			w.dialogue(t.Dialogue)
+			w.components(t.Components)
+		})
+	})
+}
+
+func (w *treeWriter) transaction(tx *Transaction) {
+	if otid := tx.OTID(); otid != "" {
+		w.linef("Source Transaction ID")
+		w.nest(func() { w.linef("otid: %s", otid) })
+	}
+	if dtid := tx.DTID(); dtid != "" {
+		w.linef("Destination Transaction ID")
+		w.nest(func() { w.linef("dtid: %s", dtid) })
+	}
+	if c := tx.PAbortCause; c != nil && tx.Type.Code() == Abort && len(c.Value) != 0 {
+		w.linef("p-abortCause: %s (%d)", enumName(int(c.Value[0]), pAbortCauseNames), c.Value[0])
+	}
+	w.unknown(tx.Unknown)
+}
+
+func (w *treeWriter) dialogue(d *Dialogue) {
+	if d == nil {
+		return
+	}
+
+	w.linef("dialoguePortion")
+	w.nest(func() {
+		if oid := d.ObjectIdentifier; oid != nil {
+			s, err := ber.FormatOID(oid.Value)
+			if err != nil {
+				s = hex.EncodeToString(oid.Value)
+			}
+			w.linef("oid: %s%s", s, parenthesize(dialogueOIDNames[s]))
+		}
+		if pdu := d.DialoguePDU; pdu != nil {
+			w.dialoguePDU(pdu, d.isUnidialogue())
+		}
+	})
+}
+
+func (w *treeWriter) dialoguePDU(d *DialoguePDU, uni bool) {
+	switch code := d.Type.Code(); {
+	case uni && code == AARQ:
+		w.linef("unidialoguePDU (AUDT)")
+	case code == AARQ:
+		w.linef("dialogueRequest (AARQ)")
+	case code == AARE:
+		w.linef("dialogueResponse (AARE)")
+	case code == ABRT:
+		w.linef("dialogueAbort (ABRT)")
+	default:
+		w.linef("unknown DialoguePDU (%#x)", uint8(d.Type))
+	}
+
+	w.nest(func() {
+		if pv := d.ProtocolVersion; pv != nil && (d.Type.Code() == AARQ || d.Type.Code() == AARE) {
+			ver := ""
+			if len(pv.Value) != 0 && pv.Value[len(pv.Value)-1]&0x80 != 0 {
+				ver = " (version1)"
+			}
+			w.linef("protocol-version: %x%s", pv.Value, ver)
+		}
+		if d.ApplicationContextName != nil {
+			oid, name, ver, err := d.applicationContext()
+			switch {
+			case err != nil:
+				w.linef("application-context-name: %x", d.ApplicationContextName.Value)
+			case name != "":
+				w.linef("application-context-name: %s (%s-v%d)", oid, name, ver)
+			default:
+				w.linef("application-context-name: %s", oid)
+			}
+		}
+
+		if d.Type.Code() == AARE {
+			if d.Result != nil {
+				if res, err := d.resultCode(); err == nil {
+					w.linef("result: %s (%d)", enumName(res, resultNames), res)
+				} else {
+					w.linef("result: %x", d.Result.Value)
+				}
+			}
+			if d.ResultSourceDiagnostic != nil {
+				if src, reason, err := d.diagnostic(); err == nil {
+					w.linef("result-source-diagnostic: %s: %s (%d)",
+						enumName(src, diagnosticSourceNames), enumName(reason, diagnosticReasonNames[src]), reason,
+					)
+				} else {
+					w.linef("result-source-diagnostic: %x", d.ResultSourceDiagnostic.Value)
+				}
+			}
+		}
+
+		if src := d.AbortSource; src != nil && d.Type.Code() == ABRT {
+			code := ber.DecodeInteger(src.Value)
+			w.linef("abort-source: %s (%d)", enumName(code, abortSourceNames), code)
+		}
+
+		if ui := d.UserInformation; ui != nil {
+			w.linef("user-information")
+			w.nest(func() { w.userInformation(ui.Value) })
+		}
+
+		w.unknown(d.Unknown)
+	})
+}
+
+// unknown writes the elements not known in a portion.
+func (w *treeWriter) unknown(unknown []*IE) {
+	for _, ie := range unknown {
+		w.linef("unknown element (%#x): %x", uint8(ie.Tag), ie.Value)
+	}
+}
+
+// userInformation writes the EXTERNALs in the user information, or the IEs in
+// it if they cannot be parsed as EXTERNAL.
+func (w *treeWriter) userInformation(b []byte) {
+	exts, err := ParseUserInformation(b)
+	if err != nil {
+		w.ies(b)
+		return
+	}
+
+	for _, e := range exts {
+		w.linef("EXTERNAL")
+		w.nest(func() {
+			if e.DirectReference != "" {
+				w.linef("direct-reference: %s", e.DirectReference)
+			}
+			if e.IndirectReference != nil {
+				w.linef("indirect-reference: %d", *e.IndirectReference)
+			}
+			if e.DataValueDescriptor != "" {
+				w.linef("data-value-descriptor: %q", e.DataValueDescriptor)
+			}
+			switch e.Encoding {
+			case SingleASN1Type:
+				w.linef("single-ASN1-type")
+				w.nest(func() { w.ies(e.Data) })
+			case OctetAligned:
+				w.linef("octet-aligned: %x", e.Data)
+			case Arbitrary:
+				w.linef("arbitrary: %x", e.Data)
+			}
+		})
+	}
+}
+
+func (w *treeWriter) components(c *Components) {
+	if c == nil {
+		return
+	}
+
+	w.linef("components: %d item%s", len(c.Component), plural(len(c.Component)))
+	w.nest(func() {
+		for _, comp := range c.Component {
+			w.component(comp)
+		}
+	})
+}
+
+func (w *treeWriter) component(c *Component) {
+	ctype := c.Type.Code()
+	w.linef("Component: %s (%d)", enumName(ctype, componentTypeNames), ctype)
+	w.nest(func() {
+		if id := c.InvokeID; id != nil {
+			if id.Tag == NewUniversalPrimitiveTag(5) {
+				w.linef("invokeID: NULL")
+			} else {
+				w.linef("invokeID: %d", ber.DecodeInteger(id.Value))
+			}
+		}
+
+		switch ctype {
+		case Invoke:
+			if id := c.LinkedID; id != nil {
+				w.linef("linkedID: %d", ber.DecodeInteger(id.Value))
+			}
+			w.opCode(c.OperationCode)
+			w.parameter(c.Parameter)
+		case ReturnResultLast, ReturnResultNotLast:
+			if c.ResultRetres != nil {
+				w.opCode(c.OperationCode)
+				w.parameter(c.Parameter)
+			}
+		case ReturnError:
+			w.errorCode(c.ErrorCode)
+			w.parameter(c.Parameter)
+		case Reject:
+			if p := c.ProblemCode; p != nil {
+				ptype, code := p.Tag.Code(), ber.DecodeInteger(p.Value)
+				w.linef("problem: %s (%d): %s (%d)",
+					enumName(ptype, problemTypeNames), ptype, enumName(code, problemCodeNames[ptype]), code,
+				)
+			}
+		}
+		w.unknown(c.Unknown)
+	})
+}
+
+// opCode writes the Operation Code, with the name of the operation if it is
+// found in DefaultRegistry.
+func (w *treeWriter) opCode(code *IE) {
+	if v, ok := localCode(code); ok {
+		if op := DefaultRegistry.Lookup(w.acn, v); op != nil {
+			w.linef("opCode: localValue: %s (%d)", op.Name, v)
+			return
+		}
+	}
+	w.code("opCode", code)
+}
+
+// errorCode writes the Error Code, with the name of the error if it is found
+// in DefaultRegistry.
+func (w *treeWriter) errorCode(code *IE) {
+	if v, ok := localCode(code); ok {
+		if e := DefaultRegistry.LookupError(w.acn, v); e != nil {
+			w.linef("errorCode: localValue: %s (%d)", e.Name, v)
+			return
+		}
+	}
+	w.code("errorCode", code)
+}
+
+// code writes the Operation Code or Error Code.
+func (w *treeWriter) code(label string, code *IE) {
+	if code == nil {
+		return
+	}
+
+	v, err := codeValue(code)
+	switch {
+	case err != nil:
+		w.linef("%s: %x", label, code.Value)
+	case code.Tag == NewUniversalPrimitiveTag(6):
+		w.linef("%s: globalValue (%s)", label, v)
+	default:
+		w.linef("%s: localValue (%d)", label, v)
+	}
+}
+
+func (w *treeWriter) parameter(param *IE) {
+	if param == nil {
+		return
+	}
+
+	w.linef("parameter")
+	w.nest(func() {
+		w.ie(param.Tag, param.Value)
+	})
+}
+
+// ies writes the elements in b, or b in hex if it cannot be parsed. The tags
+// can be of any number, e.g. [50] in the parameters of CAP.
+func (w *treeWriter) ies(b []byte) {
+	es, err := ber.ReadElements(b)
+	if err != nil {
+		w.linef("%x", b)
+		return
+	}
+	for _, e := range es {
+		w.element(e)
+	}
+}
+
+// ie writes an IE with the class, form and tag, and its children if constructed.
+func (w *treeWriter) ie(tag Tag, value []byte) {
+	w.element(newElement(tag, value))
+}
+
+// element writes an element with the class, form and tag, and its children if
+// constructed.
+func (w *treeWriter) element(e *ber.Element) {
+	form := 0
+	if e.Constructed {
+		form = 1
+	}
+	head := fmt.Sprintf("%s (%s, %s, 0x%x) length %d",
+		tagName(e.Class, e.Tag), classNames[e.Class], formNames[form], e.Identifier(), len(e.Value),
+	)
+	if !e.Constructed {
+		w.linef("%s: %x", head, e.Value)
+		return
+	}
+
+	w.linef("%s", head)
+	w.nest(func() { w.ies(e.Value) })
+}
+
+// newElement returns an element with the single-octet tag of IE.
+func newElement(tag Tag, value []byte) *ber.Element {
+	return &ber.Element{Class: tag.Class(), Constructed: tag.Form() == Constructor, Tag: tag.Code(), Value: value}
+}
+
+var (
+	classNames = []string{"universal", "application", "context-specific", "private"}
+	formNames  = []string{"primitive", "constructed"}
+
+	universalTagNames = map[int]string{
+		1:  "BOOLEAN",
+		2:  "INTEGER",
+		3:  "BIT STRING",
+		4:  "OCTET STRING",
+		5:  "NULL",
+		6:  "OBJECT IDENTIFIER",
+		8:  "EXTERNAL",
+		10: "ENUMERATED",
+		12: "UTF8String",
+		16: "SEQUENCE",
+		17: "SET",
+		19: "PrintableString",
+		22: "IA5String",
+		23: "UTCTime",
+		24: "GeneralizedTime",
+	}
+)
+
+// tagName returns the name of the universal tag, or the tag in ASN.1 notation.
+func tagName(class, tag int) string {
+	switch class {
+	case Universal:
+		if name, ok := universalTagNames[tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("[UNIVERSAL %d]", tag)
+	case ApplicationWide:
+		return fmt.Sprintf("[APPLICATION %d]", tag)
+	case ContextSpecific:
+		return fmt.Sprintf("[%d]", tag)
+	}
+	return fmt.Sprintf("[PRIVATE %d]", tag)
+}
+
+// enumName returns the name of code if known, or "unknown".
+func enumName(code int, names map[int]string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func parenthesize(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " (" + s + ")"
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
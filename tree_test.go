@@ -0,0 +1,119 @@
+package tcap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatTree(t *testing.T) {
+	b, err := hex.DecodeString(appendBinaryCases[0].hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Transaction Capabilities Application Part
+    begin
+        Source Transaction ID
+            otid: 00000002
+        dialoguePortion
+            oid: 0.0.17.773.1.1.1 (dialogue-as-id)
+            dialogueRequest (AARQ)
+                protocol-version: 0780 (version1)
+                application-context-name: 0.4.0.0.1.0.19.2 (networkUnstructuredSsContext-v2)
+                user-information
+                    EXTERNAL
+                        direct-reference: 0.4.0.0.1.1.1.1
+                        single-ASN1-type
+                            [0] (context-specific, constructed, 0xa0) length 16
+                                [0] (context-specific, primitive, 0x80) length 6: 9121436587f9
+                                [1] (context-specific, primitive, 0x81) length 6: 9121436587f9
+        components: 1 item
+            Component: invoke (1)
+                invokeID: 0
+                opCode: localValue (59)
+                parameter
+                    SEQUENCE (universal, constructed, 0x30) length 19
+                        OCTET STRING (universal, primitive, 0x04) length 1: 0f
+                        OCTET STRING (universal, primitive, 0x04) length 4: f4f29c0e
+                        [0] (context-specific, primitive, 0x80) length 8: 91111111111111f1
+`
+	if got := fmt.Sprintf("%+v", parsed); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	for _, verb := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(verb, parsed); got != parsed.String() {
+			t.Errorf("%s: got %s, want %s", verb, got, parsed.String())
+		}
+	}
+}
+
+func TestFormatTreeLines(t *testing.T) {
+	cases := jsonCases(t)
+	wants := map[string][]string{
+		"End - AARE - ReturnResultLast": {
+			"            dialogueResponse (AARE)",
+			"                result: accepted (0)",
+			"                result-source-diagnostic: dialogue-service-user: null (0)",
+			"            Component: returnResultLast (2)",
+		},
+		"Continue - ReturnError": {
+			"            otid: 0000000a",
+			"            dtid: 0000000b",
+			"                errorCode: localValue (27)",
+		},
+		"End - Reject without Invoke ID": {
+			"                invokeID: NULL",
+			"                problem: invokeProblem (1): unrecognizedOperation (1)",
+		},
+		"Begin - Invoke with global Operation Code and Linked ID": {
+			"                invokeID: -127",
+			"                linkedID: 5",
+			"                opCode: globalValue (1.2.840.1)",
+		},
+		"Abort - P-Abort Cause": {
+			"        p-abortCause: ResourceLimitation (4)",
+		},
+		"Abort - ABRT": {
+			"            dialogueAbort (ABRT)",
+			"                abort-source: dialogue-service-provider (1)",
+		},
+	}
+
+	for _, c := range cases {
+		lines, ok := wants[c.name]
+		if !ok {
+			continue
+		}
+		t.Run(c.name, func(t *testing.T) {
+			got := fmt.Sprintf("%+v", c.tcap)
+			for _, line := range lines {
+				if !strings.Contains(got, line+"\n") {
+					t.Errorf("missing %q in\n%s", line, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatTreeMultiOctetTag(t *testing.T) {
+	// iMSI [50] and mscAddress [55] as in InitialDP of CAP.
+	param := []byte{0x30, 0x0e, 0x80, 0x01, 0x05, 0x9f, 0x32, 0x03, 0x12, 0x34, 0x56, 0x9f, 0x37, 0x02, 0xab, 0xcd}
+	got := fmt.Sprintf("%+v", NewBeginInvoke(1, 0, 0, param))
+	for _, line := range []string{
+		"                    SEQUENCE (universal, constructed, 0x30) length 14",
+		"                        [0] (context-specific, primitive, 0x80) length 1: 05",
+		"                        [50] (context-specific, primitive, 0x9f32) length 3: 123456",
+		"                        [55] (context-specific, primitive, 0x9f37) length 2: abcd",
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, got)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// dialogueOIDPrefix is the common part of dialogue-as-id and unidialogue-as-id,
+// {itu-t recommendation q 773 as(1)}.
+var dialogueOIDPrefix = []byte{0x00, 0x11, 0x86, 0x05, 0x01}
+
+// validator collects the Violations found in a portion.
+type validator struct {
+	portion    string
+	violations []*Violation
+}
+
+func (v *validator) addf(format string, a ...interface{}) {
+	v.violations = append(v.violations, &Violation{
+		Portion: v.portion,
+		Reason:  fmt.Sprintf(format, a...),
+	})
+}
+
+// tag checks that the field exists when mandatory, and has one of the tags given if exists.
+func (v *validator) tag(name string, field *IE, mandatory bool, tags ...Tag) {
+	if field == nil {
+		if mandatory {
+			v.addf("missing mandatory %s", name)
+		}
+		return
+	}
+
+	for _, t := range tags {
+		if field.Tag == t {
+			return
+		}
+	}
+	v.addf("%s has unexpected tag %#x", name, uint8(field.Tag))
+}
+
+// absent checks that the field does not exist.
+func (v *validator) absent(name string, field *IE) {
+	if field != nil {
+		v.addf("unexpected %s", name)
+	}
+}
+
+// asError returns the violations as *ValidationError, or nil if there are none.
+func asError(vs []*Violation) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: vs}
+}
+
+// Validate checks that the TCAP follows the structural rules of Q.773: the
+// mandatory fields of each portion, the DialoguePDU allowed in the message type
+// and the presence of the portions, in addition to the ones checked by Validate
+// of each portion.
+//
+// It returns *ValidationError holding all the violations found, or nil if valid.
+func (t *TCAP) Validate() error {
+	return asError(t.validate())
+}
+
+func (t *TCAP) validate() []*Violation {
+	v := &validator{portion: "TCAP"}
+	if t.Transaction == nil {
+		v.addf("missing Transaction Portion")
+		return v.violations
+	}
+	vs := t.Transaction.validate()
+
+	mtype := t.Transaction.Type.Code()
+	if d := t.Dialogue; d != nil {
+		vs = append(vs, d.validate()...)
+		if pdu := d.DialoguePDU; pdu != nil {
+			switch allowed := allowedDialoguePDUs(mtype, d.isUnidialogue()); {
+			case allowed == nil:
+				v.addf("%s must not have Dialogue Portion", t.Transaction.MessageTypeString())
+			case !containsInt(allowed, pdu.Type.Code()):
+				v.addf("%s must not have %s", t.Transaction.MessageTypeString(), pdu.DialogueType())
+			}
+		}
+	}
+
+	if c := t.Components; c != nil {
+		if c.Tag != NewApplicationWideConstructorTag(12) {
+			v.addf("Component Portion has unexpected tag %#x", uint8(c.Tag))
+		}
+		if mtype == Abort {
+			v.addf("Abort must not have Component Portion")
+		}
+		for i, comp := range c.Component {
+			vs = append(vs, comp.validate(fmt.Sprintf("Component[%d]", i))...)
+		}
+	}
+
+	if mtype == Unidirectional && (t.Components == nil || len(t.Components.Component) == 0) {
+		v.addf("Unidirectional must have at least one Component")
+	}
+
+	return append(vs, v.violations...)
+}
+
+// allowedDialoguePDUs returns the type of DialoguePDUs the message type can carry.
+func allowedDialoguePDUs(mtype int, uni bool) []int {
+	if uni {
+		if mtype == Unidirectional {
+			return []int{AARQ} // AUDT shares the tag with AARQ.
+		}
+		return nil
+	}
+
+	switch mtype {
+	case Begin:
+		return []int{AARQ}
+	case Continue, End:
+		return []int{AARE}
+	case Abort:
+		return []int{AARE, ABRT}
+	}
+	return nil
+}
+
+func containsInt(s []int, x int) bool {
+	for _, y := range s {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the Transaction Portion has the tag of a known message
+// type and the Transaction IDs and P-Abort Cause allowed in it.
+//
+// It returns *ValidationError holding all the violations found, or nil if valid.
+func (t *Transaction) Validate() error {
+	return asError(t.validate())
+}
+
+func (t *Transaction) validate() []*Violation {
+	v := &validator{portion: "Transaction"}
+	if t.Type.Class() != ApplicationWide || t.Type.Form() != Constructor {
+		v.addf("unexpected tag class or form in %#x", uint8(t.Type))
+	}
+
+	otid := NewApplicationWidePrimitiveTag(8)
+	dtid := NewApplicationWidePrimitiveTag(9)
+	cause := NewApplicationWidePrimitiveTag(10)
+	switch t.Type.Code() {
+	case Unidirectional:
+		v.absent("Originating Transaction ID", t.OrigTransactionID)
+		v.absent("Destination Transaction ID", t.DestTransactionID)
+		v.absent("P-Abort Cause", t.PAbortCause)
+	case Begin:
+		v.tag("Originating Transaction ID", t.OrigTransactionID, true, otid)
+		v.absent("Destination Transaction ID", t.DestTransactionID)
+		v.absent("P-Abort Cause", t.PAbortCause)
+	case End:
+		v.absent("Originating Transaction ID", t.OrigTransactionID)
+		v.tag("Destination Transaction ID", t.DestTransactionID, true, dtid)
+		v.absent("P-Abort Cause", t.PAbortCause)
+	case Continue:
+		v.tag("Originating Transaction ID", t.OrigTransactionID, true, otid)
+		v.tag("Destination Transaction ID", t.DestTransactionID, true, dtid)
+		v.absent("P-Abort Cause", t.PAbortCause)
+	case Abort:
+		v.absent("Originating Transaction ID", t.OrigTransactionID)
+		v.tag("Destination Transaction ID", t.DestTransactionID, true, dtid)
+		v.tag("P-Abort Cause", t.PAbortCause, false, cause)
+		if c := t.PAbortCause; c != nil && (len(c.Value) != 1 || c.Value[0] > ResourceLimitation) {
+			v.addf("unknown P-Abort Cause %x", c.Value)
+		}
+	default:
+		v.addf("unknown message type %d", t.Type.Code())
+	}
+
+	for _, tid := range []*IE{t.OrigTransactionID, t.DestTransactionID} {
+		if tid != nil && (len(tid.Value) < 1 || len(tid.Value) > 4) {
+			v.addf("Transaction ID %#x must be 1 to 4 octets, got %d", uint8(tid.Tag), len(tid.Value))
+		}
+	}
+
+	return v.violations
+}
+
+// Validate checks that the Dialogue Portion is an EXTERNAL with dialogue-as-id or
+// unidialogue-as-id, and that the DialoguePDU in it has the mandatory fields.
+//
+// It returns *ValidationError holding all the violations found, or nil if valid.
+func (d *Dialogue) Validate() error {
+	return asError(d.validate())
+}
+
+func (d *Dialogue) validate() []*Violation {
+	v := &validator{portion: "Dialogue"}
+	if d.Tag != NewApplicationWideConstructorTag(11) {
+		v.addf("unexpected tag %#x", uint8(d.Tag))
+	}
+	if d.ExternalTag != NewUniversalConstructorTag(8) {
+		v.addf("unexpected EXTERNAL tag %#x", uint8(d.ExternalTag))
+	}
+
+	v.tag("Object Identifier", d.ObjectIdentifier, true, NewUniversalPrimitiveTag(6))
+	if oid := d.ObjectIdentifier; oid != nil {
+		if len(oid.Value) != 7 || !bytes.HasPrefix(oid.Value, dialogueOIDPrefix) ||
+			(oid.Value[5] != DialogueAsID && oid.Value[5] != UnidialogueAsID) || oid.Value[6] != 1 {
+			v.addf("Object Identifier %x is neither dialogue-as-id nor unidialogue-as-id version1", oid.Value)
+		}
+	}
+	v.tag("Single-ASN.1-type", d.SingleAsn1Type, false, NewContextSpecificConstructorTag(0))
+
+	pdu := d.DialoguePDU
+	if pdu == nil {
+		v.addf("missing DialoguePDU")
+		return v.violations
+	}
+
+	v.portion = "DialoguePDU"
+	if pdu.Type.Class() != ApplicationWide || pdu.Type.Form() != Constructor {
+		v.addf("unexpected tag class or form in %#x", uint8(pdu.Type))
+	}
+
+	pv := NewContextSpecificPrimitiveTag(0)
+	acn := NewContextSpecificConstructorTag(1)
+	ui := NewContextSpecificConstructorTag(30)
+	switch pdu.Type.Code() {
+	case AARQ: // or AUDT with unidialogue-as-id, which has the same structure.
+		v.tag("Protocol Version", pdu.ProtocolVersion, false, pv)
+		v.tag("Application Context Name", pdu.ApplicationContextName, true, acn)
+	case AARE:
+		v.tag("Protocol Version", pdu.ProtocolVersion, false, pv)
+		v.tag("Application Context Name", pdu.ApplicationContextName, true, acn)
+		v.tag("Result", pdu.Result, true, NewContextSpecificConstructorTag(2))
+		v.tag("Result Source Diagnostic", pdu.ResultSourceDiagnostic, true, NewContextSpecificConstructorTag(3))
+	case ABRT:
+		v.tag("Abort Source", pdu.AbortSource, true, NewContextSpecificPrimitiveTag(0))
+	default:
+		v.addf("unknown DialoguePDU type %d", pdu.Type.Code())
+	}
+	v.tag("User Information", pdu.UserInformation, false, ui)
+
+	return v.violations
+}
+
+// isUnidialogue reports whether the Dialogue has unidialogue-as-id.
+func (d *Dialogue) isUnidialogue() bool {
+	oid := d.ObjectIdentifier
+	return oid != nil && len(oid.Value) > 5 && oid.Value[5] == UnidialogueAsID
+}
+
+// Validate checks that the Component has the tag of a known component type and
+// the mandatory fields of it with the expected tags.
+//
+// It returns *ValidationError holding all the violations found, or nil if valid.
+func (c *Component) Validate() error {
+	return asError(c.validate("Component"))
+}
+
+func (c *Component) validate(portion string) []*Violation {
+	v := &validator{portion: portion}
+	if c.Type.Class() != ContextSpecific || c.Type.Form() != Constructor {
+		v.addf("unexpected tag class or form in %#x", uint8(c.Type))
+	}
+
+	invokeID := NewUniversalPrimitiveTag(2)
+	local, global := NewUniversalPrimitiveTag(2), NewUniversalPrimitiveTag(6)
+	switch c.Type.Code() {
+	case Invoke:
+		v.tag("Invoke ID", c.InvokeID, true, invokeID)
+		v.tag("Linked ID", c.LinkedID, false, NewContextSpecificPrimitiveTag(0))
+		v.tag("Operation Code", c.OperationCode, true, local, global)
+	case ReturnResultLast, ReturnResultNotLast:
+		v.tag("Invoke ID", c.InvokeID, true, invokeID)
+		v.tag("result sequence", c.ResultRetres, false, NewUniversalConstructorTag(0x10))
+		if c.ResultRetres != nil {
+			// The Parameter is mandatory in the result sequence.
+			v.tag("Operation Code", c.OperationCode, true, local, global)
+			if c.Parameter == nil {
+				v.addf("missing mandatory Parameter in result sequence")
+			}
+		} else {
+			v.absent("Operation Code without result sequence", c.OperationCode)
+			v.absent("Parameter without result sequence", c.Parameter)
+		}
+	case ReturnError:
+		v.tag("Invoke ID", c.InvokeID, true, invokeID)
+		v.tag("Error Code", c.ErrorCode, true, local, global)
+	case Reject:
+		// Invoke ID can be NULL when it is not derivable.
+		v.tag("Invoke ID", c.InvokeID, true, invokeID, NewUniversalPrimitiveTag(5))
+		v.tag("Problem Code", c.ProblemCode, true,
+			NewContextSpecificPrimitiveTag(GeneralProblem),
+			NewContextSpecificPrimitiveTag(InvokeProblem),
+			NewContextSpecificPrimitiveTag(ReturnResultProblem),
+			NewContextSpecificPrimitiveTag(ReturnErrorProblem),
+		)
+		v.absent("Parameter", c.Parameter)
+	default:
+		v.addf("unknown component type %d", c.Type.Code())
+	}
+
+	if id := c.InvokeID; id != nil && id.Tag == invokeID && len(id.Value) != 1 {
+		v.addf("Invoke ID must be 1 octet, got %d", len(id.Value))
+	}
+
+	return v.violations
+}
@@ -0,0 +1,106 @@
+package tcap
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	param := []byte{0x30, 0x03, 0x04, 0x01, 0x0f}
+
+	tests := []struct {
+		name string
+		tcap func() *TCAP
+		want []string
+	}{
+		{
+			name: "valid Begin",
+			tcap: func() *TCAP {
+				return NewBeginInvokeWithDialogue(1, DialogueAsID, NetworkUnstructuredSsContext, 2, 0, 59, param)
+			},
+		}, {
+			name: "valid End",
+			tcap: func() *TCAP {
+				return NewEndReturnResultWithDialogue(1, DialogueAsID, NetworkUnstructuredSsContext, 2, 0, 59, true, param)
+			},
+		}, {
+			name: "Begin without OTID",
+			tcap: func() *TCAP {
+				t := NewBeginInvoke(1, 0, 59, param)
+				t.Transaction.OrigTransactionID = nil
+				return t
+			},
+			want: []string{"Transaction: missing mandatory Originating Transaction ID"},
+		}, {
+			name: "End with AARQ",
+			tcap: func() *TCAP {
+				t := NewEndReturnResult(1, 0, 59, true, param)
+				t.Dialogue = NewDialogue(DialogueAsID, 1, NewAARQ(1, NetworkUnstructuredSsContext, 2), nil)
+				return t
+			},
+			want: []string{"TCAP: End must not have AARQ"},
+		}, {
+			name: "ReturnResult without Invoke ID",
+			tcap: func() *TCAP {
+				t := NewEndReturnResult(1, 0, 59, true, param)
+				t.Components.Component[0].InvokeID = nil
+				return t
+			},
+			want: []string{"Component[0]: missing mandatory Invoke ID"},
+		}, {
+			name: "Dialogue with unknown OID and long TID",
+			tcap: func() *TCAP {
+				t := NewBeginInvokeWithDialogue(1, DialogueAsID, NetworkUnstructuredSsContext, 2, 0, 59, param)
+				t.Transaction.OrigTransactionID.Value = []byte{1, 2, 3, 4, 5}
+				t.Dialogue.ObjectIdentifier.Value[5] = 3
+				return t
+			},
+			want: []string{
+				"Transaction: Transaction ID 0x48 must be 1 to 4 octets, got 5",
+				"Dialogue: Object Identifier 00118605010301 is neither dialogue-as-id nor unidialogue-as-id version1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tcap().Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				return
+			}
+
+			verr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want *ValidationError", err)
+			}
+			if len(verr.Violations) != len(tt.want) {
+				t.Fatalf("got %v, want %v", verr.Violations, tt.want)
+			}
+			for i, v := range verr.Violations {
+				if got := v.Error(); got != tt.want[i] {
+					t.Errorf("got %q, want %q", got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithValidation(t *testing.T) {
+	tc := NewEndReturnResult(1, 0, 59, true, []byte{0x30, 0x03, 0x04, 0x01, 0x0f})
+	tc.Dialogue = NewDialogue(DialogueAsID, 1, NewAARQ(1, NetworkUnstructuredSsContext, 2), nil)
+	b, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Parse(b); err != nil {
+		t.Fatalf("got %v without validation", err)
+	}
+	if _, err := Parse(b, WithValidation()); err == nil {
+		t.Fatal("got no error with validation")
+	} else if _, ok := err.(*ValidationError); !ok {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2019-2020 go-tcap authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tcap
+
+import "io"
+
+// View is a read-only view over the byte sequence of a TCAP message.
+//
+// Unlike Parse, it does not build any struct nor copy the given bytes. Only the
+// top-level elements of the Transaction Portion are located when the View is
+// created, and the rest is looked up when it is asked for. This is useful when
+// only a few fields are needed, e.g. routing messages by transaction IDs or ACN.
+//
+// The View refers to the given bytes, which must not be modified while in use.
+// Use Parse (or View.Parse) to get the full TCAP when needed.
+type View struct {
+	raw        []byte
+	typ        Tag
+	otid       []byte
+	dtid       []byte
+	cause      []byte
+	dialogue   []byte
+	components []byte
+}
+
+// ParseView creates a View over the given byte sequence.
+func ParseView(b []byte) (View, error) {
+	v := View{raw: b}
+	tag, body, _, err := readTLV(b)
+	if err != nil {
+		return View{}, err
+	}
+	v.typ = tag
+
+	for len(body) != 0 {
+		tag, value, n, err := readTLV(body)
+		if err != nil {
+			return View{}, err
+		}
+
+		switch tag {
+		case 0x48:
+			v.otid = value
+		case 0x49:
+			v.dtid = value
+		case 0x4a:
+			v.cause = value
+		case 0x6b:
+			v.dialogue = value
+		case 0x6c:
+			v.components = value
+		}
+		body = body[n:]
+	}
+
+	return v, nil
+}
+
+// Bytes returns the byte sequence the View refers to.
+func (v View) Bytes() []byte {
+	return v.raw
+}
+
+// Parse parses the byte sequence the View refers to as a TCAP.
+func (v View) Parse() (*TCAP, error) {
+	return Parse(v.raw)
+}
+
+// MessageType returns the Message Type, e.g. Begin.
+func (v View) MessageType() int {
+	return v.typ.Code()
+}
+
+// MessageTypeString returns the name of Message Type in string.
+func (v View) MessageTypeString() string {
+	t := Transaction{Type: v.typ}
+	return t.MessageTypeString()
+}
+
+// OTID returns the value of Originating Transaction ID, or nil if the message does not have it.
+func (v View) OTID() []byte {
+	return v.otid
+}
+
+// DTID returns the value of Destination Transaction ID, or nil if the message does not have it.
+func (v View) DTID() []byte {
+	return v.dtid
+}
+
+// PAbortCause returns the P-Abort Cause, or -1 if the message does not have it.
+func (v View) PAbortCause() int {
+	if len(v.cause) == 0 {
+		return -1
+	}
+	return int(v.cause[0])
+}
+
+// HasDialogue reports whether the message has the Dialogue Portion.
+func (v View) HasDialogue() bool {
+	return v.dialogue != nil
+}
+
+// DialogueOID returns the contents of the Object Identifier in the Dialogue Portion,
+// i.e. dialogue-as-id or unidialogue-as-id, or nil if not found.
+func (v View) DialogueOID() []byte {
+	ext, err := findTLV(v.dialogue, 0x28)
+	if err != nil {
+		return nil
+	}
+	oid, err := findTLV(ext, 0x06)
+	if err != nil {
+		return nil
+	}
+	return oid
+}
+
+// DialoguePDUType returns the type of DialoguePDU (AARQ, AARE or ABRT), or -1 if not found.
+func (v View) DialoguePDUType() int {
+	tag, _, err := v.dialoguePDU()
+	if err != nil {
+		return -1
+	}
+	return tag.Code()
+}
+
+// ApplicationContextName returns the contents of the OBJECT IDENTIFIER of
+// Application Context Name, or nil if not found.
+//
+// For MAP, it looks like {0x04, 0x00, 0x00, 0x01, 0x00, ctx, ver}.
+func (v View) ApplicationContextName() []byte {
+	tag, pdu, err := v.dialoguePDU()
+	if err != nil {
+		return nil
+	}
+	switch tag.Code() {
+	case AARQ, AARE:
+	default:
+		return nil
+	}
+
+	acn, err := findTLV(pdu, 0xa1)
+	if err != nil {
+		return nil
+	}
+	oid, err := findTLV(acn, 0x06)
+	if err != nil {
+		return nil
+	}
+	return oid
+}
+
+// dialoguePDU returns the tag and contents of DialoguePDU in the Dialogue Portion.
+func (v View) dialoguePDU() (Tag, []byte, error) {
+	ext, err := findTLV(v.dialogue, 0x28)
+	if err != nil {
+		return 0, nil, err
+	}
+	asn1, err := findTLV(ext, 0xa0)
+	if err != nil {
+		return 0, nil, err
+	}
+	tag, pdu, _, err := readTLV(asn1)
+	if err != nil {
+		return 0, nil, err
+	}
+	return tag, pdu, nil
+}
+
+// Components returns the iterator over the Components in the Component Portion.
+//
+// It does not fail even if the message does not have the Component Portion,
+// in which case the iterator yields nothing.
+func (v View) Components() ComponentIterator {
+	return ComponentIterator{rest: v.components}
+}
+
+// ComponentIterator iterates over the Components in the Component Portion.
+//
+//	it := v.Components()
+//	for c, ok := it.Next(); ok; c, ok = it.Next() {
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type ComponentIterator struct {
+	rest []byte
+	err  error
+}
+
+// Next returns the next Component, and false if there are no more or it fails to
+// read the next one. Check Err to tell which.
+func (it *ComponentIterator) Next() (ComponentView, bool) {
+	if it.err != nil || len(it.rest) == 0 {
+		return ComponentView{}, false
+	}
+
+	tag, value, n, err := readTLV(it.rest)
+	if err != nil {
+		it.err = err
+		return ComponentView{}, false
+	}
+	it.rest = it.rest[n:]
+	return ComponentView{typ: tag, body: value}, true
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ComponentIterator) Err() error {
+	return it.err
+}
+
+// ComponentView is a read-only view over a Component.
+type ComponentView struct {
+	typ  Tag
+	body []byte
+}
+
+// Type returns the Component Type, e.g. Invoke.
+func (c ComponentView) Type() int {
+	return c.typ.Code()
+}
+
+// ComponentTypeString returns the Component Type in string.
+func (c ComponentView) ComponentTypeString() string {
+	cm := Component{Type: c.typ}
+	return cm.ComponentTypeString()
+}
+
+// InvokeID returns the Invoke ID, or -1 if the Component does not have it.
+func (c ComponentView) InvokeID() int {
+	tag, value, _, err := readTLV(c.body)
+	if err != nil || tag != 0x02 || len(value) == 0 {
+		return -1
+	}
+	return int(value[0])
+}
+
+// OpCode returns the Operation Code of Invoke and ReturnResult, or the Error Code of ReturnError.
+// It returns -1 if the Component does not have it.
+func (c ComponentView) OpCode() int {
+	_, value, ok := c.code()
+	if !ok || len(value) == 0 {
+		return -1
+	}
+	return int(value[0])
+}
+
+// IsLocal reports whether the Operation Code or Error Code is a local value (INTEGER),
+// not a global value (OBJECT IDENTIFIER).
+func (c ComponentView) IsLocal() bool {
+	tag, _, ok := c.code()
+	return ok && tag == 0x02
+}
+
+// Parameter returns the byte sequence of the Parameter including its tag and length,
+// or nil if the Component does not have it.
+func (c ComponentView) Parameter() []byte {
+	elems := c.elements()
+	found := false
+	for len(elems) != 0 {
+		tag, _, n, err := readTLV(elems)
+		if err != nil {
+			return nil
+		}
+		if found {
+			return elems[:n]
+		}
+		if tag == 0x02 || tag == 0x06 {
+			found = true
+		}
+		elems = elems[n:]
+	}
+	return nil
+}
+
+// code returns the tag and contents of the Operation Code or Error Code.
+func (c ComponentView) code() (Tag, []byte, bool) {
+	switch c.typ.Code() {
+	case Invoke, ReturnResultLast, ReturnResultNotLast, ReturnError:
+	default:
+		return 0, nil, false
+	}
+
+	elems := c.elements()
+	for len(elems) != 0 {
+		tag, value, n, err := readTLV(elems)
+		if err != nil {
+			return 0, nil, false
+		}
+		if tag == 0x02 || tag == 0x06 {
+			return tag, value, true
+		}
+		elems = elems[n:]
+	}
+	return 0, nil, false
+}
+
+// elements returns the contents of the Component after the Invoke ID (and Linked ID),
+// looking into the result sequence for ReturnResult.
+func (c ComponentView) elements() []byte {
+	_, _, n, err := readTLV(c.body)
+	if err != nil {
+		return nil
+	}
+	rest := c.body[n:]
+
+	switch c.typ.Code() {
+	case Invoke:
+		if tag, _, n, err := readTLV(rest); err == nil && tag == 0x80 {
+			rest = rest[n:]
+		}
+	case ReturnResultLast, ReturnResultNotLast:
+		tag, seq, _, err := readTLV(rest)
+		if err != nil || tag != 0x30 {
+			return nil
+		}
+		rest = seq
+	}
+	return rest
+}
+
+// findTLV returns the contents of the first element with the given tag in b.
+func findTLV(b []byte, tag Tag) ([]byte, error) {
+	for len(b) != 0 {
+		t, value, n, err := readTLV(b)
+		if err != nil {
+			return nil, err
+		}
+		if t == tag {
+			return value, nil
+		}
+		b = b[n:]
+	}
+	return nil, io.ErrUnexpectedEOF
+}
@@ -0,0 +1,120 @@
+package tcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	b, err := hex.DecodeString(appendBinaryCases[1].hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := ParseView(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := v.MessageType(), Begin; got != want {
+		t.Errorf("MessageType: got %v, want %v", got, want)
+	}
+	if got, want := v.OTID(), []byte{0x00, 0x00, 0x00, 0x0a}; !bytes.Equal(got, want) {
+		t.Errorf("OTID: got %x, want %x", got, want)
+	}
+	if got := v.DTID(); got != nil {
+		t.Errorf("DTID: got %x, want nil", got)
+	}
+	if got, want := v.DialoguePDUType(), AARQ; got != want {
+		t.Errorf("DialoguePDUType: got %v, want %v", got, want)
+	}
+	if got, want := v.ApplicationContextName(), []byte{0x04, 0x00, 0x00, 0x01, 0x00, 0x13, 0x02}; !bytes.Equal(got, want) {
+		t.Errorf("ApplicationContextName: got %x, want %x", got, want)
+	}
+
+	it := v.Components()
+	c, ok := it.Next()
+	if !ok {
+		t.Fatalf("no component: %v", it.Err())
+	}
+	if got, want := c.Type(), Invoke; got != want {
+		t.Errorf("Type: got %v, want %v", got, want)
+	}
+	if got, want := c.InvokeID(), 0; got != want {
+		t.Errorf("InvokeID: got %v, want %v", got, want)
+	}
+	if got, want := c.OpCode(), 59; got != want {
+		t.Errorf("OpCode: got %v, want %v", got, want)
+	}
+	if got, want := len(c.Parameter()), 0x9c+3; got != want {
+		t.Errorf("Parameter: got %d octets, want %d", got, want)
+	}
+	if _, ok := it.Next(); ok {
+		t.Error("got unexpected component")
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		v, _ := ParseView(b)
+		_ = v.ApplicationContextName()
+		it := v.Components()
+		for c, ok := it.Next(); ok; c, ok = it.Next() {
+			_ = c.OpCode()
+			_ = c.Parameter()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs, want 0", allocs)
+	}
+}
+
+func TestViewReturnResult(t *testing.T) {
+	tc := NewEndReturnResult(0x11111111, 1, 71, true, []byte{0x30, 0x04, 0xde, 0xad, 0xbe, 0xef})
+	b, err := tc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := ParseView(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := v.DTID(), []byte{0x11, 0x11, 0x11, 0x11}; !bytes.Equal(got, want) {
+		t.Errorf("DTID: got %x, want %x", got, want)
+	}
+	if got := v.ApplicationContextName(); got != nil {
+		t.Errorf("ApplicationContextName: got %x, want nil", got)
+	}
+
+	it := v.Components()
+	c, ok := it.Next()
+	if !ok {
+		t.Fatalf("no component: %v", it.Err())
+	}
+	if got, want := c.OpCode(), 71; got != want {
+		t.Errorf("OpCode: got %v, want %v", got, want)
+	}
+	if got, want := c.Parameter(), []byte{0x30, 0x04, 0xde, 0xad, 0xbe, 0xef}; !bytes.Equal(got, want) {
+		t.Errorf("Parameter: got %x, want %x", got, want)
+	}
+}
+
+func BenchmarkParseView(b *testing.B) {
+	raw, err := hex.DecodeString(appendBinaryCases[0].hex)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v, err := ParseView(raw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = v.OTID()
+		_ = v.ApplicationContextName()
+	}
+}